@@ -0,0 +1,137 @@
+package consensus
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const defaultAncestorEntries = 65536 // Jumlah default entri skip list yang disimpan di memori
+
+// skipEntry adalah satu node skip list untuk sebuah header. Karena hash header
+// menentukan induknya, entri tidak pernah berubah dan tetap valid saat reorg.
+type skipEntry struct {
+	number uint64      // Nomor blok header
+	parent common.Hash // Hash induk langsung
+	skip   common.Hash // Hash leluhur pada tinggi skipHeight(number), kosong jika belum dihitung
+}
+
+// AncestorIndex adalah layanan pencarian leluhur berbasis skip list di atas
+// ChainHeaderReader. Setiap header menyimpan tautan ke induknya dan ke satu leluhur
+// yang lebih jauh, sehingga GetAncestor dan IsAncestor berjalan dalam O(log n)
+// alih-alih menelusuri header satu per satu.
+//
+// Entri dibangun secara malas hanya untuk header yang dilewati pencarian, dan
+// pencarian tidak pernah turun di bawah tinggi tujuannya. Pencarian pertama di
+// antara dua tinggi yang berjarak n membaca paling banyak n header, dan entri yang
+// terbuang dari cache hanya dibangun ulang jika dilewati lagi.
+type AncestorIndex struct {
+	entries *lru.ARCCache // Entri skip list untuk header terbaru, berdasarkan hash
+}
+
+// NewAncestorIndex membuat indeks leluhur yang menyimpan paling banyak size entri.
+// Ukuran nol atau negatif berarti memakai ukuran default.
+func NewAncestorIndex(size int) *AncestorIndex {
+	if size <= 0 {
+		size = defaultAncestorEntries
+	}
+	entries, _ := lru.NewARC(size)
+	return &AncestorIndex{entries: entries}
+}
+
+// GetAncestor mengembalikan hash dan nomor leluhur sejauh distance blok dari header
+// dengan hash dan nomor yang diberikan. Jika leluhur tidak bisa ditemukan, hash
+// kosong dikembalikan.
+func (idx *AncestorIndex) GetAncestor(chain ChainHeaderReader, hash common.Hash, number, distance uint64) (common.Hash, uint64) {
+	if distance > number {
+		return common.Hash{}, 0
+	}
+	return idx.ancestor(chain, hash, number, number-distance)
+}
+
+// IsAncestor mengembalikan apakah header ancestor adalah leluhur (atau sama dengan)
+// header dengan hash dan nomor yang diberikan.
+func (idx *AncestorIndex) IsAncestor(chain ChainHeaderReader, ancestor common.Hash, ancestorNumber uint64, hash common.Hash, number uint64) bool {
+	if ancestorNumber > number {
+		return false
+	}
+	found, _ := idx.ancestor(chain, hash, number, ancestorNumber)
+	return found != (common.Hash{}) && found == ancestor
+}
+
+// ancestor menelusuri skip list dari header yang diberikan sampai tinggi target.
+// Tautan skip dipakai selama tidak melewati target, selain itu turun ke induk.
+// Penelusuran tidak pernah membaca header di bawah target.
+func (idx *AncestorIndex) ancestor(chain ChainHeaderReader, hash common.Hash, number, target uint64) (common.Hash, uint64) {
+	for number > target {
+		entry := idx.entry(chain, hash, number)
+		if entry == nil {
+			return common.Hash{}, 0
+		}
+		var (
+			skip     = skipHeight(number)
+			skipPrev = skipHeight(number - 1)
+		)
+		if skip == target || (skip > target && !(skipPrev+2 < skip && skipPrev >= target)) {
+			if entry.skip == (common.Hash{}) {
+				if entry = idx.link(chain, hash, entry); entry == nil {
+					return common.Hash{}, 0
+				}
+			}
+			hash, number = entry.skip, skip
+		} else {
+			hash, number = entry.parent, number-1
+		}
+	}
+	return hash, number
+}
+
+// entry mengambil entri skip list untuk header tertentu, atau membuatnya dari
+// header di rantai jika belum ada. Entri baru hanya berisi tautan ke induk; tautan
+// skip baru dihitung oleh link saat pertama kali dibutuhkan.
+func (idx *AncestorIndex) entry(chain ChainHeaderReader, hash common.Hash, number uint64) *skipEntry {
+	if cached, ok := idx.entries.Get(hash); ok {
+		if entry := cached.(*skipEntry); entry.number == number {
+			return entry
+		}
+		return nil
+	}
+	header := chain.GetHeader(hash, number)
+	if header == nil {
+		return nil
+	}
+	entry := &skipEntry{number: number, parent: header.ParentHash}
+	idx.entries.Add(hash, entry)
+	return entry
+}
+
+// link menghitung tautan skip untuk entri dengan menelusuri dari induknya sampai
+// tinggi skipHeight, jadi tidak ada header di bawah tinggi tersebut yang dibaca.
+// Entri disimpan ulang sebagai salinan baru agar pembaca bersamaan tidak pernah
+// melihat entri yang sedang diubah.
+func (idx *AncestorIndex) link(chain ChainHeaderReader, hash common.Hash, entry *skipEntry) *skipEntry {
+	skip, _ := idx.ancestor(chain, entry.parent, entry.number-1, skipHeight(entry.number))
+	if skip == (common.Hash{}) {
+		return nil
+	}
+	linked := &skipEntry{number: entry.number, parent: entry.parent, skip: skip}
+	idx.entries.Add(hash, linked)
+	return linked
+}
+
+// invertLowestOne mematikan bit 1 terendah dari n.
+func invertLowestOne(n uint64) uint64 {
+	return n & (n - 1)
+}
+
+// skipHeight menghitung tinggi tujuan tautan skip untuk tinggi tertentu. Pola ini
+// menjamin setiap tinggi bisa dicapai dari tinggi mana pun di atasnya dengan
+// O(log n) langkah.
+func skipHeight(number uint64) uint64 {
+	if number < 2 {
+		return 0
+	}
+	if number&1 != 0 {
+		return invertLowestOne(invertLowestOne(number-1)) + 1
+	}
+	return invertLowestOne(number)
+}
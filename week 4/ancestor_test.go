@@ -0,0 +1,143 @@
+package consensus
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// countingChain adalah ChainHeaderReader di memori yang mencatat jumlah dan tinggi
+// terendah header yang dibaca lewat GetHeader.
+type countingChain struct {
+	headers map[common.Hash]*types.Header
+	reads   int    // Jumlah pemanggilan GetHeader
+	lowest  uint64 // Tinggi terendah yang pernah dibaca
+}
+
+// newCountingChain membuat rantai lurus dengan n header mulai dari genesis.
+func newCountingChain(n int) (*countingChain, []*types.Header) {
+	chain := &countingChain{headers: make(map[common.Hash]*types.Header)}
+	headers := make([]*types.Header, n)
+	for i := range headers {
+		header := &types.Header{Number: big.NewInt(int64(i)), Difficulty: common.Big1}
+		if i > 0 {
+			header.ParentHash = headers[i-1].Hash()
+		}
+		headers[i] = header
+		chain.headers[header.Hash()] = header
+	}
+	chain.reset()
+	return chain, headers
+}
+
+// reset menghapus catatan pembacaan.
+func (c *countingChain) reset() {
+	c.reads, c.lowest = 0, ^uint64(0)
+}
+
+func (c *countingChain) Config() *params.ChainConfig                   { return params.TestChainConfig }
+func (c *countingChain) CurrentHeader() *types.Header                  { return nil }
+func (c *countingChain) GetHeaderByNumber(number uint64) *types.Header { return nil }
+func (c *countingChain) GetHeaderByHash(hash common.Hash) *types.Header {
+	return c.headers[hash]
+}
+func (c *countingChain) GetTd(hash common.Hash, number uint64) *big.Int { return nil }
+
+func (c *countingChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	c.reads++
+	if number < c.lowest {
+		c.lowest = number
+	}
+	header := c.headers[hash]
+	if header == nil || header.Number.Uint64() != number {
+		return nil
+	}
+	return header
+}
+
+// Menguji bahwa GetAncestor dan IsAncestor mengembalikan leluhur yang benar untuk
+// berbagai jarak, termasuk jarak di luar rantai.
+func TestGetAncestor(t *testing.T) {
+	chain, headers := newCountingChain(5000)
+	idx := NewAncestorIndex(0)
+
+	for i := 0; i < 500; i++ {
+		from := uint64(i*97) % uint64(len(headers))
+		to := uint64(i*31) % (from + 1)
+
+		hash, number := idx.GetAncestor(chain, headers[from].Hash(), from, from-to)
+		if hash != headers[to].Hash() || number != to {
+			t.Fatalf("ancestor %d of %d mismatch: have %d/%x", to, from, number, hash)
+		}
+		if !idx.IsAncestor(chain, headers[to].Hash(), to, headers[from].Hash(), from) {
+			t.Fatalf("%d not reported as ancestor of %d", to, from)
+		}
+	}
+	if hash, _ := idx.GetAncestor(chain, headers[10].Hash(), 10, 11); hash != (common.Hash{}) {
+		t.Fatalf("ancestor beyond genesis found: %x", hash)
+	}
+	if idx.IsAncestor(chain, headers[20].Hash(), 20, headers[10].Hash(), 10) {
+		t.Fatalf("descendant reported as ancestor")
+	}
+	if idx.IsAncestor(chain, headers[6].Hash(), 5, headers[100].Hash(), 100) {
+		t.Fatalf("header with wrong number reported as ancestor")
+	}
+}
+
+// Menguji bahwa pencarian tidak pernah membaca header di bawah targetnya dan
+// membaca paling banyak satu header per tinggi saat indeks masih dingin.
+func TestGetAncestorBounded(t *testing.T) {
+	chain, headers := newCountingChain(100000)
+	idx := NewAncestorIndex(0)
+	tip := headers[len(headers)-1]
+
+	hash, _ := idx.GetAncestor(chain, tip.Hash(), tip.Number.Uint64(), 7)
+	if hash != headers[len(headers)-8].Hash() {
+		t.Fatalf("ancestor mismatch")
+	}
+	if want := uint64(len(headers) - 8); chain.lowest < want {
+		t.Fatalf("read below target: lowest %d, target %d", chain.lowest, want)
+	}
+	if chain.reads > 7 {
+		t.Fatalf("cold lookup read %d headers, want at most 7", chain.reads)
+	}
+	// Pencarian jauh tetap dibatasi jaraknya, dan pencarian ulang memakai indeks
+	chain.reset()
+	idx.GetAncestor(chain, tip.Hash(), tip.Number.Uint64(), 30000)
+	if want := uint64(len(headers) - 30001); chain.lowest < want {
+		t.Fatalf("read below target: lowest %d, target %d", chain.lowest, want)
+	}
+	if chain.reads > 30000 {
+		t.Fatalf("cold lookup read %d headers, want at most 30000", chain.reads)
+	}
+	chain.reset()
+	idx.GetAncestor(chain, tip.Hash(), tip.Number.Uint64(), 29999)
+	if chain.reads > 64 {
+		t.Fatalf("warm lookup read %d headers, want O(log n)", chain.reads)
+	}
+}
+
+// Menguji bahwa indeks yang sangat kecil tetap benar dan tetap terbatas walaupun
+// entri terus terbuang dari cache.
+func TestGetAncestorEviction(t *testing.T) {
+	chain, headers := newCountingChain(20000)
+	idx := NewAncestorIndex(16)
+
+	for i := 0; i < 50; i++ {
+		from := uint64(len(headers) - 1 - i*13)
+		chain.reset()
+		hash, _ := idx.GetAncestor(chain, headers[from].Hash(), from, 1000)
+		if hash != headers[from-1000].Hash() {
+			t.Fatalf("ancestor of %d mismatch", from)
+		}
+		if chain.lowest < from-1000 {
+			t.Fatalf("read below target: lowest %d, target %d", chain.lowest, from-1000)
+		}
+		if chain.reads > 2*1000 {
+			t.Fatalf("lookup from %d read %d headers", from, chain.reads)
+		}
+	}
+}
@@ -0,0 +1,427 @@
+// Paket beacon mengimplementasikan mesin konsensus pembungkus yang berpindah dari
+// aturan eth1 (PoW/PoA) ke aturan proof-of-stake setelah terminal total difficulty
+// tercapai, seperti transisi merge di Ethereum.
+package beacon
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// Konstanta protokol proof-of-stake.
+var (
+	beaconDifficulty = common.Big0          // Kesulitan blok default di konsensus beacon
+	beaconNonce      = types.EncodeNonce(0) // Nonce blok default di konsensus beacon
+)
+
+// Berbagai pesan error untuk menandai blok tidak valid. Error ini sengaja dibuat
+// privat agar bagian lain dari kode tidak bergantung pada error spesifik mesin ini.
+var (
+	errInvalidNonce = errors.New("invalid nonce")
+)
+
+// Beacon adalah mesin konsensus yang menggabungkan konsensus eth1 dengan algoritma
+// proof-of-stake. Aturan yang dipakai ditentukan oleh apakah total difficulty induk
+// sudah melewati terminal total difficulty di konfigurasi chain (EIP-3675).
+//
+// Beacon hanya menjalankan pemeriksaan konsensus yang diperlukan; produksi blok
+// pasca-transisi dilakukan oleh konsensus eksternal. Mesin eth1 yang dibungkus boleh
+// berupa mesin apa pun yang mengimplementasikan consensus.Engine, kecuali beacon.
+type Beacon struct {
+	ethone consensus.Engine // Mesin konsensus eth1 asli, misalnya ethash atau clique
+}
+
+// New membuat mesin konsensus beacon yang membungkus mesin eth1 yang diberikan.
+func New(ethone consensus.Engine) *Beacon {
+	if _, ok := ethone.(*Beacon); ok {
+		panic("nested consensus engine")
+	}
+	return &Beacon{ethone: ethone}
+}
+
+// Author mengimplementasikan consensus.Engine, mengembalikan pembuat blok yang
+// sudah diverifikasi.
+func (beacon *Beacon) Author(header *types.Header) (common.Address, error) {
+	if !beacon.IsPoSHeader(header) {
+		return beacon.ethone.Author(header)
+	}
+	return header.Coinbase, nil
+}
+
+// VerifyHeader memeriksa apakah header sesuai dengan aturan konsensus, memakai
+// aturan eth1 sebelum TTD tercapai dan aturan beacon sesudahnya.
+func (beacon *Beacon) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, seal bool) error {
+	reached, err := IsTTDReached(chain, header.ParentHash, header.Number.Uint64()-1)
+	if err != nil {
+		return err
+	}
+	if !reached {
+		if beacon.IsPoSHeader(header) {
+			return consensus.ErrInvalidTerminalBlock
+		}
+		return beacon.ethone.VerifyHeader(chain, header, seal)
+	}
+	// Keluar lebih awal jika induk tidak dikenal
+	parent := chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	// Pemeriksaan dasar lolos, lakukan verifikasi lengkap
+	return beacon.verifyHeader(chain, header, parent)
+}
+
+// VerifyHeaders mirip dengan VerifyHeader, tetapi memverifikasi sekumpulan header
+// secara bersamaan. Metode mengembalikan saluran keluar untuk membatalkan operasi
+// dan saluran hasil untuk mengambil hasil verifikasi. Header harus berurutan dan
+// bersambung.
+func (beacon *Beacon) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	if !beacon.IsPoSHeader(headers[len(headers)-1]) {
+		return beacon.ethone.VerifyHeaders(chain, headers, seals)
+	}
+	var (
+		preHeaders  []*types.Header
+		postHeaders []*types.Header
+		preSeals    []bool
+	)
+	for index, header := range headers {
+		if beacon.IsPoSHeader(header) {
+			preHeaders = headers[:index]
+			postHeaders = headers[index:]
+			preSeals = seals[:index]
+			break
+		}
+	}
+	if len(preHeaders) == 0 {
+		// Semua header adalah header PoS, pastikan induknya sudah mencapai TTD
+		if reached, err := IsTTDReached(chain, headers[0].ParentHash, headers[0].Number.Uint64()-1); !reached {
+			// TTD belum tercapai, tandai semua header sebagai blok terminal tidak valid
+			if err == nil {
+				err = consensus.ErrInvalidTerminalBlock
+			}
+			results := make(chan error, len(headers))
+			for i := 0; i < len(headers); i++ {
+				results <- err
+			}
+			return make(chan struct{}), results
+		}
+		return beacon.verifyHeaders(chain, headers, nil)
+	}
+	// Titik transisi ada di tengah, pisahkan header menjadi dua kelompok dan
+	// terapkan aturan verifikasi yang berbeda untuk masing-masing
+	var (
+		abort   = make(chan struct{})
+		results = make(chan error, len(headers))
+	)
+	go func() {
+		var (
+			old, new, out      = 0, len(preHeaders), 0
+			errors             = make([]error, len(headers))
+			done               = make([]bool, len(headers))
+			oldDone, oldResult = beacon.ethone.VerifyHeaders(chain, preHeaders, preSeals)
+			newDone, newResult = beacon.verifyHeaders(chain, postHeaders, preHeaders[len(preHeaders)-1])
+		)
+		// Pastikan header pra-merge tidak melewati TTD
+		if index, err := verifyTerminalPoWBlock(chain, preHeaders); err != nil {
+			// Tandai semua header PoW berikutnya dengan error
+			for i := index; i < len(preHeaders); i++ {
+				errors[i], done[i] = err, true
+			}
+		}
+		// Kumpulkan hasilnya sesuai urutan
+		for {
+			for ; done[out]; out++ {
+				results <- errors[out]
+				if out == len(headers)-1 {
+					return
+				}
+			}
+			select {
+			case err := <-oldResult:
+				if !done[old] { // lewati kegagalan yang sudah ditandai oleh pemeriksaan TTD
+					errors[old], done[old] = err, true
+				}
+				old++
+			case err := <-newResult:
+				errors[new], done[new] = err, true
+				new++
+			case <-abort:
+				close(oldDone)
+				close(newDone)
+				return
+			}
+		}
+	}()
+	return abort, results
+}
+
+// verifyTerminalPoWBlock memverifikasi bahwa preHeaders sesuai spesifikasi terkait
+// total difficulty-nya. Fungsi ini mengharapkan:
+//   - preHeaders berisi minimal satu elemen
+//   - induk dari elemen pertama sudah tersimpan di rantai
+//   - semua preHeaders memiliki kesulitan
+//   - elemen terakhir adalah blok terminal
+func verifyTerminalPoWBlock(chain consensus.ChainHeaderReader, preHeaders []*types.Header) (int, error) {
+	td := chain.GetTd(preHeaders[0].ParentHash, preHeaders[0].Number.Uint64()-1)
+	if td == nil {
+		return 0, consensus.ErrUnknownAncestor
+	}
+	td = new(big.Int).Set(td)
+
+	// Pastikan semua blok sebelum blok terakhir masih di bawah TTD
+	for i, head := range preHeaders {
+		if td.Cmp(chain.Config().TerminalTotalDifficulty) >= 0 {
+			return i, consensus.ErrInvalidTerminalBlock
+		}
+		td.Add(td, head.Difficulty)
+	}
+	// Pastikan blok terakhir adalah blok terminal
+	if td.Cmp(chain.Config().TerminalTotalDifficulty) < 0 {
+		return len(preHeaders) - 1, consensus.ErrInvalidTerminalBlock
+	}
+	return 0, nil
+}
+
+// VerifyUncles memverifikasi bahwa paman dari blok yang diberikan sesuai dengan
+// aturan konsensus.
+func (beacon *Beacon) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
+	if !beacon.IsPoSHeader(block.Header()) {
+		return beacon.ethone.VerifyUncles(chain, block)
+	}
+	// Pastikan tidak ada paman karena paman dinonaktifkan di beacon
+	if len(block.Uncles()) > 0 {
+		return consensus.ErrTooManyUncles
+	}
+	return nil
+}
+
+// verifyHeader memeriksa apakah header sesuai dengan aturan konsensus beacon.
+// Perbedaannya dengan aturan klasik:
+//
+//	(a) field berikut harus berisi konstanta:
+//	    - kesulitan harus 0
+//	    - nonce harus 0
+//	    - unclehash harus Hash(emptyHeader)
+//	(b) blok dari masa depan tidak lagi diperiksa
+//	(c) extra-data dibatasi 32 byte
+func (beacon *Beacon) verifyHeader(chain consensus.ChainHeaderReader, header, parent *types.Header) error {
+	// Pastikan ukuran extra-data masuk akal
+	if len(header.Extra) > 32 {
+		return fmt.Errorf("extra-data longer than 32 bytes (%d)", len(header.Extra))
+	}
+	// Verifikasi bagian segel: nonce dan uncle hash harus sesuai konstanta
+	if header.Nonce != beaconNonce {
+		return errInvalidNonce
+	}
+	if header.UncleHash != types.EmptyUncleHash {
+		return consensus.ErrInvalidUncleHash
+	}
+	// Verifikasi stempel waktu
+	if header.Time <= parent.Time {
+		return consensus.ErrInvalidTimestamp
+	}
+	// Verifikasi kesulitan blok sesuai konstanta default
+	if beaconDifficulty.Cmp(header.Difficulty) != 0 {
+		return fmt.Errorf("%w: have %v, want %v", consensus.ErrInvalidDifficulty, header.Difficulty, beaconDifficulty)
+	}
+	// Verifikasi bahwa batas gas <= 2^63-1
+	if header.GasLimit > params.MaxGasLimit {
+		return fmt.Errorf("invalid gasLimit: have %v, max %v", header.GasLimit, params.MaxGasLimit)
+	}
+	// Verifikasi bahwa gas yang dipakai <= batas gas
+	if header.GasUsed > header.GasLimit {
+		return fmt.Errorf("invalid gasUsed: have %d, gasLimit %d", header.GasUsed, header.GasLimit)
+	}
+	// Verifikasi bahwa nomor blok adalah nomor induk + 1
+	if diff := new(big.Int).Sub(header.Number, parent.Number); diff.Cmp(common.Big1) != 0 {
+		return consensus.ErrInvalidNumber
+	}
+	// Verifikasi atribut EIP-1559 dari header
+	return misc.VerifyEip1559Header(chain.Config(), parent, header)
+}
+
+// verifyHeaders mirip dengan verifyHeader, tetapi memverifikasi sekumpulan header
+// di latar belakang. Header induk tambahan bisa diberikan jika header yang
+// bersangkutan belum ada di database.
+func (beacon *Beacon) verifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header, ancestor *types.Header) (chan<- struct{}, <-chan error) {
+	var (
+		abort   = make(chan struct{})
+		results = make(chan error, len(headers))
+	)
+	go func() {
+		for i, header := range headers {
+			var parent *types.Header
+			if i == 0 {
+				if ancestor != nil {
+					parent = ancestor
+				} else {
+					parent = chain.GetHeader(headers[0].ParentHash, headers[0].Number.Uint64()-1)
+				}
+			} else if headers[i-1].Hash() == headers[i].ParentHash {
+				parent = headers[i-1]
+			}
+			if parent == nil {
+				select {
+				case <-abort:
+					return
+				case results <- consensus.ErrUnknownAncestor:
+				}
+				continue
+			}
+			err := beacon.verifyHeader(chain, header, parent)
+			select {
+			case <-abort:
+				return
+			case results <- err:
+			}
+		}
+	}()
+	return abort, results
+}
+
+// Prepare mengimplementasikan consensus.Engine, mengisi field kesulitan header
+// sesuai protokol beacon. Perubahan dilakukan langsung pada header.
+func (beacon *Beacon) Prepare(chain consensus.ChainHeaderReader, header *types.Header) error {
+	// Transisi belum terjadi, pakai aturan lama untuk persiapan
+	reached, err := IsTTDReached(chain, header.ParentHash, header.Number.Uint64()-1)
+	if err != nil {
+		return err
+	}
+	if !reached {
+		return beacon.ethone.Prepare(chain, header)
+	}
+	header.Difficulty = beaconDifficulty
+	return nil
+}
+
+// Finalize mengimplementasikan consensus.Engine, menetapkan state akhir pada header.
+func (beacon *Beacon) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header) {
+	// Berbeda dengan Prepare, Finalize dipakai baik saat membuat maupun memverifikasi
+	// blok, jadi aturan konsensus ditentukan dari jenis header
+	if !beacon.IsPoSHeader(header) {
+		beacon.ethone.Finalize(chain, header, state, txs, uncles)
+		return
+	}
+	// Block reward tidak lagi ditangani di sini, melainkan oleh konsensus eksternal
+	header.Root = state.IntermediateRoot(true)
+}
+
+// FinalizeAndAssemble mengimplementasikan consensus.Engine, menetapkan state akhir
+// dan merakit blok.
+func (beacon *Beacon) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
+	if !beacon.IsPoSHeader(header) {
+		return beacon.ethone.FinalizeAndAssemble(chain, header, state, txs, uncles, receipts)
+	}
+	beacon.Finalize(chain, header, state, txs, uncles)
+	return types.NewBlock(header, txs, uncles, receipts, trie.NewStackTrie(nil)), nil
+}
+
+// Seal membuat permintaan penyegelan baru untuk blok yang diberikan dan mengirim
+// hasilnya ke saluran yang diberikan.
+func (beacon *Beacon) Seal(chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
+	if !beacon.IsPoSHeader(block.Header()) {
+		return beacon.ethone.Seal(chain, block, results, stop)
+	}
+	// Verifikasi segel dilakukan oleh konsensus eksternal, jadi langsung kembali
+	// tanpa mengirim blok apa pun ke saluran results
+	return nil
+}
+
+// SealHash mengembalikan hash dari sebuah blok sebelum disegel.
+func (beacon *Beacon) SealHash(header *types.Header) common.Hash {
+	return beacon.ethone.SealHash(header)
+}
+
+// CalcDifficulty adalah algoritma penyesuaian kesulitan. Ini mengembalikan kesulitan
+// yang harus dimiliki blok baru, memakai aturan eth1 sebelum TTD tercapai.
+func (beacon *Beacon) CalcDifficulty(chain consensus.ChainHeaderReader, time uint64, parent *types.Header) *big.Int {
+	if reached, _ := IsTTDReached(chain, parent.Hash(), parent.Number.Uint64()); !reached {
+		return beacon.ethone.CalcDifficulty(chain, time, parent)
+	}
+	return beaconDifficulty
+}
+
+// FinalizedHeader mengimplementasikan consensus.Engine, meneruskan ke mesin eth1.
+// Setelah transisi, finalitas ditentukan oleh konsensus eksternal lewat forkchoice
+// dan tidak dilacak oleh mesin ini.
+func (beacon *Beacon) FinalizedHeader(chain consensus.ChainHeaderReader) *types.Header {
+	return beacon.ethone.FinalizedHeader(chain)
+}
+
+// SafeHeader mengimplementasikan consensus.Engine, meneruskan ke mesin eth1.
+func (beacon *Beacon) SafeHeader(chain consensus.ChainHeaderReader) *types.Header {
+	return beacon.ethone.SafeHeader(chain)
+}
+
+// APIs mengimplementasikan consensus.Engine, mengembalikan API RPC dari mesin eth1.
+func (beacon *Beacon) APIs(chain consensus.ChainHeaderReader) []rpc.API {
+	return beacon.ethone.APIs(chain)
+}
+
+// Close menghentikan mesin konsensus eth1 yang dibungkus.
+func (beacon *Beacon) Close() error {
+	return beacon.ethone.Close()
+}
+
+// IsPoSHeader mengembalikan apakah header termasuk tahap PoS. Fungsi ini tidak cocok
+// untuk Prepare atau CalcDifficulty karena kesulitan header belum diisi.
+func (beacon *Beacon) IsPoSHeader(header *types.Header) bool {
+	if header.Difficulty == nil {
+		panic("IsPoSHeader called with invalid difficulty")
+	}
+	return header.Difficulty.Cmp(beaconDifficulty) == 0
+}
+
+// InnerEngine mengembalikan mesin konsensus eth1 yang dibungkus.
+func (beacon *Beacon) InnerEngine() consensus.Engine {
+	return beacon.ethone
+}
+
+// SetThreads meneruskan jumlah thread penambangan ke mesin eth1 jika mesin tersebut
+// berbasis PoW.
+func (beacon *Beacon) SetThreads(threads int) {
+	if pow, ok := beacon.ethone.(consensus.PoW); ok {
+		pow.SetThreads(threads)
+	}
+}
+
+// Hashrate meneruskan hashrate dari mesin eth1 jika mesin tersebut berbasis PoW,
+// atau nol jika tidak.
+func (beacon *Beacon) Hashrate() float64 {
+	if pow, ok := beacon.ethone.(consensus.PoW); ok {
+		return pow.Hashrate()
+	}
+	return 0
+}
+
+// SubmitHashrate meneruskan hashrate penambang remote ke mesin eth1 jika mesin
+// tersebut berbasis PoW. Laporan ditolak jika tidak.
+func (beacon *Beacon) SubmitHashrate(id common.Hash, rate uint64) bool {
+	if pow, ok := beacon.ethone.(consensus.PoW); ok {
+		return pow.SubmitHashrate(id, rate)
+	}
+	return false
+}
+
+// IsTTDReached memeriksa apakah terminal total difficulty sudah terlewati pada blok
+// parentHash. Blok tersebut harus sudah tersimpan di database, jika tidak maka
+// error unknown ancestor dikembalikan.
+func IsTTDReached(chain consensus.ChainHeaderReader, parentHash common.Hash, number uint64) (bool, error) {
+	if chain.Config().TerminalTotalDifficulty == nil {
+		return false, nil
+	}
+	td := chain.GetTd(parentHash, number)
+	if td == nil {
+		return false, consensus.ErrUnknownAncestor
+	}
+	return td.Cmp(chain.Config().TerminalTotalDifficulty) >= 0, nil
+}
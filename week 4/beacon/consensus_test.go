@@ -0,0 +1,258 @@
+package beacon
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/consensustest"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// newTestChain membuat rantai di memori dengan TTD 3: blok 1 dan 2 adalah blok PoW
+// berkesulitan 1 sehingga blok 2 menjadi blok terminal, lalu blok 3 dan 4 adalah
+// blok PoS.
+func newTestChain(t *testing.T) (*consensustest.HeaderChain, []*types.Header) {
+	t.Helper()
+
+	config := *params.AllEthashProtocolChanges
+	config.TerminalTotalDifficulty = big.NewInt(3)
+
+	genesis := &types.Header{
+		Number:     big.NewInt(0),
+		Difficulty: big.NewInt(1),
+		GasLimit:   params.GenesisGasLimit,
+		BaseFee:    big.NewInt(params.InitialBaseFee),
+		UncleHash:  types.EmptyUncleHash,
+	}
+	chain := consensustest.NewHeaderChain(&config, genesis)
+	headers, err := chain.Extend(4, func(i int, header *types.Header) {
+		if header.Number.Uint64() > 2 {
+			header.Difficulty = new(big.Int).Set(beaconDifficulty)
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to extend chain: %v", err)
+	}
+	return chain, headers
+}
+
+// nextHeader membuat header PoS yang valid di atas induk tanpa memasukkannya ke
+// rantai.
+func nextHeader(chain *consensustest.HeaderChain, parent *types.Header) *types.Header {
+	return &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		Time:       parent.Time + 12,
+		GasLimit:   parent.GasLimit,
+		BaseFee:    misc.CalcBaseFee(chain.Config(), parent),
+		UncleHash:  types.EmptyUncleHash,
+		Difficulty: new(big.Int).Set(beaconDifficulty),
+	}
+}
+
+// Menguji bahwa header sebelum TTD diteruskan ke mesin eth1, header PoS sebelum
+// TTD ditolak, dan header sesudah TTD diperiksa dengan aturan beacon.
+func TestVerifyHeader(t *testing.T) {
+	chain, headers := newTestChain(t)
+	engine := New(consensustest.NewFakeFailer(1))
+
+	pow := nextHeader(chain, headers[0])
+	pow.Difficulty = big.NewInt(1)
+
+	tests := []struct {
+		name   string
+		header *types.Header
+		want   error
+	}{
+		{"pre-merge delegated", headers[0], consensustest.ErrFakeFailure},
+		{"pre-merge valid", pow, nil},
+		{"pos before ttd", nextHeader(chain, headers[0]), consensus.ErrInvalidTerminalBlock},
+		{"first pos", headers[2], nil},
+		{"pos", nextHeader(chain, headers[3]), nil},
+	}
+	for _, test := range tests {
+		if err := engine.VerifyHeader(chain, test.header, true); err != test.want {
+			t.Errorf("%s: error mismatch: have %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+// Menguji bahwa header PoS yang melanggar aturan beacon ditolak.
+func TestVerifyHeaderReject(t *testing.T) {
+	chain, headers := newTestChain(t)
+	engine := New(consensustest.NewFakeEngine())
+	parent := headers[3]
+
+	tests := []struct {
+		name   string
+		mutate func(header *types.Header)
+		want   error
+	}{
+		{"valid", func(h *types.Header) {}, nil},
+		{"nonce", func(h *types.Header) { h.Nonce = types.EncodeNonce(1) }, errInvalidNonce},
+		{"uncle hash", func(h *types.Header) { h.UncleHash = common.Hash{1} }, consensus.ErrInvalidUncleHash},
+		{"same timestamp", func(h *types.Header) { h.Time = parent.Time }, consensus.ErrInvalidTimestamp},
+		{"difficulty", func(h *types.Header) { h.Difficulty = big.NewInt(1) }, consensus.ErrInvalidDifficulty},
+		{"unknown ancestor", func(h *types.Header) { h.ParentHash = common.Hash{1} }, consensus.ErrUnknownAncestor},
+	}
+	for _, test := range tests {
+		header := nextHeader(chain, parent)
+		test.mutate(header)
+		if err := engine.VerifyHeader(chain, header, true); !errors.Is(err, test.want) {
+			t.Errorf("%s: error mismatch: have %v, want %v", test.name, err, test.want)
+		}
+	}
+	// Pelanggaran tanpa error khusus cukup dipastikan ditolak
+	invalid := []struct {
+		name   string
+		mutate func(header *types.Header)
+	}{
+		{"extra too long", func(h *types.Header) { h.Extra = make([]byte, 33) }},
+		{"gas used over limit", func(h *types.Header) { h.GasUsed = h.GasLimit + 1 }},
+		{"base fee", func(h *types.Header) { h.BaseFee = new(big.Int).Add(h.BaseFee, common.Big1) }},
+	}
+	for _, test := range invalid {
+		header := nextHeader(chain, parent)
+		test.mutate(header)
+		if err := engine.VerifyHeader(chain, header, true); err == nil {
+			t.Errorf("%s: invalid header accepted", test.name)
+		}
+	}
+	header := nextHeader(chain, parent)
+	header.Number = big.NewInt(10)
+	if err := engine.verifyHeader(chain, header, parent); err != consensus.ErrInvalidNumber {
+		t.Errorf("number error mismatch: have %v, want %v", err, consensus.ErrInvalidNumber)
+	}
+}
+
+// Menguji verifikasi batch yang melewati titik transisi, termasuk batch yang
+// berpindah ke PoS sebelum TTD tercapai.
+func TestVerifyHeaders(t *testing.T) {
+	chain, headers := newTestChain(t)
+	engine := New(consensustest.NewFakeFailer(1))
+	genesis := chain.GetHeaderByNumber(0)
+
+	// Cabang yang berpindah ke PoS setelah satu blok PoW, sebelum TTD tercapai
+	early, err := chain.Fork(genesis.Hash(), 2, func(i int, header *types.Header) {
+		header.Extra = []byte("early")
+		if i == 1 {
+			header.Difficulty = new(big.Int).Set(beaconDifficulty)
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to fork chain: %v", err)
+	}
+	// Cabang yang langsung berpindah ke PoS di atas genesis
+	premature, err := chain.Fork(genesis.Hash(), 1, func(i int, header *types.Header) {
+		header.Difficulty = new(big.Int).Set(beaconDifficulty)
+	})
+	if err != nil {
+		t.Fatalf("failed to fork chain: %v", err)
+	}
+	tests := []struct {
+		name    string
+		headers []*types.Header
+		want    []error
+	}{
+		{"pow only", headers[:2], []error{consensustest.ErrFakeFailure, nil}},
+		{"transition", headers, []error{consensustest.ErrFakeFailure, nil, nil, nil}},
+		{"pos only", headers[2:], []error{nil, nil}},
+		{"early transition", early, []error{consensus.ErrInvalidTerminalBlock, nil}},
+		{"pos before ttd", premature, []error{consensus.ErrInvalidTerminalBlock}},
+	}
+	for _, test := range tests {
+		abort, results := engine.VerifyHeaders(chain, test.headers, make([]bool, len(test.headers)))
+		for i, want := range test.want {
+			select {
+			case err := <-results:
+				if err != want {
+					t.Errorf("%s: header %d: error mismatch: have %v, want %v", test.name, i, err, want)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("%s: header %d: verification timeout", test.name, i)
+			}
+		}
+		close(abort)
+	}
+}
+
+// Menguji bahwa blok PoS tidak boleh memiliki paman, sedangkan blok PoW diteruskan
+// ke mesin eth1.
+func TestVerifyUncles(t *testing.T) {
+	chain, headers := newTestChain(t)
+	engine := New(consensustest.NewFakeEngine())
+	uncles := []*types.Header{headers[0]}
+
+	pos := types.NewBlockWithHeader(headers[3]).WithBody(nil, uncles)
+	if err := engine.VerifyUncles(chain, pos); err != consensus.ErrTooManyUncles {
+		t.Errorf("pos error mismatch: have %v, want %v", err, consensus.ErrTooManyUncles)
+	}
+	pow := types.NewBlockWithHeader(headers[1]).WithBody(nil, uncles)
+	if err := engine.VerifyUncles(chain, pow); err != nil {
+		t.Errorf("pow uncles rejected: %v", err)
+	}
+}
+
+// Menguji bahwa penyegelan blok PoW diteruskan ke mesin eth1, sedangkan blok PoS
+// tidak disegel secara lokal.
+func TestSeal(t *testing.T) {
+	chain, headers := newTestChain(t)
+	engine := New(consensustest.NewFakeEngine())
+
+	results := make(chan *types.Block, 1)
+	if err := engine.Seal(chain, types.NewBlockWithHeader(headers[1]), results, nil); err != nil {
+		t.Fatalf("failed to seal pow block: %v", err)
+	}
+	select {
+	case block := <-results:
+		if block.Hash() != headers[1].Hash() {
+			t.Errorf("sealed block mismatch: have %x, want %x", block.Hash(), headers[1].Hash())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("sealing result timeout")
+	}
+	if err := engine.Seal(chain, types.NewBlockWithHeader(headers[3]), results, nil); err != nil {
+		t.Fatalf("failed to seal pos block: %v", err)
+	}
+	select {
+	case block := <-results:
+		t.Errorf("pos block %d sealed locally", block.NumberU64())
+	case <-time.After(100 * time.Millisecond):
+	}
+	// Kesulitan mengikuti mesin eth1 sampai induknya mencapai TTD
+	if have := engine.CalcDifficulty(chain, headers[1].Time, headers[0]); have.Cmp(common.Big1) != 0 {
+		t.Errorf("pre-merge difficulty mismatch: have %v, want %v", have, common.Big1)
+	}
+	if have := engine.CalcDifficulty(chain, headers[2].Time, headers[1]); have.Cmp(beaconDifficulty) != 0 {
+		t.Errorf("post-merge difficulty mismatch: have %v, want %v", have, beaconDifficulty)
+	}
+}
+
+// Menguji bahwa metode PoW diteruskan ke mesin eth1, dan diabaikan dengan aman
+// jika mesin eth1 bukan PoW.
+func TestPoWForwarding(t *testing.T) {
+	inner := consensustest.NewFakePoW()
+	var engine consensus.PoW = New(inner)
+
+	engine.SetThreads(3)
+	if !engine.SubmitHashrate(common.Hash{1}, 100) {
+		t.Fatalf("hashrate submission rejected")
+	}
+	if threads := inner.Threads(); threads != 3 {
+		t.Errorf("threads mismatch: have %d, want %d", threads, 3)
+	}
+	if have := engine.Hashrate(); have != 100 {
+		t.Errorf("hashrate mismatch: have %v, want %v", have, 100)
+	}
+	plain := New(consensustest.NewFakeEngine())
+	plain.SetThreads(3)
+	if plain.SubmitHashrate(common.Hash{1}, 100) || plain.Hashrate() != 0 {
+		t.Errorf("non-PoW engine accepted hashrate")
+	}
+}
@@ -0,0 +1,231 @@
+package clique
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	defaultStatsWindow = 64   // Jumlah blok default untuk statistik signer
+	maxStatsWindow     = 4096 // Jumlah maksimum blok statistik agar satu panggilan RPC tetap ringan
+	maxDuties          = 1024 // Jumlah maksimum giliran yang dikembalikan satu panggilan RPC
+)
+
+// Duty adalah satu giliran in-turn signer yang akan datang.
+type Duty struct {
+	Number uint64 `json:"number"` // Nomor blok yang menjadi giliran signer
+	Time   uint64 `json:"time"`   // Stempel waktu paling awal untuk blok tersebut
+}
+
+// SealerStats adalah statistik produksi blok dari satu signer di dalam jendela blok.
+type SealerStats struct {
+	Sealed   uint64 `json:"sealed"`   // Jumlah blok yang disegel signer
+	Missed   uint64 `json:"missed"`   // Jumlah giliran in-turn signer yang disegel signer lain
+	LastSeen uint64 `json:"lastSeen"` // Nomor blok terakhir yang disegel signer, nol jika tidak ada
+	LastTime uint64 `json:"lastTime"` // Stempel waktu blok terakhir yang disegel signer, nol jika tidak ada
+}
+
+// SealerActivity adalah statistik produksi blok semua signer pada blok From sampai
+// To (inklusif). Signer yang berwenang di dalam jendela tetapi tidak menyegel apa
+// pun tetap dicantumkan dengan statistik nol.
+type SealerActivity struct {
+	From    uint64                          `json:"from"`
+	To      uint64                          `json:"to"`
+	Sealers map[common.Address]*SealerStats `json:"sealers"`
+}
+
+// API adalah API RPC untuk mengendalikan mekanisme signer dan pemungutan suara
+// dari skema proof-of-authority.
+type API struct {
+	chain  consensus.ChainHeaderReader
+	clique *Clique
+}
+
+// header mengambil header untuk nomor blok yang diminta (atau header saat ini
+// jika tidak ada yang diminta).
+func (api *API) header(number *rpc.BlockNumber) *types.Header {
+	if number == nil || *number == rpc.LatestBlockNumber {
+		return api.chain.CurrentHeader()
+	}
+	return api.chain.GetHeaderByNumber(uint64(number.Int64()))
+}
+
+// GetSnapshot mengambil snapshot otorisasi pada blok tertentu.
+func (api *API) GetSnapshot(number *rpc.BlockNumber) (*Snapshot, error) {
+	header := api.header(number)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return api.clique.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+}
+
+// GetSnapshotAtHash mengambil snapshot otorisasi pada blok dengan hash tertentu.
+func (api *API) GetSnapshotAtHash(hash common.Hash) (*Snapshot, error) {
+	header := api.chain.GetHeaderByHash(hash)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return api.clique.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+}
+
+// GetSigners mengambil daftar signer berwenang pada blok tertentu.
+func (api *API) GetSigners(number *rpc.BlockNumber) ([]common.Address, error) {
+	snap, err := api.GetSnapshot(number)
+	if err != nil {
+		return nil, err
+	}
+	return snap.signers(), nil
+}
+
+// GetSignersAtHash mengambil daftar signer berwenang pada blok dengan hash tertentu.
+func (api *API) GetSignersAtHash(hash common.Hash) ([]common.Address, error) {
+	snap, err := api.GetSnapshotAtHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	return snap.signers(), nil
+}
+
+// Proposals mengembalikan proposal yang sedang dipertahankan dan dipilih oleh node.
+func (api *API) Proposals() map[common.Address]bool {
+	api.clique.lock.RLock()
+	defer api.clique.lock.RUnlock()
+
+	proposals := make(map[common.Address]bool)
+	for address, auth := range api.clique.proposals {
+		proposals[address] = auth
+	}
+	return proposals
+}
+
+// Propose menyuntikkan proposal otorisasi baru yang akan coba didorong oleh signer.
+func (api *API) Propose(address common.Address, auth bool) {
+	api.clique.lock.Lock()
+	defer api.clique.lock.Unlock()
+
+	api.clique.proposals[address] = auth
+}
+
+// Discard membatalkan proposal yang sedang berjalan, sehingga signer berhenti
+// memberikan suara (baik mendukung maupun menentang).
+func (api *API) Discard(address common.Address) {
+	api.clique.lock.Lock()
+	defer api.clique.lock.Unlock()
+
+	delete(api.clique.proposals, address)
+}
+
+// GetSealerStats menghitung statistik produksi blok setiap signer untuk window blok
+// yang berakhir di blok tertentu, agar peserta konsorsium bisa memantau keaktifan
+// satu sama lain. Window kosong atau nol memakai 64 blok, dan dibatasi 4096 blok.
+func (api *API) GetSealerStats(number *rpc.BlockNumber, window *uint64) (*SealerActivity, error) {
+	header := api.header(number)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	size := uint64(defaultStatsWindow)
+	if window != nil && *window > 0 {
+		size = *window
+	}
+	if size > maxStatsWindow {
+		size = maxStatsWindow
+	}
+	// Genesis tidak disegel, jadi jendela paling awal dimulai dari blok 1
+	end := header.Number.Uint64()
+	if end == 0 {
+		return nil, errUnknownBlock
+	}
+	start := uint64(1)
+	if end > size {
+		start = end - size + 1
+	}
+	// Kumpulkan header jendela dari belakang, mengikuti hash induk agar tetap di
+	// rantai yang sama dengan header yang diminta
+	headers := make([]*types.Header, end-start+1)
+	for i := len(headers) - 1; i >= 0; i-- {
+		headers[i] = header
+		if i > 0 {
+			if header = api.chain.GetHeader(header.ParentHash, header.Number.Uint64()-1); header == nil {
+				return nil, errUnknownBlock
+			}
+		}
+	}
+	snap, err := api.clique.snapshot(api.chain, start-1, headers[0].ParentHash, nil)
+	if err != nil {
+		return nil, err
+	}
+	activity := &SealerActivity{From: start, To: end, Sealers: make(map[common.Address]*SealerStats)}
+	stats := func(signer common.Address) *SealerStats {
+		if activity.Sealers[signer] == nil {
+			activity.Sealers[signer] = new(SealerStats)
+		}
+		return activity.Sealers[signer]
+	}
+	for _, header := range headers {
+		// Giliran blok ditentukan oleh signer yang berwenang di induknya
+		signers := snap.signers()
+		for _, signer := range signers {
+			stats(signer)
+		}
+		signer, err := ecrecover(header, api.clique.signatures)
+		if err != nil {
+			return nil, err
+		}
+		sealer := stats(signer)
+		sealer.Sealed++
+		sealer.LastSeen, sealer.LastTime = header.Number.Uint64(), header.Time
+
+		if inturn := signers[header.Number.Uint64()%uint64(len(signers))]; inturn != signer {
+			stats(inturn).Missed++
+		}
+		if snap, err = snap.apply([]*types.Header{header}); err != nil {
+			return nil, err
+		}
+	}
+	return activity, nil
+}
+
+// GetDuties mengembalikan giliran in-turn signer mulai dari blok setelah head sampai
+// akhir epoch ke-N, dengan epoch yang sedang berjalan sebagai epoch pertama (default
+// satu), agar operator bisa menjadwalkan pemeliharaan di luar gilirannya. Jadwal
+// dihitung dari signer yang berwenang di head, jadi hanya berlaku selama pemungutan
+// suara tidak mengubah daftar signer, dan stempel waktunya mengasumsikan setiap blok
+// sebelumnya disegel tepat waktu. Paling banyak 1024 giliran dikembalikan.
+func (api *API) GetDuties(signer common.Address, epochs *uint64) ([]Duty, error) {
+	header := api.chain.CurrentHeader()
+	snap, err := api.clique.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := snap.Signers[signer]; !ok {
+		return nil, errUnauthorizedSigner
+	}
+	count := uint64(1)
+	if epochs != nil && *epochs > 0 {
+		count = *epochs
+	}
+	if count > maxDuties {
+		count = maxDuties // Setiap epoch berisi minimal satu blok, jadi batas ini tidak memotong hasil
+	}
+	var (
+		head    = header.Number.Uint64()
+		next    = head + 1
+		epoch   = api.clique.config.Epoch
+		end     = (next/epoch+count)*epoch - 1
+		signers = snap.signers()
+		total   = uint64(len(signers))
+		offset  uint64
+	)
+	for signers[offset] != signer {
+		offset++
+	}
+	// Giliran signer adalah nomor blok yang sisa baginya terhadap jumlah signer sama
+	// dengan posisi signer di daftar terurut
+	var duties []Duty
+	for number := next + (offset+total-next%total)%total; number <= end && len(duties) < maxDuties; number += total {
+		duties = append(duties, Duty{Number: number, Time: header.Time + (number-head)*api.clique.config.Period})
+	}
+	return duties, nil
+}
@@ -0,0 +1,709 @@
+// Paket clique mengimplementasikan mesin konsensus proof-of-authority.
+package clique
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+	lru "github.com/hashicorp/golang-lru"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	checkpointInterval = 1024 // Jumlah blok setelah snapshot suara disimpan ke database
+	inmemorySnapshots  = 128  // Jumlah snapshot suara terbaru yang disimpan di memori
+	inmemorySignatures = 4096 // Jumlah signature blok terbaru yang disimpan di memori
+
+	wiggleTime = 500 * time.Millisecond // Jeda acak (per signer) agar signer bisa menyegel bersamaan
+)
+
+// Konstanta protokol proof-of-authority Clique.
+var (
+	epochLength = uint64(30000) // Jumlah blok default sebelum checkpoint dan reset suara yang tertunda
+
+	extraVanity = 32                     // Jumlah byte awalan extra-data yang dicadangkan untuk vanity signer
+	extraSeal   = crypto.SignatureLength // Jumlah byte akhiran extra-data yang dicadangkan untuk segel signer
+
+	nonceAuthVote = hexutil.MustDecode("0xffffffffffffffff") // Nonce ajaib untuk memilih penambahan signer baru
+	nonceDropVote = hexutil.MustDecode("0x0000000000000000") // Nonce ajaib untuk memilih penghapusan signer
+
+	uncleHash = types.CalcUncleHash(nil) // Selalu Keccak256(RLP([])) karena paman tidak berarti di luar PoW.
+
+	diffInTurn = big.NewInt(2) // Kesulitan blok untuk signature yang sesuai giliran
+	diffNoTurn = big.NewInt(1) // Kesulitan blok untuk signature di luar giliran
+)
+
+// Berbagai pesan error untuk menandai blok tidak valid. Error ini sengaja dibuat
+// privat agar bagian lain dari kode tidak bergantung pada error spesifik mesin ini.
+var (
+	// errUnknownBlock dikembalikan ketika daftar signer diminta untuk blok yang
+	// bukan bagian dari blockchain lokal.
+	errUnknownBlock = errors.New("unknown block")
+
+	// errInvalidCheckpointBeneficiary dikembalikan jika blok checkpoint/transisi epoch
+	// memiliki beneficiary yang bukan nol.
+	errInvalidCheckpointBeneficiary = errors.New("beneficiary in checkpoint block non-zero")
+
+	// errInvalidVote dikembalikan jika nilai nonce bukan salah satu dari dua
+	// konstanta yang diizinkan yaitu 0x00..0 atau 0xff..f.
+	errInvalidVote = errors.New("vote nonce not 0x00..0 or 0xff..f")
+
+	// errInvalidCheckpointVote dikembalikan jika blok checkpoint/transisi epoch
+	// memiliki nonce suara yang bukan nol.
+	errInvalidCheckpointVote = errors.New("vote nonce in checkpoint block non-zero")
+
+	// errMissingVanity dikembalikan jika bagian extra-data blok lebih pendek dari
+	// 32 byte, yang dibutuhkan untuk menyimpan vanity signer.
+	errMissingVanity = errors.New("extra-data 32 byte vanity prefix missing")
+
+	// errMissingSignature dikembalikan jika bagian extra-data blok tidak berisi
+	// signature secp256k1 sepanjang 65 byte.
+	errMissingSignature = errors.New("extra-data 65 byte signature suffix missing")
+
+	// errExtraSigners dikembalikan jika blok non-checkpoint berisi data signer
+	// di dalam extra-data.
+	errExtraSigners = errors.New("non-checkpoint block contains extra signer list")
+
+	// errInvalidCheckpointSigners dikembalikan jika blok checkpoint berisi daftar
+	// signer yang tidak valid (panjangnya tidak habis dibagi 20 byte).
+	errInvalidCheckpointSigners = errors.New("invalid signer list on checkpoint block")
+
+	// errMismatchingCheckpointSigners dikembalikan jika blok checkpoint berisi daftar
+	// signer yang berbeda dari yang dihitung oleh node lokal.
+	errMismatchingCheckpointSigners = errors.New("mismatching signer list on checkpoint block")
+
+	// errWrongDifficulty dikembalikan jika kesulitan blok tidak sesuai dengan
+	// giliran signer.
+	errWrongDifficulty = errors.New("wrong difficulty")
+
+	// errInvalidVotingChain dikembalikan jika daftar otorisasi coba diubah melalui
+	// header yang berada di luar jangkauan atau tidak berurutan.
+	errInvalidVotingChain = errors.New("invalid voting chain")
+
+	// errUnauthorizedSigner dikembalikan jika header ditandatangani oleh pihak yang tidak berwenang.
+	errUnauthorizedSigner = errors.New("unauthorized signer")
+
+	// errRecentlySigned dikembalikan jika header ditandatangani oleh signer berwenang
+	// yang baru saja menandatangani header lain, sehingga untuk sementara tidak diizinkan.
+	errRecentlySigned = errors.New("recently signed")
+)
+
+// SignerFn melakukan hash dan menandatangani data menggunakan akun pendukung.
+type SignerFn func(signer accounts.Account, mimeType string, message []byte) ([]byte, error)
+
+// ecrecover mengekstrak alamat akun Ethereum dari header yang sudah ditandatangani.
+func ecrecover(header *types.Header, sigcache *lru.ARCCache) (common.Address, error) {
+	// Jika signature sudah ada di cache, kembalikan langsung
+	hash := header.Hash()
+	if address, known := sigcache.Get(hash); known {
+		return address.(common.Address), nil
+	}
+	// Ambil signature dari extra-data header
+	if len(header.Extra) < extraSeal {
+		return common.Address{}, errMissingSignature
+	}
+	signature := header.Extra[len(header.Extra)-extraSeal:]
+
+	// Pulihkan public key dan alamat Ethereum
+	pubkey, err := crypto.Ecrecover(SealHash(header).Bytes(), signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	var signer common.Address
+	copy(signer[:], crypto.Keccak256(pubkey[1:])[12:])
+
+	sigcache.Add(hash, signer)
+	return signer, nil
+}
+
+// Clique adalah mesin konsensus proof-of-authority yang menunjuk sekumpulan
+// signer berwenang untuk menyegel blok secara bergiliran.
+type Clique struct {
+	consensus.NoFinality // Mesin ini tidak memiliki finalitas
+
+	config *params.CliqueConfig // Parameter konfigurasi mesin konsensus
+	db     ethdb.Database       // Database untuk menyimpan dan mengambil checkpoint snapshot
+
+	recents    *lru.ARCCache // Snapshot untuk blok terbaru agar reorg lebih cepat
+	signatures *lru.ARCCache // Signature dari blok terbaru agar penyegelan lebih cepat
+
+	proposals map[common.Address]bool // Daftar proposal yang sedang kita dorong
+
+	signer common.Address // Alamat Ethereum dari kunci penandatangan
+	signFn SignerFn       // Fungsi signer untuk mengotorisasi hash
+	lock   sync.RWMutex   // Melindungi field signer dan proposals
+}
+
+// New membuat mesin konsensus proof-of-authority Clique dengan signer awal
+// yang diambil dari blok genesis.
+func New(config *params.CliqueConfig, db ethdb.Database) *Clique {
+	// Isi parameter konsensus yang kosong dengan nilai default
+	conf := *config
+	if conf.Epoch == 0 {
+		conf.Epoch = epochLength
+	}
+	// Alokasikan cache snapshot dan buat mesinnya
+	recents, _ := lru.NewARC(inmemorySnapshots)
+	signatures, _ := lru.NewARC(inmemorySignatures)
+
+	return &Clique{
+		config:     &conf,
+		db:         db,
+		recents:    recents,
+		signatures: signatures,
+		proposals:  make(map[common.Address]bool),
+	}
+}
+
+// Author mengimplementasikan consensus.Engine, mengembalikan alamat Ethereum yang
+// dipulihkan dari signature di bagian extra-data header.
+func (c *Clique) Author(header *types.Header) (common.Address, error) {
+	return ecrecover(header, c.signatures)
+}
+
+// VerifyHeader memeriksa apakah header sesuai dengan aturan konsensus.
+func (c *Clique) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, seal bool) error {
+	return c.verifyHeader(chain, header, nil)
+}
+
+// VerifyHeaders mirip dengan VerifyHeader, tetapi memverifikasi sekumpulan header
+// secara berurutan di latar belakang. Metode mengembalikan saluran keluar untuk
+// membatalkan operasi dan saluran hasil (urutannya sama dengan inputan slice).
+func (c *Clique) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	abort := make(chan struct{})
+	results := make(chan error, len(headers))
+
+	go func() {
+		for i, header := range headers {
+			err := c.verifyHeader(chain, header, headers[:i])
+
+			select {
+			case <-abort:
+				return
+			case results <- err:
+			}
+		}
+	}()
+	return abort, results
+}
+
+// verifyHeader memeriksa apakah header sesuai dengan aturan konsensus. Pemanggil
+// boleh memberikan sekumpulan induk (urutan naik) agar tidak perlu mencarinya dari
+// database. Ini berguna untuk memverifikasi sekumpulan header baru sekaligus.
+func (c *Clique) verifyHeader(chain consensus.ChainHeaderReader, header *types.Header, parents []*types.Header) error {
+	if header.Number == nil {
+		return errUnknownBlock
+	}
+	number := header.Number.Uint64()
+
+	// Jangan buang waktu memeriksa blok dari masa depan
+	if header.Time > uint64(time.Now().Unix()) {
+		return consensus.ErrFutureBlock
+	}
+	// Blok checkpoint wajib memiliki beneficiary nol
+	checkpoint := (number % c.config.Epoch) == 0
+	if checkpoint && header.Coinbase != (common.Address{}) {
+		return errInvalidCheckpointBeneficiary
+	}
+	// Nonce harus 0x00..0 atau 0xff..f, dan wajib nol pada checkpoint
+	if !bytes.Equal(header.Nonce[:], nonceAuthVote) && !bytes.Equal(header.Nonce[:], nonceDropVote) {
+		return errInvalidVote
+	}
+	if checkpoint && !bytes.Equal(header.Nonce[:], nonceDropVote) {
+		return errInvalidCheckpointVote
+	}
+	// Pastikan extra-data berisi vanity dan signature
+	if len(header.Extra) < extraVanity {
+		return errMissingVanity
+	}
+	if len(header.Extra) < extraVanity+extraSeal {
+		return errMissingSignature
+	}
+	// Pastikan extra-data berisi daftar signer pada checkpoint, dan kosong selain itu
+	signersBytes := len(header.Extra) - extraVanity - extraSeal
+	if !checkpoint && signersBytes != 0 {
+		return errExtraSigners
+	}
+	if checkpoint && signersBytes%common.AddressLength != 0 {
+		return errInvalidCheckpointSigners
+	}
+	// Pastikan mix digest nol karena belum ada perlindungan fork
+	if header.MixDigest != (common.Hash{}) {
+		return consensus.ErrInvalidMixDigest
+	}
+	// Pastikan blok tidak berisi paman yang tidak berarti di PoA
+	if header.UncleHash != uncleHash {
+		return consensus.ErrInvalidUncleHash
+	}
+	// Pastikan kesulitan blok masuk akal (belum tentu benar pada titik ini)
+	if number > 0 {
+		if header.Difficulty == nil || (header.Difficulty.Cmp(diffInTurn) != 0 && header.Difficulty.Cmp(diffNoTurn) != 0) {
+			return consensus.ErrInvalidDifficulty
+		}
+	}
+	// Verifikasi bahwa batas gas <= 2^63-1
+	if header.GasLimit > params.MaxGasLimit {
+		return fmt.Errorf("invalid gasLimit: have %v, max %v", header.GasLimit, params.MaxGasLimit)
+	}
+	// Semua pemeriksaan dasar lolos, verifikasi field yang bergantung pada induk
+	return c.verifyCascadingFields(chain, header, parents)
+}
+
+// verifyCascadingFields memverifikasi semua field header yang tidak berdiri sendiri,
+// melainkan bergantung pada sekumpulan header sebelumnya. Pemanggil boleh memberikan
+// sekumpulan induk (urutan naik) agar tidak perlu mencarinya dari database.
+func (c *Clique) verifyCascadingFields(chain consensus.ChainHeaderReader, header *types.Header, parents []*types.Header) error {
+	// Blok genesis selalu valid
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil
+	}
+	// Pastikan stempel waktu blok tidak terlalu dekat dengan induknya
+	var parent *types.Header
+	if len(parents) > 0 {
+		parent = parents[len(parents)-1]
+	} else {
+		parent = chain.GetHeader(header.ParentHash, number-1)
+	}
+	if parent == nil || parent.Number.Uint64() != number-1 || parent.Hash() != header.ParentHash {
+		return consensus.ErrUnknownAncestor
+	}
+	if parent.Time+c.config.Period > header.Time {
+		return consensus.ErrInvalidTimestamp
+	}
+	// Verifikasi bahwa gas yang dipakai <= batas gas
+	if header.GasUsed > header.GasLimit {
+		return fmt.Errorf("invalid gasUsed: have %d, gasLimit %d", header.GasUsed, header.GasLimit)
+	}
+	if !chain.Config().IsLondon(header.Number) {
+		// BaseFee tidak boleh ada sebelum fork EIP-1559
+		if header.BaseFee != nil {
+			return fmt.Errorf("invalid baseFee before fork: have %d, want <nil>", header.BaseFee)
+		}
+		if err := misc.VerifyGaslimit(parent.GasLimit, header.GasLimit); err != nil {
+			return err
+		}
+	} else if err := misc.VerifyEip1559Header(chain.Config(), parent, header); err != nil {
+		// Verifikasi atribut EIP-1559 dari header
+		return err
+	}
+	// Ambil snapshot yang dibutuhkan untuk memverifikasi header ini dan simpan di cache
+	snap, err := c.snapshot(chain, number-1, header.ParentHash, parents)
+	if err != nil {
+		return err
+	}
+	// Jika blok adalah checkpoint, verifikasi daftar signer
+	if number%c.config.Epoch == 0 {
+		signers := make([]byte, len(snap.Signers)*common.AddressLength)
+		for i, signer := range snap.signers() {
+			copy(signers[i*common.AddressLength:], signer[:])
+		}
+		extraSuffix := len(header.Extra) - extraSeal
+		if !bytes.Equal(header.Extra[extraVanity:extraSuffix], signers) {
+			return errMismatchingCheckpointSigners
+		}
+	}
+	// Semua pemeriksaan lolos, verifikasi segelnya
+	return c.verifySeal(snap, header)
+}
+
+// snapshot mengambil snapshot otorisasi pada titik waktu tertentu.
+func (c *Clique) snapshot(chain consensus.ChainHeaderReader, number uint64, hash common.Hash, parents []*types.Header) (*Snapshot, error) {
+	// Cari snapshot di memori atau di disk untuk checkpoint
+	var (
+		headers []*types.Header
+		snap    *Snapshot
+	)
+	for snap == nil {
+		// Jika snapshot di memori ditemukan, gunakan itu
+		if s, ok := c.recents.Get(hash); ok {
+			snap = s.(*Snapshot)
+			break
+		}
+		// Jika checkpoint snapshot di disk ditemukan, gunakan itu
+		if number%checkpointInterval == 0 {
+			if s, err := loadSnapshot(c.config, c.signatures, c.db, hash); err == nil {
+				log.Trace("Loaded voting snapshot from disk", "number", number, "hash", hash)
+				snap = s
+				break
+			}
+		}
+		// Jika kita berada di genesis, buat snapshot dari keadaan awal. Begitu juga jika
+		// kita berada di checkpoint tanpa induk (light client) atau sudah menumpuk lebih
+		// banyak header dari batas reorg, anggap checkpoint itu terpercaya.
+		if number == 0 || (number%c.config.Epoch == 0 && (len(headers) > params.FullImmutabilityThreshold || chain.GetHeaderByNumber(number-1) == nil)) {
+			checkpoint := chain.GetHeaderByNumber(number)
+			if checkpoint != nil {
+				hash := checkpoint.Hash()
+
+				signers := make([]common.Address, (len(checkpoint.Extra)-extraVanity-extraSeal)/common.AddressLength)
+				for i := 0; i < len(signers); i++ {
+					copy(signers[i][:], checkpoint.Extra[extraVanity+i*common.AddressLength:])
+				}
+				snap = newSnapshot(c.config, c.signatures, number, hash, signers)
+				if err := snap.store(c.db); err != nil {
+					return nil, err
+				}
+				log.Info("Stored checkpoint snapshot to disk", "number", number, "hash", hash)
+				break
+			}
+		}
+		// Tidak ada snapshot untuk header ini, kumpulkan header dan mundur
+		var header *types.Header
+		if len(parents) > 0 {
+			// Jika ada induk eksplisit, ambil dari sana (wajib)
+			header = parents[len(parents)-1]
+			if header.Hash() != hash || header.Number.Uint64() != number {
+				return nil, consensus.ErrUnknownAncestor
+			}
+			parents = parents[:len(parents)-1]
+		} else {
+			// Tidak ada induk eksplisit (atau sudah habis), ambil dari database
+			header = chain.GetHeader(hash, number)
+			if header == nil {
+				return nil, consensus.ErrUnknownAncestor
+			}
+		}
+		headers = append(headers, header)
+		number, hash = number-1, header.ParentHash
+	}
+	// Snapshot sebelumnya ditemukan, terapkan header yang tertunda di atasnya
+	for i := 0; i < len(headers)/2; i++ {
+		headers[i], headers[len(headers)-1-i] = headers[len(headers)-1-i], headers[i]
+	}
+	snap, err := snap.apply(headers)
+	if err != nil {
+		return nil, err
+	}
+	c.recents.Add(snap.Hash, snap)
+
+	// Jika snapshot checkpoint baru dibuat, simpan ke disk
+	if snap.Number%checkpointInterval == 0 && len(headers) > 0 {
+		if err = snap.store(c.db); err != nil {
+			return nil, err
+		}
+		log.Trace("Stored voting snapshot to disk", "number", snap.Number, "hash", snap.Hash)
+	}
+	return snap, err
+}
+
+// VerifyUncles mengimplementasikan consensus.Engine, selalu mengembalikan error untuk
+// paman karena mekanisme konsensus ini tidak mengizinkan paman.
+func (c *Clique) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
+	if len(block.Uncles()) > 0 {
+		return consensus.ErrTooManyUncles
+	}
+	return nil
+}
+
+// verifySeal memeriksa apakah signature di dalam header memenuhi persyaratan
+// protokol konsensus terhadap snapshot otorisasi yang diberikan.
+func (c *Clique) verifySeal(snap *Snapshot, header *types.Header) error {
+	// Verifikasi blok genesis tidak didukung
+	number := header.Number.Uint64()
+	if number == 0 {
+		return errUnknownBlock
+	}
+	// Pulihkan kunci otorisasi dan periksa terhadap daftar signer
+	signer, err := ecrecover(header, c.signatures)
+	if err != nil {
+		return err
+	}
+	if _, ok := snap.Signers[signer]; !ok {
+		return errUnauthorizedSigner
+	}
+	for seen, recent := range snap.Recents {
+		if recent == signer {
+			// Signer termasuk yang baru menyegel, gagal hanya jika blok ini tidak menggesernya keluar
+			if limit := uint64(len(snap.Signers)/2 + 1); seen > number-limit {
+				return errRecentlySigned
+			}
+		}
+	}
+	// Pastikan kesulitan sesuai dengan giliran signer
+	inturn := snap.inturn(header.Number.Uint64(), signer)
+	if inturn && header.Difficulty.Cmp(diffInTurn) != 0 {
+		return errWrongDifficulty
+	}
+	if !inturn && header.Difficulty.Cmp(diffNoTurn) != 0 {
+		return errWrongDifficulty
+	}
+	return nil
+}
+
+// Prepare mengimplementasikan consensus.Engine, menyiapkan semua field konsensus
+// dari header sebelum transaksi dijalankan di atasnya.
+func (c *Clique) Prepare(chain consensus.ChainHeaderReader, header *types.Header) error {
+	// Jika blok bukan checkpoint, berikan suara acak (cukup untuk saat ini)
+	header.Coinbase = common.Address{}
+	header.Nonce = types.BlockNonce{}
+
+	number := header.Number.Uint64()
+	// Susun snapshot suara untuk memeriksa suara mana yang masuk akal
+	snap, err := c.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	c.lock.RLock()
+	if number%c.config.Epoch != 0 {
+		// Kumpulkan semua proposal yang masuk akal untuk dipilih
+		addresses := make([]common.Address, 0, len(c.proposals))
+		for address, authorize := range c.proposals {
+			if snap.validVote(address, authorize) {
+				addresses = append(addresses, address)
+			}
+		}
+		// Jika ada proposal tertunda, berikan suara untuk salah satunya
+		if len(addresses) > 0 {
+			header.Coinbase = addresses[rand.Intn(len(addresses))]
+			if c.proposals[header.Coinbase] {
+				copy(header.Nonce[:], nonceAuthVote)
+			} else {
+				copy(header.Nonce[:], nonceDropVote)
+			}
+		}
+	}
+	// Salin signer yang dilindungi mutex untuk menghindari race condition
+	signer := c.signer
+	c.lock.RUnlock()
+
+	// Tetapkan kesulitan yang benar
+	header.Difficulty = calcDifficulty(snap, signer)
+
+	// Pastikan extra data memiliki semua komponennya
+	if len(header.Extra) < extraVanity {
+		header.Extra = append(header.Extra, bytes.Repeat([]byte{0x00}, extraVanity-len(header.Extra))...)
+	}
+	header.Extra = header.Extra[:extraVanity]
+
+	if number%c.config.Epoch == 0 {
+		for _, signer := range snap.signers() {
+			header.Extra = append(header.Extra, signer[:]...)
+		}
+	}
+	header.Extra = append(header.Extra, make([]byte, extraSeal)...)
+
+	// Mix digest dicadangkan untuk saat ini, kosongkan
+	header.MixDigest = common.Hash{}
+
+	// Pastikan stempel waktu memiliki jeda yang benar
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	header.Time = parent.Time + c.config.Period
+	if header.Time < uint64(time.Now().Unix()) {
+		header.Time = uint64(time.Now().Unix())
+	}
+	return nil
+}
+
+// Finalize mengimplementasikan consensus.Engine, memastikan tidak ada paman dan
+// tidak ada block rewards yang diberikan.
+func (c *Clique) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header) {
+	// Tidak ada block rewards di PoA, jadi status tetap dan paman dibuang
+	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
+	header.UncleHash = types.CalcUncleHash(nil)
+}
+
+// FinalizeAndAssemble mengimplementasikan consensus.Engine, memastikan tidak ada paman
+// dan tidak ada block rewards, lalu mengembalikan blok terakhir.
+func (c *Clique) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
+	// Finalisasi blok
+	c.Finalize(chain, header, state, txs, uncles)
+
+	// Rakit dan kembalikan blok terakhir untuk disegel
+	return types.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil)), nil
+}
+
+// Authorize menyuntikkan kunci privat ke dalam mesin konsensus untuk mencetak
+// blok baru.
+func (c *Clique) Authorize(signer common.Address, signFn SignerFn) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.signer = signer
+	c.signFn = signFn
+}
+
+// Seal mengimplementasikan consensus.Engine, mencoba membuat blok tersegel
+// menggunakan kredensial penandatangan lokal.
+func (c *Clique) Seal(chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
+	header := block.Header()
+
+	// Penyegelan blok genesis tidak didukung
+	number := header.Number.Uint64()
+	if number == 0 {
+		return errUnknownBlock
+	}
+	// Untuk chain dengan periode 0, tolak menyegel blok kosong (tidak ada reward tetapi terus berputar)
+	if c.config.Period == 0 && len(block.Transactions()) == 0 {
+		return errors.New("sealing paused while waiting for transactions")
+	}
+	// Jangan menahan field signer selama seluruh prosedur penyegelan
+	c.lock.RLock()
+	signer, signFn := c.signer, c.signFn
+	c.lock.RUnlock()
+
+	// Berhenti jika kita tidak berwenang menandatangani blok
+	snap, err := c.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	if _, authorized := snap.Signers[signer]; !authorized {
+		return errUnauthorizedSigner
+	}
+	// Jika kita termasuk signer terbaru, tunggu blok berikutnya
+	for seen, recent := range snap.Recents {
+		if recent == signer {
+			// Signer termasuk yang baru menyegel, tunggu hanya jika blok ini tidak menggesernya keluar
+			if limit := uint64(len(snap.Signers)/2 + 1); number < limit || seen > number-limit {
+				return errors.New("signed recently, must wait for others")
+			}
+		}
+	}
+	// Protokol mengizinkan kita menandatangani blok, tunggu giliran kita
+	delay := time.Until(time.Unix(int64(header.Time), 0))
+	if header.Difficulty.Cmp(diffNoTurn) == 0 {
+		// Bukan giliran kita secara eksplisit, tunda sedikit
+		wiggle := time.Duration(len(snap.Signers)/2+1) * wiggleTime
+		delay += time.Duration(rand.Int63n(int64(wiggle)))
+
+		log.Trace("Out-of-turn signing requested", "wiggle", common.PrettyDuration(wiggle))
+	}
+	// Tandatangani header
+	sighash, err := signFn(accounts.Account{Address: signer}, accounts.MimetypeClique, CliqueRLP(header))
+	if err != nil {
+		return err
+	}
+	copy(header.Extra[len(header.Extra)-extraSeal:], sighash)
+
+	// Tunggu sampai penyegelan dihentikan atau jeda habis
+	log.Trace("Waiting for slot to sign and propagate", "delay", common.PrettyDuration(delay))
+	go func() {
+		select {
+		case <-stop:
+			return
+		case <-time.After(delay):
+		}
+		select {
+		case results <- block.WithSeal(header):
+		default:
+			log.Warn("Sealing result is not read by miner", "sealhash", SealHash(header))
+		}
+	}()
+	return nil
+}
+
+// CalcDifficulty adalah algoritma penyesuaian kesulitan. Ini mengembalikan kesulitan
+// yang harus dimiliki oleh blok baru:
+// * DIFF_NOTURN(1) jika BLOCK_NUMBER % SIGNER_COUNT != SIGNER_INDEX
+// * DIFF_INTURN(2) jika BLOCK_NUMBER % SIGNER_COUNT == SIGNER_INDEX
+func (c *Clique) CalcDifficulty(chain consensus.ChainHeaderReader, time uint64, parent *types.Header) *big.Int {
+	snap, err := c.snapshot(chain, parent.Number.Uint64(), parent.Hash(), nil)
+	if err != nil {
+		return nil
+	}
+	c.lock.RLock()
+	signer := c.signer
+	c.lock.RUnlock()
+
+	return calcDifficulty(snap, signer)
+}
+
+// calcDifficulty mengembalikan kesulitan untuk blok setelah snapshot yang diberikan
+// berdasarkan giliran signer.
+func calcDifficulty(snap *Snapshot, signer common.Address) *big.Int {
+	if snap.inturn(snap.Number+1, signer) {
+		return new(big.Int).Set(diffInTurn)
+	}
+	return new(big.Int).Set(diffNoTurn)
+}
+
+// SealHash mengembalikan hash dari sebuah blok sebelum disegel.
+func (c *Clique) SealHash(header *types.Header) common.Hash {
+	return SealHash(header)
+}
+
+// Close mengimplementasikan consensus.Engine. Tidak melakukan apa-apa karena clique
+// tidak memiliki utas latar belakang.
+func (c *Clique) Close() error {
+	return nil
+}
+
+// APIs mengimplementasikan consensus.Engine, mengembalikan API RPC untuk
+// mengendalikan pemungutan suara signer.
+func (c *Clique) APIs(chain consensus.ChainHeaderReader) []rpc.API {
+	return []rpc.API{{
+		Namespace: "clique",
+		Service:   &API{chain: chain, clique: c},
+	}}
+}
+
+// SealHash mengembalikan hash dari sebuah blok sebelum disegel.
+func SealHash(header *types.Header) (hash common.Hash) {
+	hasher := sha3.NewLegacyKeccak256()
+	encodeSigHeader(hasher, header)
+	hasher.(crypto.KeccakState).Read(hash[:])
+	return hash
+}
+
+// CliqueRLP mengembalikan byte rlp yang perlu ditandatangani untuk penyegelan
+// proof-of-authority. RLP tersebut terdiri dari seluruh header kecuali signature
+// 65 byte di akhir extra data.
+//
+// Catatan, metode ini membutuhkan extra data minimal 65 byte, jika tidak akan panic.
+// Ini dilakukan agar kedua bentuk (dengan atau tanpa signature) tidak tercampur,
+// yang bisa disalahgunakan untuk menghasilkan hash berbeda bagi header yang sama.
+func CliqueRLP(header *types.Header) []byte {
+	b := new(bytes.Buffer)
+	encodeSigHeader(b, header)
+	return b.Bytes()
+}
+
+// encodeSigHeader menulis encoding RLP dari header tanpa segel signature.
+func encodeSigHeader(w io.Writer, header *types.Header) {
+	enc := []interface{}{
+		header.ParentHash,
+		header.UncleHash,
+		header.Coinbase,
+		header.Root,
+		header.TxHash,
+		header.ReceiptHash,
+		header.Bloom,
+		header.Difficulty,
+		header.Number,
+		header.GasLimit,
+		header.GasUsed,
+		header.Time,
+		header.Extra[:len(header.Extra)-crypto.SignatureLength], // Ya, ini akan panic jika extra terlalu pendek
+		header.MixDigest,
+		header.Nonce,
+	}
+	if header.BaseFee != nil {
+		enc = append(enc, header.BaseFee)
+	}
+	if err := rlp.Encode(w, enc); err != nil {
+		panic("can't encode: " + err.Error())
+	}
+}
@@ -0,0 +1,729 @@
+package clique
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/consensustest"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// testerAccountPool memetakan nama akun di tes ke kunci privat yang dibuat saat
+// pertama kali dipakai.
+type testerAccountPool struct {
+	accounts map[string]*ecdsa.PrivateKey
+}
+
+func newTesterAccountPool() *testerAccountPool {
+	return &testerAccountPool{accounts: make(map[string]*ecdsa.PrivateKey)}
+}
+
+// address mengembalikan alamat akun dengan nama tertentu, atau alamat nol untuk
+// nama kosong.
+func (ap *testerAccountPool) address(account string) common.Address {
+	if account == "" {
+		return common.Address{}
+	}
+	if ap.accounts[account] == nil {
+		ap.accounts[account], _ = crypto.GenerateKey()
+	}
+	return crypto.PubkeyToAddress(ap.accounts[account].PublicKey)
+}
+
+// addresses mengembalikan alamat akun dengan nama tertentu, diurutkan naik.
+func (ap *testerAccountPool) addresses(accounts []string) []common.Address {
+	addrs := make([]common.Address, len(accounts))
+	for i, account := range accounts {
+		addrs[i] = ap.address(account)
+	}
+	sort.Sort(signersAscending(addrs))
+	return addrs
+}
+
+// checkpoint menulis daftar signer ke extra-data header.
+func (ap *testerAccountPool) checkpoint(header *types.Header, signers []string) {
+	header.Extra = make([]byte, extraVanity+len(signers)*common.AddressLength+extraSeal)
+	for i, auth := range ap.addresses(signers) {
+		copy(header.Extra[extraVanity+i*common.AddressLength:], auth.Bytes())
+	}
+}
+
+// sign menandatangani header dengan akun yang diberikan.
+func (ap *testerAccountPool) sign(header *types.Header, signer string) {
+	ap.address(signer)
+	sig, _ := crypto.Sign(SealHash(header).Bytes(), ap.accounts[signer])
+	copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+}
+
+// signFn mengembalikan SignerFn untuk akun yang diberikan.
+func (ap *testerAccountPool) signFn(signer string) SignerFn {
+	ap.address(signer)
+	return func(account accounts.Account, mimeType string, message []byte) ([]byte, error) {
+		return crypto.Sign(crypto.Keccak256(message), ap.accounts[signer])
+	}
+}
+
+// newTestChain membuat rantai di memori dengan signer genesis yang diberikan dan
+// mesin clique baru di atasnya.
+func newTestChain(ap *testerAccountPool, period, epoch uint64, signers ...string) (*consensustest.HeaderChain, *Clique) {
+	config := *params.AllCliqueProtocolChanges
+	config.Clique = &params.CliqueConfig{Period: period, Epoch: epoch}
+
+	genesis := &types.Header{
+		Number:     big.NewInt(0),
+		Time:       uint64(time.Now().Unix()) - 100000,
+		Difficulty: big.NewInt(1),
+		GasLimit:   params.GenesisGasLimit,
+		BaseFee:    big.NewInt(params.InitialBaseFee),
+		UncleHash:  uncleHash,
+	}
+	ap.checkpoint(genesis, signers)
+	return consensustest.NewHeaderChain(&config, genesis), New(config.Clique, rawdb.NewMemoryDatabase())
+}
+
+// testerVote adalah satu blok yang ditandatangani akun tertentu, dengan atau tanpa
+// suara clique.
+type testerVote struct {
+	signer     string
+	voted      string
+	auth       bool
+	checkpoint []string
+	newbatch   bool
+}
+
+// Menguji bahwa pemungutan suara signer clique dihitung dengan benar untuk berbagai
+// skenario sederhana maupun rumit, dan bahwa beberapa kasus khusus gagal dengan
+// benar.
+func TestVoting(t *testing.T) {
+	tests := []struct {
+		epoch   uint64
+		signers []string
+		votes   []testerVote
+		results []string
+		failure error
+	}{
+		{
+			// Satu signer, tanpa suara
+			signers: []string{"A"},
+			votes:   []testerVote{{signer: "A"}},
+			results: []string{"A"},
+		}, {
+			// Satu signer memilih menambah dua signer (hanya yang pertama diterima, yang kedua butuh 2 suara)
+			signers: []string{"A"},
+			votes: []testerVote{
+				{signer: "A", voted: "B", auth: true},
+				{signer: "B"},
+				{signer: "A", voted: "C", auth: true},
+			},
+			results: []string{"A", "B"},
+		}, {
+			// Dua signer memilih menambah tiga signer (hanya dua pertama diterima, yang ketiga sudah butuh 3 suara)
+			signers: []string{"A", "B"},
+			votes: []testerVote{
+				{signer: "A", voted: "C", auth: true},
+				{signer: "B", voted: "C", auth: true},
+				{signer: "A", voted: "D", auth: true},
+				{signer: "B", voted: "D", auth: true},
+				{signer: "C"},
+				{signer: "A", voted: "E", auth: true},
+				{signer: "B", voted: "E", auth: true},
+			},
+			results: []string{"A", "B", "C", "D"},
+		}, {
+			// Satu signer menghapus dirinya sendiri (aneh, tapi diizinkan agar tidak ada kasus khusus)
+			signers: []string{"A"},
+			votes: []testerVote{
+				{signer: "A", voted: "A", auth: false},
+			},
+			results: []string{},
+		}, {
+			// Dua signer, butuh persetujuan keduanya untuk menghapus salah satunya (tidak terpenuhi)
+			signers: []string{"A", "B"},
+			votes: []testerVote{
+				{signer: "A", voted: "B", auth: false},
+			},
+			results: []string{"A", "B"},
+		}, {
+			// Dua signer, butuh persetujuan keduanya untuk menghapus salah satunya (terpenuhi)
+			signers: []string{"A", "B"},
+			votes: []testerVote{
+				{signer: "A", voted: "B", auth: false},
+				{signer: "B", voted: "B", auth: false},
+			},
+			results: []string{"A"},
+		}, {
+			// Tiga signer, dua di antaranya menghapus yang ketiga
+			signers: []string{"A", "B", "C"},
+			votes: []testerVote{
+				{signer: "A", voted: "C", auth: false},
+				{signer: "B", voted: "C", auth: false},
+			},
+			results: []string{"A", "B"},
+		}, {
+			// Empat signer, konsensus dua tidak cukup untuk menghapus siapa pun
+			signers: []string{"A", "B", "C", "D"},
+			votes: []testerVote{
+				{signer: "A", voted: "C", auth: false},
+				{signer: "B", voted: "C", auth: false},
+			},
+			results: []string{"A", "B", "C", "D"},
+		}, {
+			// Empat signer, konsensus tiga sudah cukup untuk menghapus satu signer
+			signers: []string{"A", "B", "C", "D"},
+			votes: []testerVote{
+				{signer: "A", voted: "D", auth: false},
+				{signer: "B", voted: "D", auth: false},
+				{signer: "C", voted: "D", auth: false},
+			},
+			results: []string{"A", "B", "C"},
+		}, {
+			// Otorisasi dihitung sekali per signer per target
+			signers: []string{"A", "B"},
+			votes: []testerVote{
+				{signer: "A", voted: "C", auth: true},
+				{signer: "B"},
+				{signer: "A", voted: "C", auth: true},
+				{signer: "B"},
+				{signer: "A", voted: "C", auth: true},
+			},
+			results: []string{"A", "B"},
+		}, {
+			// Mengotorisasi beberapa akun bersamaan diizinkan
+			signers: []string{"A", "B"},
+			votes: []testerVote{
+				{signer: "A", voted: "C", auth: true},
+				{signer: "B"},
+				{signer: "A", voted: "D", auth: true},
+				{signer: "B"},
+				{signer: "A"},
+				{signer: "B", voted: "D", auth: true},
+				{signer: "A"},
+				{signer: "B", voted: "C", auth: true},
+			},
+			results: []string{"A", "B", "C", "D"},
+		}, {
+			// Deotorisasi dihitung sekali per signer per target
+			signers: []string{"A", "B"},
+			votes: []testerVote{
+				{signer: "A", voted: "B", auth: false},
+				{signer: "B"},
+				{signer: "A", voted: "B", auth: false},
+				{signer: "B"},
+				{signer: "A", voted: "B", auth: false},
+			},
+			results: []string{"A", "B"},
+		}, {
+			// Mendeotorisasi beberapa akun bersamaan diizinkan
+			signers: []string{"A", "B", "C", "D"},
+			votes: []testerVote{
+				{signer: "A", voted: "C", auth: false},
+				{signer: "B"},
+				{signer: "C"},
+				{signer: "A", voted: "D", auth: false},
+				{signer: "B"},
+				{signer: "C"},
+				{signer: "A"},
+				{signer: "B", voted: "D", auth: false},
+				{signer: "C", voted: "D", auth: false},
+				{signer: "A"},
+				{signer: "B", voted: "C", auth: false},
+			},
+			results: []string{"A", "B"},
+		}, {
+			// Suara dari signer yang dihapus langsung dibuang (suara deauth)
+			signers: []string{"A", "B", "C"},
+			votes: []testerVote{
+				{signer: "C", voted: "B", auth: false},
+				{signer: "A", voted: "C", auth: false},
+				{signer: "B", voted: "C", auth: false},
+				{signer: "A", voted: "B", auth: false},
+			},
+			results: []string{"A", "B"},
+		}, {
+			// Suara dari signer yang dihapus langsung dibuang (suara auth)
+			signers: []string{"A", "B", "C"},
+			votes: []testerVote{
+				{signer: "C", voted: "D", auth: true},
+				{signer: "A", voted: "C", auth: false},
+				{signer: "B", voted: "C", auth: false},
+				{signer: "A", voted: "D", auth: true},
+			},
+			results: []string{"A", "B"},
+		}, {
+			// Perubahan berantai tidak diizinkan, hanya akun yang dipilih yang boleh berubah
+			signers: []string{"A", "B", "C", "D"},
+			votes: []testerVote{
+				{signer: "A", voted: "C", auth: false},
+				{signer: "B"},
+				{signer: "C"},
+				{signer: "A", voted: "D", auth: false},
+				{signer: "B", voted: "C", auth: false},
+				{signer: "C"},
+				{signer: "A"},
+				{signer: "B", voted: "D", auth: false},
+				{signer: "C", voted: "D", auth: false},
+			},
+			results: []string{"A", "B", "C"},
+		}, {
+			// Perubahan yang mencapai konsensus di luar jalur (lewat deauth) dijalankan saat disentuh
+			signers: []string{"A", "B", "C", "D"},
+			votes: []testerVote{
+				{signer: "A", voted: "C", auth: false},
+				{signer: "B"},
+				{signer: "C"},
+				{signer: "A", voted: "D", auth: false},
+				{signer: "B", voted: "C", auth: false},
+				{signer: "C"},
+				{signer: "A"},
+				{signer: "B", voted: "D", auth: false},
+				{signer: "C", voted: "D", auth: false},
+				{signer: "A"},
+				{signer: "C", voted: "C", auth: true},
+			},
+			results: []string{"A", "B"},
+		}, {
+			// Perubahan yang mencapai konsensus di luar jalur (lewat deauth) bisa kehilangan konsensus saat pertama disentuh
+			signers: []string{"A", "B", "C", "D"},
+			votes: []testerVote{
+				{signer: "A", voted: "C", auth: false},
+				{signer: "B"},
+				{signer: "C"},
+				{signer: "A", voted: "D", auth: false},
+				{signer: "B", voted: "C", auth: false},
+				{signer: "C"},
+				{signer: "A"},
+				{signer: "B", voted: "D", auth: false},
+				{signer: "C", voted: "D", auth: false},
+				{signer: "A"},
+				{signer: "B", voted: "C", auth: true},
+			},
+			results: []string{"A", "B", "C"},
+		}, {
+			// Suara tertunda tidak boleh bertahan setelah status otorisasi berubah. Kasus
+			// ini hanya muncul jika signer cepat ditambah, dihapus lalu ditambah lagi (atau
+			// sebaliknya) sementara salah satu pemberi suara awal dihapus. Suara lama yang
+			// masih tersimpan akan mengacaukan hasil akhir.
+			signers: []string{"A", "B", "C", "D", "E"},
+			votes: []testerVote{
+				{signer: "A", voted: "F", auth: true}, // Otorisasi F, butuh 3 suara
+				{signer: "B", voted: "F", auth: true},
+				{signer: "C", voted: "F", auth: true},
+				{signer: "D", voted: "F", auth: false}, // Deotorisasi F, butuh 4 suara (suara A sebelumnya dibiarkan)
+				{signer: "E", voted: "F", auth: false},
+				{signer: "B", voted: "F", auth: false},
+				{signer: "C", voted: "F", auth: false},
+				{signer: "D", voted: "F", auth: true}, // Hampir mengotorisasi F, butuh 2/3 suara
+				{signer: "E", voted: "F", auth: true},
+				{signer: "B", voted: "A", auth: false}, // Deotorisasi A, butuh 3 suara
+				{signer: "C", voted: "A", auth: false},
+				{signer: "D", voted: "A", auth: false},
+				{signer: "B", voted: "F", auth: true}, // Selesaikan otorisasi F, butuh 3/3 suara
+			},
+			results: []string{"B", "C", "D", "E", "F"},
+		}, {
+			// Transisi epoch mereset semua suara agar rantai bisa di-checkpoint
+			epoch:   3,
+			signers: []string{"A", "B"},
+			votes: []testerVote{
+				{signer: "A", voted: "C", auth: true},
+				{signer: "B"},
+				{signer: "A", checkpoint: []string{"A", "B"}},
+				{signer: "B", voted: "C", auth: true},
+			},
+			results: []string{"A", "B"},
+		}, {
+			// Signer yang tidak berwenang tidak boleh menandatangani blok
+			signers: []string{"A"},
+			votes: []testerVote{
+				{signer: "B"},
+			},
+			failure: errUnauthorizedSigner,
+		}, {
+			// Signer berwenang yang baru menandatangani tidak boleh menandatangani lagi
+			signers: []string{"A", "B"},
+			votes: []testerVote{
+				{signer: "A"},
+				{signer: "A"},
+			},
+			failure: errRecentlySigned,
+		}, {
+			// Signature terbaru tidak boleh direset oleh checkpoint yang diimpor dalam satu batch
+			epoch:   3,
+			signers: []string{"A", "B", "C"},
+			votes: []testerVote{
+				{signer: "A"},
+				{signer: "B"},
+				{signer: "A", checkpoint: []string{"A", "B", "C"}},
+				{signer: "A"},
+			},
+			failure: errRecentlySigned,
+		}, {
+			// Signature terbaru tidak boleh direset oleh checkpoint yang diimpor di batch
+			// baru (https://github.com/ethereum/go-ethereum/issues/17593). Kasus ini pernah
+			// memecah konsensus Rinkeby.
+			epoch:   3,
+			signers: []string{"A", "B", "C"},
+			votes: []testerVote{
+				{signer: "A"},
+				{signer: "B"},
+				{signer: "A", checkpoint: []string{"A", "B", "C"}},
+				{signer: "A", newbatch: true},
+			},
+			failure: errRecentlySigned,
+		}}
+	for i, tt := range tests {
+		ap := newTesterAccountPool()
+		chain, engine := newTestChain(ap, 1, tt.epoch, tt.signers...)
+
+		// Susun semua header dari suara yang diberikan tanpa memasukkannya ke rantai
+		var headers []*types.Header
+		parent := chain.CurrentHeader()
+		for _, vote := range tt.votes {
+			header := &types.Header{
+				ParentHash: parent.Hash(),
+				Number:     new(big.Int).Add(parent.Number, common.Big1),
+				Time:       parent.Time + 1,
+				GasLimit:   parent.GasLimit,
+				BaseFee:    misc.CalcBaseFee(chain.Config(), parent),
+				UncleHash:  uncleHash,
+				Coinbase:   ap.address(vote.voted),
+				Extra:      make([]byte, extraVanity+extraSeal),
+			}
+			if vote.auth {
+				copy(header.Nonce[:], nonceAuthVote)
+			}
+			if vote.checkpoint != nil {
+				ap.checkpoint(header, vote.checkpoint)
+			}
+			// Kesulitan harus sesuai giliran; jika snapshot induk tidak bisa dibuat,
+			// biarkan verifikasi yang melaporkan kesalahannya
+			header.Difficulty = new(big.Int).Set(diffNoTurn)
+			if snap, err := engine.snapshot(chain, parent.Number.Uint64(), parent.Hash(), headers); err == nil {
+				header.Difficulty = calcDifficulty(snap, ap.address(vote.signer))
+			}
+			ap.sign(header, vote.signer)
+			headers = append(headers, header)
+			parent = header
+		}
+		// Bagi header menjadi beberapa batch impor untuk menguji kasus khusus
+		batches := [][]*types.Header{nil}
+		for j, header := range headers {
+			if tt.votes[j].newbatch {
+				batches = append(batches, nil)
+			}
+			batches[len(batches)-1] = append(batches[len(batches)-1], header)
+		}
+		// Verifikasi semua batch seperti impor rantai, berhenti pada error pertama
+		var err error
+		for j, batch := range batches {
+			if err = verifyBatch(chain, engine, batch); err != nil {
+				if j < len(batches)-1 {
+					t.Errorf("test %d: failed to import batch %d: %v", i, j, err)
+				}
+				break
+			}
+		}
+		if err != tt.failure {
+			t.Errorf("test %d: failure mismatch: have %v, want %v", i, err, tt.failure)
+		}
+		if tt.failure != nil || err != nil {
+			continue
+		}
+		// Tidak ada kegagalan, bandingkan daftar signer terakhir
+		head := headers[len(headers)-1]
+		snap, err := engine.snapshot(chain, head.Number.Uint64(), head.Hash(), nil)
+		if err != nil {
+			t.Errorf("test %d: failed to retrieve voting snapshot: %v", i, err)
+			continue
+		}
+		result, signers := snap.signers(), ap.addresses(tt.results)
+		if len(result) != len(signers) {
+			t.Errorf("test %d: signers mismatch: have %x, want %x", i, result, signers)
+			continue
+		}
+		for j := range result {
+			if !bytes.Equal(result[j][:], signers[j][:]) {
+				t.Errorf("test %d, signer %d: signer mismatch: have %x, want %x", i, j, result[j], signers[j])
+			}
+		}
+	}
+}
+
+// verifyBatch memverifikasi header dengan VerifyHeaders lalu memasukkan yang lolos
+// ke rantai, dan mengembalikan error pertama.
+func verifyBatch(chain *consensustest.HeaderChain, engine *Clique, batch []*types.Header) error {
+	seals := make([]bool, len(batch))
+	for i := range seals {
+		seals[i] = true
+	}
+	abort, results := engine.VerifyHeaders(chain, batch, seals)
+	defer close(abort)
+
+	for _, header := range batch {
+		if err := <-results; err != nil {
+			return err
+		}
+		if err := chain.Insert(header); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newHeader membuat header di atas induk yang ditandatangani signer tertentu, dengan
+// kesulitan sesuai gilirannya.
+func newHeader(ap *testerAccountPool, chain *consensustest.HeaderChain, engine *Clique, parent *types.Header, signer string) *types.Header {
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		Time:       parent.Time + 1,
+		GasLimit:   parent.GasLimit,
+		BaseFee:    misc.CalcBaseFee(chain.Config(), parent),
+		UncleHash:  uncleHash,
+		Extra:      make([]byte, extraVanity+extraSeal),
+		Difficulty: new(big.Int).Set(diffNoTurn),
+	}
+	if snap, err := engine.snapshot(chain, parent.Number.Uint64(), parent.Hash(), nil); err == nil {
+		header.Difficulty = calcDifficulty(snap, ap.address(signer))
+	}
+	ap.sign(header, signer)
+	return header
+}
+
+// Menguji bahwa header yang melanggar aturan clique ditolak dengan error yang tepat.
+func TestVerifyHeaderReject(t *testing.T) {
+	ap := newTesterAccountPool()
+	chain, engine := newTestChain(ap, 1, 0, "A", "B")
+	if err := chain.Insert(newHeader(ap, chain, engine, chain.CurrentHeader(), "A")); err != nil {
+		t.Fatalf("failed to insert header: %v", err)
+	}
+	parent := chain.CurrentHeader()
+
+	tests := []struct {
+		name   string
+		mutate func(header *types.Header)
+		want   error
+	}{
+		{"valid", func(h *types.Header) {}, nil},
+		{"no number", func(h *types.Header) { h.Number = nil }, errUnknownBlock},
+		{"future", func(h *types.Header) { h.Time = uint64(time.Now().Unix()) + 100 }, consensus.ErrFutureBlock},
+		{"checkpoint beneficiary", func(h *types.Header) {
+			h.Number = big.NewInt(int64(engine.config.Epoch))
+			h.Coinbase = common.Address{1}
+		}, errInvalidCheckpointBeneficiary},
+		{"invalid vote", func(h *types.Header) { h.Nonce = types.BlockNonce{1} }, errInvalidVote},
+		{"checkpoint vote", func(h *types.Header) {
+			h.Number = big.NewInt(int64(engine.config.Epoch))
+			copy(h.Nonce[:], nonceAuthVote)
+		}, errInvalidCheckpointVote},
+		{"missing vanity", func(h *types.Header) { h.Extra = make([]byte, extraVanity-1) }, errMissingVanity},
+		{"missing signature", func(h *types.Header) { h.Extra = make([]byte, extraVanity) }, errMissingSignature},
+		{"extra signers", func(h *types.Header) {
+			h.Extra = make([]byte, extraVanity+common.AddressLength+extraSeal)
+		}, errExtraSigners},
+		{"invalid checkpoint signers", func(h *types.Header) {
+			h.Number = big.NewInt(int64(engine.config.Epoch))
+			h.Extra = make([]byte, extraVanity+1+extraSeal)
+		}, errInvalidCheckpointSigners},
+		{"mix digest", func(h *types.Header) { h.MixDigest = common.Hash{1} }, consensus.ErrInvalidMixDigest},
+		{"uncle hash", func(h *types.Header) { h.UncleHash = common.Hash{1} }, consensus.ErrInvalidUncleHash},
+		{"invalid difficulty", func(h *types.Header) { h.Difficulty = big.NewInt(3) }, consensus.ErrInvalidDifficulty},
+		{"unknown ancestor", func(h *types.Header) { h.ParentHash = common.Hash{1} }, consensus.ErrUnknownAncestor},
+		{"timestamp", func(h *types.Header) { h.Time = parent.Time }, consensus.ErrInvalidTimestamp},
+		{"wrong difficulty", func(h *types.Header) {
+			if h.Difficulty.Cmp(diffInTurn) == 0 {
+				h.Difficulty = new(big.Int).Set(diffNoTurn)
+			} else {
+				h.Difficulty = new(big.Int).Set(diffInTurn)
+			}
+			ap.sign(h, "B")
+		}, errWrongDifficulty},
+		{"unauthorized", func(h *types.Header) { ap.sign(h, "C") }, errUnauthorizedSigner},
+		{"recently signed", func(h *types.Header) {
+			h.Difficulty = calcDifficulty(mustSnapshot(t, chain, engine, parent), ap.address("A"))
+			ap.sign(h, "A")
+		}, errRecentlySigned},
+	}
+	for _, test := range tests {
+		header := newHeader(ap, chain, engine, parent, "B")
+		test.mutate(header)
+		if err := engine.VerifyHeader(chain, header, true); err != test.want {
+			t.Errorf("%s: error mismatch: have %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+// mustSnapshot mengambil snapshot voting pada header tertentu.
+func mustSnapshot(t *testing.T, chain *consensustest.HeaderChain, engine *Clique, header *types.Header) *Snapshot {
+	t.Helper()
+	snap, err := engine.snapshot(chain, header.Number.Uint64(), header.Hash(), nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve snapshot: %v", err)
+	}
+	return snap
+}
+
+// Menguji bahwa signer yang berwenang bisa menyegel blok yang lolos verifikasi, dan
+// bahwa signer yang tidak berwenang atau baru menyegel ditolak.
+func TestSeal(t *testing.T) {
+	ap := newTesterAccountPool()
+	chain, engine := newTestChain(ap, 1, 0, "A", "B")
+	if err := chain.Insert(newHeader(ap, chain, engine, chain.CurrentHeader(), "A")); err != nil {
+		t.Fatalf("failed to insert header: %v", err)
+	}
+	parent := chain.CurrentHeader()
+
+	// Signer yang baru menyegel dan yang tidak berwenang tidak boleh menyegel
+	engine.Authorize(ap.address("A"), ap.signFn("A"))
+	block := types.NewBlockWithHeader(newHeader(ap, chain, engine, parent, "A"))
+	if err := engine.Seal(chain, block, make(chan *types.Block, 1), nil); err == nil {
+		t.Errorf("recent signer sealed a block")
+	}
+	engine.Authorize(ap.address("C"), ap.signFn("C"))
+	block = types.NewBlockWithHeader(newHeader(ap, chain, engine, parent, "C"))
+	if err := engine.Seal(chain, block, make(chan *types.Block, 1), nil); err != errUnauthorizedSigner {
+		t.Errorf("unauthorized error mismatch: have %v, want %v", err, errUnauthorizedSigner)
+	}
+	genesis := types.NewBlockWithHeader(chain.GetHeaderByNumber(0))
+	if err := engine.Seal(chain, genesis, make(chan *types.Block, 1), nil); err != errUnknownBlock {
+		t.Errorf("genesis error mismatch: have %v, want %v", err, errUnknownBlock)
+	}
+	// Signer yang berwenang menyegel blok yang lolos verifikasi penuh
+	engine.Authorize(ap.address("B"), ap.signFn("B"))
+	header := newHeader(ap, chain, engine, parent, "B")
+	header.Extra = make([]byte, extraVanity+extraSeal)
+
+	results := make(chan *types.Block, 1)
+	if err := engine.Seal(chain, types.NewBlockWithHeader(header), results, nil); err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	select {
+	case sealed := <-results:
+		if err := engine.VerifyHeader(chain, sealed.Header(), true); err != nil {
+			t.Errorf("sealed block rejected: %v", err)
+		}
+		if author, _ := engine.Author(sealed.Header()); author != ap.address("B") {
+			t.Errorf("author mismatch: have %x, want %x", author, ap.address("B"))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("sealing result timeout")
+	}
+}
+
+// Menguji bahwa statistik signer menghitung blok yang disegel, giliran yang
+// dilewatkan dan blok terakhir setiap signer di dalam jendela yang diminta.
+func TestSealerStats(t *testing.T) {
+	ap := newTesterAccountPool()
+	chain, engine := newTestChain(ap, 1, 0, "A", "B", "C")
+
+	// Urutkan nama signer sesuai alamatnya agar giliran mudah dibaca: signer ke-i
+	// mendapat giliran pada blok dengan nomor i modulo 3
+	names := []string{"A", "B", "C"}
+	sort.Slice(names, func(i, j int) bool {
+		return bytes.Compare(ap.address(names[i]).Bytes(), ap.address(names[j]).Bytes()) < 0
+	})
+	// Blok 3 dan 4 disegel di luar giliran, sehingga signer 0 dan 1 melewatkan gilirannya
+	for _, sealer := range []int{1, 2, 1, 0, 2, 0} {
+		if err := chain.Insert(newHeader(ap, chain, engine, chain.CurrentHeader(), names[sealer])); err != nil {
+			t.Fatalf("failed to insert header: %v", err)
+		}
+	}
+	api := &API{chain: chain, clique: engine}
+	window := func(n uint64) *uint64 { return &n }
+
+	tests := []struct {
+		window *uint64
+		from   uint64
+		want   []SealerStats
+	}{
+		{nil, 1, []SealerStats{{2, 1, 6, 0}, {2, 1, 3, 0}, {2, 0, 5, 0}}},
+		{window(0), 1, []SealerStats{{2, 1, 6, 0}, {2, 1, 3, 0}, {2, 0, 5, 0}}},
+		{window(3), 4, []SealerStats{{2, 0, 6, 0}, {0, 1, 0, 0}, {1, 0, 5, 0}}},
+	}
+	for i, tt := range tests {
+		activity, err := api.GetSealerStats(nil, tt.window)
+		if err != nil {
+			t.Fatalf("test %d: failed to retrieve stats: %v", i, err)
+		}
+		if activity.From != tt.from || activity.To != 6 {
+			t.Errorf("test %d: window mismatch: have %d-%d, want %d-%d", i, activity.From, activity.To, tt.from, 6)
+		}
+		if len(activity.Sealers) != len(tt.want) {
+			t.Errorf("test %d: sealer count mismatch: have %d, want %d", i, len(activity.Sealers), len(tt.want))
+		}
+		for j, want := range tt.want {
+			have := activity.Sealers[ap.address(names[j])]
+			if have == nil {
+				t.Errorf("test %d, signer %d: missing stats", i, j)
+				continue
+			}
+			if want.LastSeen != 0 {
+				want.LastTime = chain.GetHeaderByNumber(want.LastSeen).Time
+			}
+			if *have != want {
+				t.Errorf("test %d, signer %d: stats mismatch: have %+v, want %+v", i, j, *have, want)
+			}
+		}
+	}
+}
+
+// Menguji bahwa giliran in-turn signer dihitung sampai akhir jumlah epoch yang
+// diminta, dan bahwa alamat yang bukan signer ditolak.
+func TestDuties(t *testing.T) {
+	ap := newTesterAccountPool()
+	chain, engine := newTestChain(ap, 1, 10, "A", "B", "C")
+	signers := ap.addresses([]string{"A", "B", "C"})
+
+	names := make(map[common.Address]string)
+	for _, name := range []string{"A", "B", "C"} {
+		names[ap.address(name)] = name
+	}
+	for number := uint64(1); number <= 3; number++ {
+		if err := chain.Insert(newHeader(ap, chain, engine, chain.CurrentHeader(), names[signers[number%3]])); err != nil {
+			t.Fatalf("failed to insert header: %v", err)
+		}
+	}
+	api := &API{chain: chain, clique: engine}
+	head := chain.CurrentHeader()
+	epochs := func(n uint64) *uint64 { return &n }
+
+	tests := []struct {
+		signer common.Address
+		epochs *uint64
+		want   []uint64
+	}{
+		{signers[0], nil, []uint64{6, 9}},
+		{signers[1], nil, []uint64{4, 7}},
+		{signers[0], epochs(2), []uint64{6, 9, 12, 15, 18}},
+		{signers[2], epochs(2), []uint64{5, 8, 11, 14, 17}},
+	}
+	for i, tt := range tests {
+		duties, err := api.GetDuties(tt.signer, tt.epochs)
+		if err != nil {
+			t.Fatalf("test %d: failed to retrieve duties: %v", i, err)
+		}
+		if len(duties) != len(tt.want) {
+			t.Errorf("test %d: duty count mismatch: have %v, want %v", i, duties, tt.want)
+			continue
+		}
+		for j, duty := range duties {
+			if duty.Number != tt.want[j] || duty.Time != head.Time+tt.want[j]-3 {
+				t.Errorf("test %d, duty %d: mismatch: have %d@%d, want %d@%d", i, j, duty.Number, duty.Time, tt.want[j], head.Time+tt.want[j]-3)
+			}
+		}
+	}
+	if _, err := api.GetDuties(ap.address("D"), nil); err != errUnauthorizedSigner {
+		t.Errorf("error mismatch: have %v, want %v", err, errUnauthorizedSigner)
+	}
+}
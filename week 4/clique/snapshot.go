@@ -0,0 +1,309 @@
+package clique
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// Vote mewakili satu suara yang diberikan oleh signer berwenang untuk mengubah
+// daftar otorisasi.
+type Vote struct {
+	Signer    common.Address `json:"signer"`    // Signer berwenang yang memberikan suara ini
+	Block     uint64         `json:"block"`     // Nomor blok tempat suara diberikan (untuk kedaluwarsa suara lama)
+	Address   common.Address `json:"address"`   // Akun yang dipilih untuk diubah otorisasinya
+	Authorize bool           `json:"authorize"` // Apakah akun akan diotorisasi atau dicabut
+}
+
+// Tally adalah penghitungan suara sederhana untuk menyimpan skor suara saat ini.
+// Suara yang menentang proposal tidak dihitung karena sama dengan tidak memilih.
+type Tally struct {
+	Authorize bool `json:"authorize"` // Apakah suara ini tentang menambah atau mengeluarkan seseorang
+	Votes     int  `json:"votes"`     // Jumlah suara sejauh ini yang ingin meloloskan proposal
+}
+
+// Snapshot adalah keadaan pemungutan suara otorisasi pada titik waktu tertentu.
+type Snapshot struct {
+	config   *params.CliqueConfig // Parameter mesin konsensus untuk mengatur perilaku
+	sigcache *lru.ARCCache        // Cache signature blok terbaru untuk mempercepat ecrecover
+
+	Number  uint64                      `json:"number"`  // Nomor blok tempat snapshot dibuat
+	Hash    common.Hash                 `json:"hash"`    // Hash blok tempat snapshot dibuat
+	Signers map[common.Address]struct{} `json:"signers"` // Kumpulan signer berwenang saat ini
+	Recents map[uint64]common.Address   `json:"recents"` // Kumpulan signer terbaru untuk perlindungan spam
+	Votes   []*Vote                     `json:"votes"`   // Daftar suara sesuai urutan waktu
+	Tally   map[common.Address]Tally    `json:"tally"`   // Penghitungan suara saat ini agar tidak dihitung ulang
+}
+
+// signersAscending mengimplementasikan sort.Interface untuk mengurutkan daftar alamat.
+type signersAscending []common.Address
+
+func (s signersAscending) Len() int           { return len(s) }
+func (s signersAscending) Less(i, j int) bool { return bytes.Compare(s[i][:], s[j][:]) < 0 }
+func (s signersAscending) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// newSnapshot membuat snapshot baru dengan parameter awal yang diberikan. Metode ini
+// tidak menginisialisasi kumpulan signer terbaru, jadi hanya gunakan untuk blok genesis
+// atau checkpoint terpercaya.
+func newSnapshot(config *params.CliqueConfig, sigcache *lru.ARCCache, number uint64, hash common.Hash, signers []common.Address) *Snapshot {
+	snap := &Snapshot{
+		config:   config,
+		sigcache: sigcache,
+		Number:   number,
+		Hash:     hash,
+		Signers:  make(map[common.Address]struct{}),
+		Recents:  make(map[uint64]common.Address),
+		Tally:    make(map[common.Address]Tally),
+	}
+	for _, signer := range signers {
+		snap.Signers[signer] = struct{}{}
+	}
+	return snap
+}
+
+// loadSnapshot memuat snapshot yang sudah ada dari database.
+func loadSnapshot(config *params.CliqueConfig, sigcache *lru.ARCCache, db ethdb.Database, hash common.Hash) (*Snapshot, error) {
+	blob, err := db.Get(append([]byte("clique-"), hash[:]...))
+	if err != nil {
+		return nil, err
+	}
+	snap := new(Snapshot)
+	if err := json.Unmarshal(blob, snap); err != nil {
+		return nil, err
+	}
+	snap.config = config
+	snap.sigcache = sigcache
+
+	return snap, nil
+}
+
+// store menyimpan snapshot ke dalam database.
+func (s *Snapshot) store(db ethdb.Database) error {
+	blob, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return db.Put(append([]byte("clique-"), s.Hash[:]...), blob)
+}
+
+// copy membuat salinan mendalam dari snapshot, kecuali suara-suara individualnya.
+func (s *Snapshot) copy() *Snapshot {
+	cpy := &Snapshot{
+		config:   s.config,
+		sigcache: s.sigcache,
+		Number:   s.Number,
+		Hash:     s.Hash,
+		Signers:  make(map[common.Address]struct{}),
+		Recents:  make(map[uint64]common.Address),
+		Votes:    make([]*Vote, len(s.Votes)),
+		Tally:    make(map[common.Address]Tally),
+	}
+	for signer := range s.Signers {
+		cpy.Signers[signer] = struct{}{}
+	}
+	for block, signer := range s.Recents {
+		cpy.Recents[block] = signer
+	}
+	for address, tally := range s.Tally {
+		cpy.Tally[address] = tally
+	}
+	copy(cpy.Votes, s.Votes)
+
+	return cpy
+}
+
+// validVote mengembalikan apakah suara yang diberikan masuk akal dalam konteks
+// snapshot ini (misalnya jangan menambah signer yang sudah berwenang).
+func (s *Snapshot) validVote(address common.Address, authorize bool) bool {
+	_, signer := s.Signers[address]
+	return (signer && !authorize) || (!signer && authorize)
+}
+
+// cast menambahkan suara baru ke dalam penghitungan.
+func (s *Snapshot) cast(address common.Address, authorize bool) bool {
+	// Pastikan suaranya bermakna
+	if !s.validVote(address, authorize) {
+		return false
+	}
+	// Masukkan suara ke penghitungan yang sudah ada atau yang baru
+	if old, ok := s.Tally[address]; ok {
+		old.Votes++
+		s.Tally[address] = old
+	} else {
+		s.Tally[address] = Tally{Authorize: authorize, Votes: 1}
+	}
+	return true
+}
+
+// uncast menghapus suara yang sebelumnya diberikan dari penghitungan.
+func (s *Snapshot) uncast(address common.Address, authorize bool) bool {
+	// Jika tidak ada penghitungan, suara ini menggantung, abaikan saja
+	tally, ok := s.Tally[address]
+	if !ok {
+		return false
+	}
+	// Pastikan kita hanya membatalkan suara yang dihitung
+	if tally.Authorize != authorize {
+		return false
+	}
+	// Batalkan suaranya
+	if tally.Votes > 1 {
+		tally.Votes--
+		s.Tally[address] = tally
+	} else {
+		delete(s.Tally, address)
+	}
+	return true
+}
+
+// apply membuat snapshot otorisasi baru dengan menerapkan header yang diberikan
+// ke snapshot asli.
+func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
+	// Izinkan daftar header kosong agar kode pemanggil lebih bersih
+	if len(headers) == 0 {
+		return s, nil
+	}
+	// Periksa bahwa header dapat diterapkan
+	for i := 0; i < len(headers)-1; i++ {
+		if headers[i+1].Number.Uint64() != headers[i].Number.Uint64()+1 {
+			return nil, errInvalidVotingChain
+		}
+	}
+	if headers[0].Number.Uint64() != s.Number+1 {
+		return nil, errInvalidVotingChain
+	}
+	// Iterasi header dan buat snapshot baru
+	snap := s.copy()
+
+	var (
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for i, header := range headers {
+		// Hapus semua suara pada blok checkpoint
+		number := header.Number.Uint64()
+		if number%s.config.Epoch == 0 {
+			snap.Votes = nil
+			snap.Tally = make(map[common.Address]Tally)
+		}
+		// Hapus signer tertua dari daftar terbaru agar boleh menyegel lagi
+		if limit := uint64(len(snap.Signers)/2 + 1); number >= limit {
+			delete(snap.Recents, number-limit)
+		}
+		// Pulihkan kunci otorisasi dan periksa terhadap daftar signer
+		signer, err := ecrecover(header, s.sigcache)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := snap.Signers[signer]; !ok {
+			return nil, errUnauthorizedSigner
+		}
+		for _, recent := range snap.Recents {
+			if recent == signer {
+				return nil, errRecentlySigned
+			}
+		}
+		snap.Recents[number] = signer
+
+		// Header sah, buang suara sebelumnya dari signer ini
+		for i, vote := range snap.Votes {
+			if vote.Signer == signer && vote.Address == header.Coinbase {
+				// Batalkan suara dari penghitungan cache
+				snap.uncast(vote.Address, vote.Authorize)
+
+				// Batalkan suara dari daftar kronologis
+				snap.Votes = append(snap.Votes[:i], snap.Votes[i+1:]...)
+				break // hanya satu suara yang diizinkan
+			}
+		}
+		// Hitung suara baru dari signer
+		var authorize bool
+		switch {
+		case bytes.Equal(header.Nonce[:], nonceAuthVote):
+			authorize = true
+		case bytes.Equal(header.Nonce[:], nonceDropVote):
+			authorize = false
+		default:
+			return nil, errInvalidVote
+		}
+		if snap.cast(header.Coinbase, authorize) {
+			snap.Votes = append(snap.Votes, &Vote{
+				Signer:    signer,
+				Block:     number,
+				Address:   header.Coinbase,
+				Authorize: authorize,
+			})
+		}
+		// Jika suara lolos, perbarui daftar signer
+		if tally := snap.Tally[header.Coinbase]; tally.Votes > len(snap.Signers)/2 {
+			if tally.Authorize {
+				snap.Signers[header.Coinbase] = struct{}{}
+			} else {
+				delete(snap.Signers, header.Coinbase)
+
+				// Daftar signer menyusut, hapus sisa cache signer terbaru
+				if limit := uint64(len(snap.Signers)/2 + 1); number >= limit {
+					delete(snap.Recents, number-limit)
+				}
+				// Buang semua suara yang pernah diberikan oleh signer yang dicabut
+				for i := 0; i < len(snap.Votes); i++ {
+					if snap.Votes[i].Signer == header.Coinbase {
+						// Batalkan suara dari penghitungan cache
+						snap.uncast(snap.Votes[i].Address, snap.Votes[i].Authorize)
+
+						// Batalkan suara dari daftar kronologis
+						snap.Votes = append(snap.Votes[:i], snap.Votes[i+1:]...)
+						i--
+					}
+				}
+			}
+			// Buang semua suara sebelumnya tentang akun yang baru berubah
+			for i := 0; i < len(snap.Votes); i++ {
+				if snap.Votes[i].Address == header.Coinbase {
+					snap.Votes = append(snap.Votes[:i], snap.Votes[i+1:]...)
+					i--
+				}
+			}
+			delete(snap.Tally, header.Coinbase)
+		}
+		// Jika proses terlalu lama (ecrecover), beri tahu pengguna sesekali
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Reconstructing voting history", "processed", i, "total", len(headers), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if time.Since(start) > 8*time.Second {
+		log.Info("Reconstructed voting history", "processed", len(headers), "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	snap.Number += uint64(len(headers))
+	snap.Hash = headers[len(headers)-1].Hash()
+
+	return snap, nil
+}
+
+// signers mengambil daftar signer berwenang dalam urutan naik.
+func (s *Snapshot) signers() []common.Address {
+	sigs := make([]common.Address, 0, len(s.Signers))
+	for sig := range s.Signers {
+		sigs = append(sigs, sig)
+	}
+	sort.Sort(signersAscending(sigs))
+	return sigs
+}
+
+// inturn mengembalikan apakah signer pada ketinggian blok tertentu sedang mendapat giliran.
+func (s *Snapshot) inturn(number uint64, signer common.Address) bool {
+	signers, offset := s.signers(), 0
+	for offset < len(signers) && signers[offset] != signer {
+		offset++
+	}
+	return (number % uint64(len(signers))) == uint64(offset)
+}
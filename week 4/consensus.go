@@ -42,6 +42,20 @@ type ChainReader interface {
 	GetBlock(hash common.Hash, number uint64) *types.Block
 }
 
+// ChainReceiptReader adalah antarmuka opsional untuk mesin yang membutuhkan receipt
+// atau hash kanonik, misalnya untuk menghitung fee burn atau bukti finalitas. Mesin
+// memeriksanya dengan type assertion pada ChainHeaderReader yang diterima di
+// Finalize, karena tidak semua pembaca rantai menyimpan receipt.
+type ChainReceiptReader interface {
+	ChainHeaderReader
+
+	// GetReceiptsByHash mengambil semua receipt transaksi dalam blok dengan hash.
+	GetReceiptsByHash(hash common.Hash) types.Receipts
+
+	// GetCanonicalHash mengambil hash blok kanonik untuk nomor tertentu.
+	GetCanonicalHash(number uint64) common.Hash
+}
+
 // Engine adalah mesin konsensus agnostik algoritma
 type Engine interface {
 	// Penulis mengambil alamat Ethereum dari akun yang mencetak yang diberikan
@@ -57,7 +71,8 @@ type Engine interface {
 	//  VerifyHeaders mirip dengan VerifyHeader, tetapi memverifikasi sekumpulan header
 	// bersamaan. Metode mengembalikan saluran keluar untuk membatalkan operasi dan
 	// saluran hasil untuk mengambil verifikasi asinkron (urutan adalah dari
-	// inputan slice)
+	// inputan slice). Implementasi boleh memverifikasi header secara paralel, misalnya
+	// dengan VerifyParallel, selama hasil tetap dikirim sesuai urutan input.
 	VerifyHeaders(chain ChainHeaderReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error)
 
 	// VerifyUncles memverifikasi bahwa paman blok yang diberikan sesuai dengan konsensus
@@ -98,6 +113,14 @@ type Engine interface {
 	// yang harus dimiliki oleh blok baru.
 	CalcDifficulty(chain ChainHeaderReader, time uint64, parent *types.Header) *big.Int
 
+	// FinalizedHeader mengembalikan header terakhir yang sudah final dan tidak bisa
+	// lagi di-reorg, atau nil jika mesin tidak memiliki finalitas.
+	FinalizedHeader(chain ChainHeaderReader) *types.Header
+
+	// SafeHeader mengembalikan header terakhir yang dianggap aman dari reorg oleh
+	// mesin konsensus, atau nil jika mesin tidak memiliki finalitas.
+	SafeHeader(chain ChainHeaderReader) *types.Header
+
 	// APIs mengembalikan APIs RPC yang disediakan mesin konsensus ini.
 	APIs(chain ChainHeaderReader) []rpc.API
 
@@ -105,10 +128,34 @@ type Engine interface {
 	Close() error
 }
 
+// NoFinality adalah adapter default untuk mesin konsensus tanpa finalitas, misalnya
+// PoW atau PoA. Mesin cukup menyematkan struct ini agar memenuhi bagian finalitas
+// dari Engine, yang selalu melaporkan bahwa tidak ada header final maupun aman.
+type NoFinality struct{}
+
+// FinalizedHeader mengimplementasikan Engine, selalu mengembalikan nil.
+func (NoFinality) FinalizedHeader(chain ChainHeaderReader) *types.Header {
+	return nil
+}
+
+// SafeHeader mengimplementasikan Engine, selalu mengembalikan nil.
+func (NoFinality) SafeHeader(chain ChainHeaderReader) *types.Header {
+	return nil
+}
+
 // PoW adalah mesin konsensus berdasarkan bukti kerja.
 type PoW interface {
 	Engine
 
-	// Hashrate mengembalikan hashrate penambangan saat ini dari mesin konsensus PoW.
+	// Hashrate mengembalikan hashrate penambangan saat ini dari mesin konsensus PoW,
+	// termasuk hashrate yang dilaporkan penambang remote.
 	Hashrate() float64
+
+	// SetThreads mengatur jumlah thread penambangan lokal saat runtime. Nol berarti
+	// memakai semua CPU, negatif berarti penambangan lokal dimatikan.
+	SetThreads(threads int)
+
+	// SubmitHashrate mencatat hashrate yang dilaporkan penambang remote dengan
+	// identitas id agar ikut dihitung di Hashrate.
+	SubmitHashrate(id common.Hash, rate uint64) bool
 }
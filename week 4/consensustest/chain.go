@@ -0,0 +1,327 @@
+package consensustest
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	// errUnknownParent dikembalikan jika header yang dimasukkan tidak memiliki induk
+	// yang dikenal di rantai.
+	errUnknownParent = errors.New("unknown parent")
+
+	// errUnknownHead dikembalikan jika head baru untuk reorg tidak dikenal.
+	errUnknownHead = errors.New("unknown head")
+
+	// errMissingDifficulty dikembalikan jika header yang dimasukkan tidak memiliki
+	// kesulitan, sehingga total kesulitannya tidak bisa dihitung.
+	errMissingDifficulty = errors.New("missing difficulty")
+)
+
+// HeaderChain adalah rantai header di memori yang mengimplementasikan
+// consensus.ChainHeaderReader, consensus.ChainReader dan consensus.ChainReceiptReader,
+// sehingga mesin konsensus bisa diuji tanpa database. Rantai menyimpan semua cabang
+// yang pernah dimasukkan, dan hanya satu di antaranya yang kanonik.
+type HeaderChain struct {
+	config *params.ChainConfig
+
+	headers  map[common.Hash]*types.Header  // Semua header yang dikenal, berdasarkan hash
+	blocks   map[common.Hash]*types.Block   // Blok lengkap yang dimasukkan, untuk verifikasi paman
+	receipts map[common.Hash]types.Receipts // Receipt blok yang dimasukkan
+	tds      map[common.Hash]*big.Int       // Total kesulitan setiap header
+	canon    []common.Hash                  // Hash kanonik berdasarkan nomor blok
+
+	forkChoice consensus.ForkChoice // Aturan pemilihan head, nil berarti hanya perpanjangan head
+
+	lock sync.RWMutex
+}
+
+// NewHeaderChain membuat rantai di memori dengan konfigurasi dan genesis yang
+// diberikan. Genesis langsung menjadi head kanonik.
+func NewHeaderChain(config *params.ChainConfig, genesis *types.Header) *HeaderChain {
+	hash := genesis.Hash()
+	return &HeaderChain{
+		config:   config,
+		headers:  map[common.Hash]*types.Header{hash: genesis},
+		blocks:   make(map[common.Hash]*types.Block),
+		receipts: make(map[common.Hash]types.Receipts),
+		tds:      map[common.Hash]*big.Int{hash: new(big.Int).Set(genesis.Difficulty)},
+		canon:    []common.Hash{hash},
+	}
+}
+
+// SetForkChoice menetapkan aturan fork choice yang dipakai saat header dimasukkan.
+// Tanpa aturan, header hanya menjadi kanonik jika langsung melanjutkan head. Aturan
+// dipanggil selama kunci rantai dipegang, jadi aturan hanya boleh membaca rantai
+// lewat ChainHeaderReader yang diberikan ke ReorgNeeded.
+func (hc *HeaderChain) SetForkChoice(forkChoice consensus.ForkChoice) {
+	hc.lock.Lock()
+	defer hc.lock.Unlock()
+
+	hc.forkChoice = forkChoice
+}
+
+// Config mengimplementasikan consensus.ChainHeaderReader.
+func (hc *HeaderChain) Config() *params.ChainConfig {
+	return hc.config
+}
+
+// CurrentHeader mengimplementasikan consensus.ChainHeaderReader, mengembalikan head
+// kanonik.
+func (hc *HeaderChain) CurrentHeader() *types.Header {
+	hc.lock.RLock()
+	defer hc.lock.RUnlock()
+
+	return (*chainView)(hc).CurrentHeader()
+}
+
+// GetHeader mengimplementasikan consensus.ChainHeaderReader.
+func (hc *HeaderChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	hc.lock.RLock()
+	defer hc.lock.RUnlock()
+
+	return (*chainView)(hc).GetHeader(hash, number)
+}
+
+// GetHeaderByNumber mengimplementasikan consensus.ChainHeaderReader, mengembalikan
+// header kanonik pada nomor tertentu.
+func (hc *HeaderChain) GetHeaderByNumber(number uint64) *types.Header {
+	hc.lock.RLock()
+	defer hc.lock.RUnlock()
+
+	return (*chainView)(hc).GetHeaderByNumber(number)
+}
+
+// GetHeaderByHash mengimplementasikan consensus.ChainHeaderReader.
+func (hc *HeaderChain) GetHeaderByHash(hash common.Hash) *types.Header {
+	hc.lock.RLock()
+	defer hc.lock.RUnlock()
+
+	return (*chainView)(hc).GetHeaderByHash(hash)
+}
+
+// GetTd mengimplementasikan consensus.ChainHeaderReader.
+func (hc *HeaderChain) GetTd(hash common.Hash, number uint64) *big.Int {
+	hc.lock.RLock()
+	defer hc.lock.RUnlock()
+
+	return (*chainView)(hc).GetTd(hash, number)
+}
+
+// GetBlock mengimplementasikan consensus.ChainReader. Hanya blok yang dimasukkan
+// lewat InsertBlock yang memiliki body; header lain dikembalikan tanpa body.
+func (hc *HeaderChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	hc.lock.RLock()
+	defer hc.lock.RUnlock()
+
+	if block := hc.blocks[hash]; block != nil && block.NumberU64() == number {
+		return block
+	}
+	header := hc.headers[hash]
+	if header == nil || header.Number.Uint64() != number {
+		return nil
+	}
+	return types.NewBlockWithHeader(header)
+}
+
+// GetReceiptsByHash mengimplementasikan consensus.ChainReceiptReader.
+func (hc *HeaderChain) GetReceiptsByHash(hash common.Hash) types.Receipts {
+	hc.lock.RLock()
+	defer hc.lock.RUnlock()
+
+	return hc.receipts[hash]
+}
+
+// GetCanonicalHash mengimplementasikan consensus.ChainReceiptReader.
+func (hc *HeaderChain) GetCanonicalHash(number uint64) common.Hash {
+	hc.lock.RLock()
+	defer hc.lock.RUnlock()
+
+	if number >= uint64(len(hc.canon)) {
+		return common.Hash{}
+	}
+	return hc.canon[number]
+}
+
+// Insert memasukkan header ke rantai. Head kanonik hanya berubah jika header
+// langsung melanjutkan head saat ini, atau jika aturan fork choice memilihnya.
+// Header harus berurutan dan induk header pertama harus sudah dikenal.
+func (hc *HeaderChain) Insert(headers ...*types.Header) error {
+	hc.lock.Lock()
+	defer hc.lock.Unlock()
+
+	for _, header := range headers {
+		if err := hc.insert(header); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InsertBlock memasukkan blok lengkap beserta receipt-nya, sama seperti Insert
+// untuk header blok tersebut. Body dan receipt hanya disimpan jika header berhasil
+// dimasukkan.
+func (hc *HeaderChain) InsertBlock(block *types.Block, receipts types.Receipts) error {
+	hc.lock.Lock()
+	defer hc.lock.Unlock()
+
+	if err := hc.insert(block.Header()); err != nil {
+		return err
+	}
+	hc.blocks[block.Hash()] = block
+	hc.receipts[block.Hash()] = receipts
+	return nil
+}
+
+// insert memasukkan satu header, lalu menanyakan aturan fork choice (jika ada)
+// apakah header harus menjadi head kanonik. Pemanggil harus memegang kunci tulis,
+// sehingga keputusan dan reorg dilakukan atas head yang sama. Aturan fork choice
+// membaca rantai lewat chainView agar tidak mengambil kunci lagi.
+func (hc *HeaderChain) insert(header *types.Header) error {
+	if header.Difficulty == nil {
+		return errMissingDifficulty
+	}
+	parent := hc.headers[header.ParentHash]
+	if parent == nil || parent.Number.Uint64()+1 != header.Number.Uint64() {
+		return errUnknownParent
+	}
+	hash := header.Hash()
+	hc.headers[hash] = header
+	hc.tds[hash] = new(big.Int).Add(hc.tds[header.ParentHash], header.Difficulty)
+
+	if hc.forkChoice == nil {
+		if header.ParentHash == hc.canon[len(hc.canon)-1] {
+			hc.canon = append(hc.canon, hash)
+		}
+		return nil
+	}
+	view := (*chainView)(hc)
+	reorg, err := hc.forkChoice.ReorgNeeded(view, view.CurrentHeader(), header)
+	if err != nil || !reorg {
+		return err
+	}
+	return hc.reorg(hash)
+}
+
+// Extend membangun n header baru di atas head kanonik. Tanpa aturan fork choice,
+// header baru selalu menjadi kanonik. Fungsi gen, jika ada, dipanggil untuk setiap
+// header sebelum dimasukkan sehingga pemanggil bisa mengubah field atau menyegelnya
+// dengan mesin konsensus.
+func (hc *HeaderChain) Extend(n int, gen func(i int, header *types.Header)) ([]*types.Header, error) {
+	return hc.Fork(hc.CurrentHeader().Hash(), n, gen)
+}
+
+// Fork membangun n header baru di atas header parent. Head kanonik berubah sesuai
+// aturan yang sama dengan Insert; gunakan Reorg untuk berpindah ke cabang yang
+// dihasilkan secara paksa. Fork gagal jika gen mengubah header sehingga induknya
+// tidak lagi dikenal.
+func (hc *HeaderChain) Fork(parent common.Hash, n int, gen func(i int, header *types.Header)) ([]*types.Header, error) {
+	prev := hc.GetHeaderByHash(parent)
+	if prev == nil {
+		return nil, errUnknownParent
+	}
+	headers := make([]*types.Header, 0, n)
+	for i := 0; i < n; i++ {
+		header := &types.Header{
+			ParentHash: prev.Hash(),
+			UncleHash:  types.EmptyUncleHash,
+			Number:     new(big.Int).Add(prev.Number, common.Big1),
+			Difficulty: new(big.Int).Set(common.Big1),
+			GasLimit:   prev.GasLimit,
+			Time:       prev.Time + 1,
+		}
+		if hc.config.IsLondon(header.Number) {
+			header.BaseFee = misc.CalcBaseFee(hc.config, prev)
+		}
+		// gen boleh membaca rantai (misalnya untuk menyegel), jadi jangan pegang kunci
+		if gen != nil {
+			gen(i, header)
+		}
+		if err := hc.Insert(header); err != nil {
+			return headers, err
+		}
+		headers = append(headers, header)
+		prev = header
+	}
+	return headers, nil
+}
+
+// Reorg menjadikan cabang yang berakhir di head sebagai rantai kanonik, tanpa
+// memandang total kesulitannya.
+func (hc *HeaderChain) Reorg(head common.Hash) error {
+	hc.lock.Lock()
+	defer hc.lock.Unlock()
+
+	return hc.reorg(head)
+}
+
+// reorg menjadikan cabang yang berakhir di head sebagai rantai kanonik. Pemanggil
+// harus memegang kunci tulis.
+func (hc *HeaderChain) reorg(head common.Hash) error {
+	header := hc.headers[head]
+	if header == nil {
+		return errUnknownHead
+	}
+	canon := make([]common.Hash, header.Number.Uint64()+1)
+	for {
+		number := header.Number.Uint64()
+		canon[number] = header.Hash()
+		if number == 0 {
+			break
+		}
+		header = hc.headers[header.ParentHash]
+	}
+	hc.canon = canon
+	return nil
+}
+
+// chainView membaca HeaderChain tanpa mengambil kunci. Tipe ini diberikan ke aturan
+// fork choice selama kunci tulis dipegang oleh insert.
+type chainView HeaderChain
+
+// Config mengimplementasikan consensus.ChainHeaderReader.
+func (v *chainView) Config() *params.ChainConfig {
+	return v.config
+}
+
+// CurrentHeader mengimplementasikan consensus.ChainHeaderReader.
+func (v *chainView) CurrentHeader() *types.Header {
+	return v.headers[v.canon[len(v.canon)-1]]
+}
+
+// GetHeader mengimplementasikan consensus.ChainHeaderReader.
+func (v *chainView) GetHeader(hash common.Hash, number uint64) *types.Header {
+	header := v.headers[hash]
+	if header == nil || header.Number.Uint64() != number {
+		return nil
+	}
+	return header
+}
+
+// GetHeaderByNumber mengimplementasikan consensus.ChainHeaderReader.
+func (v *chainView) GetHeaderByNumber(number uint64) *types.Header {
+	if number >= uint64(len(v.canon)) {
+		return nil
+	}
+	return v.headers[v.canon[number]]
+}
+
+// GetHeaderByHash mengimplementasikan consensus.ChainHeaderReader.
+func (v *chainView) GetHeaderByHash(hash common.Hash) *types.Header {
+	return v.headers[hash]
+}
+
+// GetTd mengimplementasikan consensus.ChainHeaderReader.
+func (v *chainView) GetTd(hash common.Hash, number uint64) *big.Int {
+	if v.GetHeader(hash, number) == nil {
+		return nil
+	}
+	return v.tds[hash]
+}
@@ -0,0 +1,282 @@
+package consensustest
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Pastikan rantai header memenuhi semua antarmuka pembaca rantai.
+var (
+	_ consensus.ChainHeaderReader  = (*HeaderChain)(nil)
+	_ consensus.ChainReader        = (*HeaderChain)(nil)
+	_ consensus.ChainReceiptReader = (*HeaderChain)(nil)
+	_ consensus.ChainHeaderReader  = (*chainView)(nil)
+)
+
+// Menguji bahwa memperpanjang rantai memperbarui pencarian kanonik dan total kesulitan.
+func TestHeaderChainExtend(t *testing.T) {
+	chain, headers := newTestChain(t, 10)
+
+	if head := chain.CurrentHeader(); head.Hash() != headers[9].Hash() {
+		t.Fatalf("head mismatch: have %d, want %d", head.Number, headers[9].Number)
+	}
+	for i, header := range headers {
+		number := header.Number.Uint64()
+		if have := chain.GetHeaderByNumber(number); have == nil || have.Hash() != header.Hash() {
+			t.Errorf("header %d: canonical lookup mismatch", i)
+		}
+		if have := chain.GetHeader(header.Hash(), number+1); have != nil {
+			t.Errorf("header %d: lookup with wrong number succeeded", i)
+		}
+		if td := chain.GetTd(header.Hash(), number); td == nil || td.Uint64() != number+1 {
+			t.Errorf("header %d: td mismatch: have %v, want %d", i, td, number+1)
+		}
+	}
+	if chain.GetHeaderByNumber(11) != nil {
+		t.Errorf("lookup beyond head succeeded")
+	}
+}
+
+// Menguji bahwa cabang samping tidak memindahkan head sebelum reorg, dan bahwa reorg
+// menulis ulang pencarian kanonik mulai dari titik percabangan.
+func TestHeaderChainForkReorg(t *testing.T) {
+	chain, headers := newTestChain(t, 10)
+
+	side, err := chain.Fork(headers[4].Hash(), 8, func(i int, header *types.Header) {
+		header.Extra = []byte("side")
+	})
+	if err != nil {
+		t.Fatalf("failed to fork chain: %v", err)
+	}
+	if head := chain.CurrentHeader(); head.Hash() != headers[9].Hash() {
+		t.Fatalf("fork moved the head to %d", head.Number)
+	}
+	if err := chain.Reorg(side[7].Hash()); err != nil {
+		t.Fatalf("failed to reorg: %v", err)
+	}
+	if head := chain.CurrentHeader(); head.Hash() != side[7].Hash() {
+		t.Fatalf("head mismatch after reorg: have %d, want %d", head.Number, side[7].Number)
+	}
+	if chain.GetCanonicalHash(5) != headers[4].Hash() {
+		t.Errorf("canonical hash below fork point changed")
+	}
+	for i, header := range side {
+		if chain.GetCanonicalHash(header.Number.Uint64()) != header.Hash() {
+			t.Errorf("side header %d: not canonical after reorg", i)
+		}
+	}
+	if err := chain.Reorg(headers[9].Hash()); err != nil {
+		t.Fatalf("failed to reorg back: %v", err)
+	}
+	if chain.GetHeaderByNumber(11) != nil {
+		t.Errorf("canonical chain not shortened by reorg")
+	}
+}
+
+// Menguji bahwa penyisipan tidak valid ditolak tanpa meninggalkan sisa apa pun.
+func TestHeaderChainInsertInvalid(t *testing.T) {
+	chain, headers := newTestChain(t, 3)
+
+	if _, err := chain.Extend(1, func(i int, header *types.Header) { header.Number = big.NewInt(10) }); err != errUnknownParent {
+		t.Fatalf("error mismatch for wrong number: have %v, want %v", err, errUnknownParent)
+	}
+	if _, err := chain.Fork(types.EmptyUncleHash, 1, nil); err != errUnknownParent {
+		t.Fatalf("error mismatch for unknown parent: have %v, want %v", err, errUnknownParent)
+	}
+	orphan := types.NewBlockWithHeader(&types.Header{
+		ParentHash: types.EmptyUncleHash,
+		Number:     big.NewInt(4),
+		Difficulty: big.NewInt(1),
+	})
+	if err := chain.InsertBlock(orphan, types.Receipts{}); err != errUnknownParent {
+		t.Fatalf("error mismatch for orphan block: have %v, want %v", err, errUnknownParent)
+	}
+	if _, err := chain.Extend(1, func(i int, header *types.Header) { header.Difficulty = nil }); err != errMissingDifficulty {
+		t.Fatalf("error mismatch for missing difficulty: have %v, want %v", err, errMissingDifficulty)
+	}
+	if chain.GetBlock(orphan.Hash(), 4) != nil || chain.GetReceiptsByHash(orphan.Hash()) != nil {
+		t.Fatalf("orphan block stored")
+	}
+	if err := chain.Reorg(orphan.Hash()); err != errUnknownHead {
+		t.Fatalf("error mismatch for unknown head: have %v, want %v", err, errUnknownHead)
+	}
+	if head := chain.CurrentHeader(); head.Hash() != headers[2].Hash() {
+		t.Fatalf("head changed by invalid inserts")
+	}
+}
+
+// finalEngine adalah mesin palsu yang menganggap header kanonik dengan nomor
+// tertentu sebagai final.
+type finalEngine struct {
+	*FakeEngine
+	number uint64
+}
+
+func (f *finalEngine) FinalizedHeader(chain consensus.ChainHeaderReader) *types.Header {
+	return chain.GetHeaderByNumber(f.number)
+}
+
+// Menguji bahwa rantai memilih head dengan aturan fork choice yang dipasang. Jika
+// anak titik percabangan sudah dipangkas dari pohon GHOST, karena terlalu dalam
+// atau sudah final, percabangan diputuskan oleh total kesulitan.
+func TestHeaderChainForkChoice(t *testing.T) {
+	tests := []struct {
+		name   string
+		choice func() consensus.ForkChoice
+		side   bool // Apakah cabang dengan paman terbanyak harus menang
+	}{
+		{"heaviest", func() consensus.ForkChoice { return consensus.HeaviestTD{} }, false},
+		{"longest", func() consensus.ForkChoice { return consensus.LongestChain{} }, false},
+		{"ghost", func() consensus.ForkChoice { return consensus.NewGHOST(nil, 0) }, true},
+		{"ghost pruned by depth", func() consensus.ForkChoice { return consensus.NewGHOST(nil, 1) }, false},
+		{"ghost pruned by finality", func() consensus.ForkChoice { return consensus.NewGHOST(&finalEngine{NewFakeEngine(), 2}, 0) }, false},
+	}
+	for _, tt := range tests {
+		chain, _ := newTestChain(t, 0)
+		chain.SetForkChoice(tt.choice())
+		genesis := chain.CurrentHeader()
+
+		// Cabang utama: tiga blok berurutan (bobot subtree 3)
+		main, err := chain.Extend(3, nil)
+		if err != nil {
+			t.Fatalf("%s: failed to extend: %v", tt.name, err)
+		}
+		// Cabang samping: satu blok dengan tiga anak bersaudara (bobot subtree 4)
+		side, err := chain.Fork(genesis.Hash(), 1, func(i int, header *types.Header) { header.Extra = []byte("side") })
+		if err != nil {
+			t.Fatalf("%s: failed to fork: %v", tt.name, err)
+		}
+		for j := 0; j < 3; j++ {
+			if _, err := chain.Fork(side[0].Hash(), 1, func(i int, header *types.Header) { header.Extra = []byte{byte(j)} }); err != nil {
+				t.Fatalf("%s: failed to add sibling: %v", tt.name, err)
+			}
+		}
+		head := chain.CurrentHeader()
+		if onSide := head.ParentHash == side[0].Hash(); onSide != tt.side {
+			t.Errorf("%s: head mismatch: have %d on side branch %v, main head %d", tt.name, head.Number, onSide, main[2].Number)
+		}
+	}
+}
+
+// Menguji bahwa fork choice eksternal hanya memindahkan head ke header yang ditetapkan.
+func TestHeaderChainExternal(t *testing.T) {
+	chain, _ := newTestChain(t, 0)
+	external := consensus.NewExternal()
+	chain.SetForkChoice(external)
+
+	headers, err := chain.Extend(3, nil)
+	if err != nil {
+		t.Fatalf("failed to extend: %v", err)
+	}
+	if head := chain.CurrentHeader(); head.Number.Uint64() != 0 {
+		t.Fatalf("head moved without external decision: %d", head.Number)
+	}
+	external.SetHead(headers[1].Hash())
+	if err := chain.Reorg(headers[1].Hash()); err != nil {
+		t.Fatalf("failed to reorg: %v", err)
+	}
+	more, err := chain.Extend(1, nil)
+	if err != nil {
+		t.Fatalf("failed to extend: %v", err)
+	}
+	if head := chain.CurrentHeader(); head.Hash() != headers[1].Hash() {
+		t.Fatalf("head mismatch: have %d, want %d", head.Number, headers[1].Number)
+	}
+	external.SetHead(more[0].Hash())
+	if err := chain.Reorg(more[0].Hash()); err != nil {
+		t.Fatalf("failed to reorg: %v", err)
+	}
+	if head := chain.CurrentHeader(); head.Hash() != more[0].Hash() {
+		t.Fatalf("head mismatch: have %d, want %d", head.Number, more[0].Number)
+	}
+}
+
+// Menguji bahwa penyisipan bersamaan dengan aturan fork choice tetap berakhir di
+// cabang terberat.
+func TestHeaderChainConcurrentInsert(t *testing.T) {
+	chain, _ := newTestChain(t, 0)
+	chain.SetForkChoice(consensus.HeaviestTD{})
+	genesis := chain.CurrentHeader()
+
+	var (
+		wg    sync.WaitGroup
+		heads = make([]*types.Header, 8)
+	)
+	for i := range heads {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			headers, err := chain.Fork(genesis.Hash(), 10+i, func(j int, header *types.Header) {
+				header.Extra = []byte{byte(i)}
+			})
+			if err != nil {
+				t.Errorf("branch %d: failed to fork: %v", i, err)
+				return
+			}
+			heads[i] = headers[len(headers)-1]
+		}(i)
+	}
+	wg.Wait()
+
+	if head := chain.CurrentHeader(); head.Hash() != heads[7].Hash() {
+		t.Fatalf("head mismatch: have %d, want %d", head.Number, heads[7].Number)
+	}
+}
+
+// feeEngine adalah mesin palsu yang di Finalize membaca receipt blok induk kanonik
+// lewat consensus.ChainReceiptReader dan mengkreditkan gas yang dipakainya ke coinbase.
+type feeEngine struct {
+	*FakeEngine
+}
+
+func (f *feeEngine) Finalize(chain consensus.ChainHeaderReader, header *types.Header, statedb *state.StateDB, txs []*types.Transaction, uncles []*types.Header) {
+	reader, ok := chain.(consensus.ChainReceiptReader)
+	if !ok {
+		return
+	}
+	parent := reader.GetCanonicalHash(header.Number.Uint64() - 1)
+	for _, receipt := range reader.GetReceiptsByHash(parent) {
+		statedb.AddBalance(header.Coinbase, new(big.Int).SetUint64(receipt.GasUsed))
+	}
+}
+
+// Menguji bahwa mesin bisa membaca receipt dan hash kanonik dari HeaderChain saat
+// Finalize, dan hanya untuk blok yang benar-benar dimasukkan beserta receipt-nya.
+func TestHeaderChainReceipts(t *testing.T) {
+	chain, _ := newTestChain(t, 0)
+	genesis := chain.CurrentHeader()
+
+	block := types.NewBlockWithHeader(&types.Header{
+		ParentHash: genesis.Hash(),
+		Number:     big.NewInt(1),
+		Difficulty: big.NewInt(1),
+	})
+	receipts := types.Receipts{{GasUsed: 21000}, {GasUsed: 50000}}
+	if err := chain.InsertBlock(block, receipts); err != nil {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+	if have := chain.GetCanonicalHash(1); have != block.Hash() {
+		t.Fatalf("canonical hash mismatch: have %x, want %x", have, block.Hash())
+	}
+	engine := &feeEngine{FakeEngine: NewFakeEngine()}
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+
+	coinbase := common.Address{1}
+	engine.Finalize(chain, &types.Header{Number: big.NewInt(2), Coinbase: coinbase}, statedb, nil, nil)
+	if have := statedb.GetBalance(coinbase); have.Uint64() != 71000 {
+		t.Errorf("fee mismatch: have %v, want %v", have, 71000)
+	}
+	// Blok tanpa receipt tersimpan tidak menghasilkan apa-apa
+	other := common.Address{2}
+	engine.Finalize(chain, &types.Header{Number: big.NewInt(1), Coinbase: other}, statedb, nil, nil)
+	if have := statedb.GetBalance(other); have.Sign() != 0 {
+		t.Errorf("fee without receipts mismatch: have %v, want 0", have)
+	}
+}
@@ -0,0 +1,253 @@
+// Paket consensustest menyediakan mesin konsensus palsu untuk pengujian unit, agar
+// pengujian core dan miner tidak perlu menjalankan PoW sungguhan.
+package consensustest
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+	"golang.org/x/crypto/sha3"
+)
+
+// fakeDifficulty adalah kesulitan semua blok palsu. Nilainya bukan nol agar blok
+// tidak dianggap blok proof-of-stake oleh mesin beacon.
+var fakeDifficulty = big.NewInt(1)
+
+// ErrFakeFailure dikembalikan oleh FakeFailer untuk blok yang dikonfigurasi gagal.
+var ErrFakeFailure = errors.New("fake failure")
+
+// FakeEngine adalah mesin konsensus yang menerima semua header, paman dan segel
+// sebagai valid. Mesin ini tetap menghitung state root dan merakit blok seperti
+// mesin sungguhan, jadi blok yang dihasilkannya bisa diimpor ke rantai.
+type FakeEngine struct {
+	consensus.NoFinality // Mesin palsu tidak memiliki finalitas
+}
+
+// NewFakeEngine membuat mesin konsensus palsu yang selalu valid.
+func NewFakeEngine() *FakeEngine {
+	return &FakeEngine{}
+}
+
+// Author mengimplementasikan consensus.Engine, mengembalikan coinbase header
+// sebagai pembuat blok.
+func (f *FakeEngine) Author(header *types.Header) (common.Address, error) {
+	return header.Coinbase, nil
+}
+
+// VerifyHeader mengimplementasikan consensus.Engine, selalu menerima header.
+func (f *FakeEngine) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, seal bool) error {
+	return nil
+}
+
+// VerifyHeaders mengimplementasikan consensus.Engine, menerima semua header dalam
+// satu batch.
+func (f *FakeEngine) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	return consensus.VerifyParallel(len(headers), 0, func(int) error { return nil })
+}
+
+// VerifyUncles mengimplementasikan consensus.Engine, selalu menerima paman.
+func (f *FakeEngine) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
+	return nil
+}
+
+// Prepare mengimplementasikan consensus.Engine, mengisi kesulitan header dengan
+// nilai konstan.
+func (f *FakeEngine) Prepare(chain consensus.ChainHeaderReader, header *types.Header) error {
+	header.Difficulty = new(big.Int).Set(fakeDifficulty)
+	return nil
+}
+
+// Finalize mengimplementasikan consensus.Engine, menghitung state root tanpa
+// memberikan block reward apa pun.
+func (f *FakeEngine) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header) {
+	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
+}
+
+// FinalizeAndAssemble mengimplementasikan consensus.Engine, menetapkan state akhir
+// dan merakit blok.
+func (f *FakeEngine) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
+	f.Finalize(chain, header, state, txs, uncles)
+	return types.NewBlock(header, txs, uncles, receipts, trie.NewStackTrie(nil)), nil
+}
+
+// Seal mengimplementasikan consensus.Engine, langsung mengirim blok apa adanya ke
+// saluran hasil tanpa penambangan. Blok tidak dikirim jika stop sudah ditutup.
+func (f *FakeEngine) Seal(chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
+	go func() {
+		// select memilih secara acak jika kedua saluran siap, jadi periksa stop dulu
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		select {
+		case results <- block:
+		case <-stop:
+		}
+	}()
+	return nil
+}
+
+// SealHash mengembalikan hash dari sebuah blok sebelum disegel, yaitu hash header
+// tanpa nonce dan mix digest.
+func (f *FakeEngine) SealHash(header *types.Header) (hash common.Hash) {
+	hasher := sha3.NewLegacyKeccak256()
+
+	enc := []interface{}{
+		header.ParentHash,
+		header.UncleHash,
+		header.Coinbase,
+		header.Root,
+		header.TxHash,
+		header.ReceiptHash,
+		header.Bloom,
+		header.Difficulty,
+		header.Number,
+		header.GasLimit,
+		header.GasUsed,
+		header.Time,
+		header.Extra,
+	}
+	if header.BaseFee != nil {
+		enc = append(enc, header.BaseFee)
+	}
+	rlp.Encode(hasher, enc)
+	hasher.Sum(hash[:0])
+	return hash
+}
+
+// CalcDifficulty mengimplementasikan consensus.Engine, mengembalikan kesulitan
+// konstan untuk semua blok.
+func (f *FakeEngine) CalcDifficulty(chain consensus.ChainHeaderReader, time uint64, parent *types.Header) *big.Int {
+	return new(big.Int).Set(fakeDifficulty)
+}
+
+// APIs mengimplementasikan consensus.Engine. Mesin palsu tidak punya API RPC.
+func (f *FakeEngine) APIs(chain consensus.ChainHeaderReader) []rpc.API {
+	return nil
+}
+
+// Close mengimplementasikan consensus.Engine. Mesin palsu tidak punya thread latar
+// belakang sehingga tidak ada yang perlu dihentikan.
+func (f *FakeEngine) Close() error {
+	return nil
+}
+
+// FakeDelayer adalah FakeEngine yang menunda setiap verifikasi header selama durasi
+// tertentu, untuk menguji kode yang sensitif terhadap latensi verifikasi.
+type FakeDelayer struct {
+	*FakeEngine
+	delay time.Duration
+}
+
+// NewFakeDelayer membuat mesin konsensus palsu yang menerima semua header setelah
+// menunggu selama delay.
+func NewFakeDelayer(delay time.Duration) *FakeDelayer {
+	return &FakeDelayer{FakeEngine: NewFakeEngine(), delay: delay}
+}
+
+// VerifyHeader mengimplementasikan consensus.Engine, menerima header setelah jeda.
+func (f *FakeDelayer) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, seal bool) error {
+	time.Sleep(f.delay)
+	return nil
+}
+
+// VerifyHeaders mengimplementasikan consensus.Engine, menerima setiap header dalam
+// batch setelah jeda masing-masing. Jeda berjalan paralel di pekerja verifikasi.
+func (f *FakeDelayer) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	return consensus.VerifyParallel(len(headers), 0, func(index int) error {
+		return f.VerifyHeader(chain, headers[index], false)
+	})
+}
+
+// FakeFailer adalah FakeEngine yang menolak satu nomor blok tertentu, untuk menguji
+// penanganan kegagalan verifikasi di tengah rantai.
+type FakeFailer struct {
+	*FakeEngine
+	fail uint64
+}
+
+// NewFakeFailer membuat mesin konsensus palsu yang menerima semua header kecuali
+// header dengan nomor fail.
+func NewFakeFailer(fail uint64) *FakeFailer {
+	return &FakeFailer{FakeEngine: NewFakeEngine(), fail: fail}
+}
+
+// VerifyHeader mengimplementasikan consensus.Engine, menolak header dengan nomor
+// blok yang dikonfigurasi.
+func (f *FakeFailer) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, seal bool) error {
+	if header.Number.Uint64() == f.fail {
+		return ErrFakeFailure
+	}
+	return nil
+}
+
+// VerifyHeaders mengimplementasikan consensus.Engine, menolak header dengan nomor
+// blok yang dikonfigurasi di dalam batch.
+func (f *FakeFailer) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	return consensus.VerifyParallel(len(headers), 0, func(index int) error {
+		return f.VerifyHeader(chain, headers[index], false)
+	})
+}
+
+// FakePoW adalah FakeEngine yang juga mengimplementasikan consensus.PoW, untuk
+// menguji pembungkus mesin yang meneruskan metode PoW.
+type FakePoW struct {
+	*FakeEngine
+
+	threads int
+	rates   map[common.Hash]uint64
+	lock    sync.Mutex
+}
+
+// NewFakePoW membuat mesin PoW palsu tanpa thread dan tanpa hashrate.
+func NewFakePoW() *FakePoW {
+	return &FakePoW{FakeEngine: NewFakeEngine(), rates: make(map[common.Hash]uint64)}
+}
+
+// Threads mengembalikan jumlah thread terakhir yang diatur lewat SetThreads.
+func (f *FakePoW) Threads() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.threads
+}
+
+// SetThreads mengimplementasikan consensus.PoW, mencatat jumlah thread.
+func (f *FakePoW) SetThreads(threads int) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.threads = threads
+}
+
+// Hashrate mengimplementasikan consensus.PoW, menjumlahkan semua hashrate yang
+// dilaporkan.
+func (f *FakePoW) Hashrate() float64 {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var total uint64
+	for _, rate := range f.rates {
+		total += rate
+	}
+	return float64(total)
+}
+
+// SubmitHashrate mengimplementasikan consensus.PoW, mencatat hashrate per id.
+func (f *FakePoW) SubmitHashrate(id common.Hash, rate uint64) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.rates[id] = rate
+	return true
+}
@@ -0,0 +1,169 @@
+package consensustest
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// newTestChain membuat rantai di memori dengan n header di atas genesis.
+func newTestChain(t *testing.T, n int) (*HeaderChain, []*types.Header) {
+	t.Helper()
+
+	genesis := &types.Header{
+		Number:     big.NewInt(0),
+		Difficulty: big.NewInt(1),
+		GasLimit:   params.GenesisGasLimit,
+		BaseFee:    big.NewInt(params.InitialBaseFee),
+	}
+	chain := NewHeaderChain(params.AllEthashProtocolChanges, genesis)
+	headers, err := chain.Extend(n, nil)
+	if err != nil {
+		t.Fatalf("failed to extend chain: %v", err)
+	}
+	return chain, headers
+}
+
+// collect membaca hasil verifikasi sampai n hasil diterima atau timeout tercapai.
+func collect(results <-chan error, n int, timeout time.Duration) []error {
+	var errs []error
+	for len(errs) < n {
+		select {
+		case err := <-results:
+			errs = append(errs, err)
+		case <-time.After(timeout):
+			return errs
+		}
+	}
+	return errs
+}
+
+// Pastikan semua mesin palsu memenuhi antarmuka mesin konsensus.
+var (
+	_ consensus.Engine = (*FakeEngine)(nil)
+	_ consensus.Engine = (*FakeDelayer)(nil)
+	_ consensus.Engine = (*FakeFailer)(nil)
+	_ consensus.PoW    = (*FakePoW)(nil)
+)
+
+// Menguji bahwa verifikasi batch kosong tidak mengirim hasil dan tetap bisa dibatalkan.
+func TestVerifyHeadersEmpty(t *testing.T) {
+	chain, _ := newTestChain(t, 0)
+
+	for name, engine := range map[string]consensus.Engine{
+		"engine":  NewFakeEngine(),
+		"delayer": NewFakeDelayer(time.Millisecond),
+		"failer":  NewFakeFailer(0),
+	} {
+		abort, results := engine.VerifyHeaders(chain, nil, nil)
+		if errs := collect(results, 1, 50*time.Millisecond); len(errs) != 0 {
+			t.Errorf("%s: results mismatch: have %d, want 0", name, len(errs))
+		}
+		close(abort)
+	}
+}
+
+// Menguji bahwa mesin palsu menerima setiap header di batch, sesuai urutan.
+func TestVerifyHeadersAccept(t *testing.T) {
+	chain, headers := newTestChain(t, 64)
+
+	_, results := NewFakeEngine().VerifyHeaders(chain, headers, make([]bool, len(headers)))
+	errs := collect(results, len(headers), time.Second)
+	if len(errs) != len(headers) {
+		t.Fatalf("results mismatch: have %d, want %d", len(errs), len(headers))
+	}
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("header %d: verification failed: %v", i, err)
+		}
+	}
+}
+
+// Menguji bahwa FakeFailer hanya menolak blok yang dikonfigurasi, baik sendiri
+// maupun di dalam batch.
+func TestFakeFailer(t *testing.T) {
+	chain, headers := newTestChain(t, 16)
+
+	engine := NewFakeFailer(7)
+	for _, header := range headers {
+		err := engine.VerifyHeader(chain, header, true)
+		if number := header.Number.Uint64(); number == 7 {
+			if !errors.Is(err, ErrFakeFailure) {
+				t.Errorf("header %d: error mismatch: have %v, want %v", number, err, ErrFakeFailure)
+			}
+		} else if err != nil {
+			t.Errorf("header %d: verification failed: %v", number, err)
+		}
+	}
+	_, results := engine.VerifyHeaders(chain, headers, make([]bool, len(headers)))
+	errs := collect(results, len(headers), time.Second)
+	if len(errs) != len(headers) {
+		t.Fatalf("results mismatch: have %d, want %d", len(errs), len(headers))
+	}
+	for i, err := range errs {
+		if want := headers[i].Number.Uint64() == 7; (err != nil) != want {
+			t.Errorf("header %d: failure mismatch: have %v, want failure %v", i, err, want)
+		}
+	}
+}
+
+// Menguji bahwa membatalkan batch yang tertunda menghentikan verifikasi sebelum
+// semua header dikirim.
+func TestVerifyHeadersAbort(t *testing.T) {
+	chain, headers := newTestChain(t, 256)
+
+	abort, results := NewFakeDelayer(10*time.Millisecond).VerifyHeaders(chain, headers, make([]bool, len(headers)))
+	close(abort)
+
+	if errs := collect(results, len(headers), 200*time.Millisecond); len(errs) == len(headers) {
+		t.Fatalf("all %d headers verified despite abort", len(errs))
+	}
+}
+
+// Menguji bahwa FakeDelayer menunggu sebelum menerima header.
+func TestFakeDelayer(t *testing.T) {
+	chain, headers := newTestChain(t, 1)
+
+	start := time.Now()
+	if err := NewFakeDelayer(20*time.Millisecond).VerifyHeader(chain, headers[0], true); err != nil {
+		t.Fatalf("verification failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Fatalf("verification returned too early: %v", elapsed)
+	}
+}
+
+// Menguji bahwa penyegelan dengan mesin palsu mengembalikan blok apa adanya, dan
+// bahwa menghentikan penyegelan membuang hasilnya.
+func TestFakeSeal(t *testing.T) {
+	chain, headers := newTestChain(t, 1)
+	block := types.NewBlockWithHeader(headers[0])
+
+	results := make(chan *types.Block)
+	if err := NewFakeEngine().Seal(chain, block, results, nil); err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	select {
+	case sealed := <-results:
+		if sealed.Hash() != block.Hash() {
+			t.Fatalf("sealed block mismatch: have %x, want %x", sealed.Hash(), block.Hash())
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("sealing result timeout")
+	}
+	stop := make(chan struct{})
+	close(stop)
+	if err := NewFakeEngine().Seal(chain, block, results, stop); err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	select {
+	case <-results:
+		t.Fatalf("sealing result delivered after stop")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
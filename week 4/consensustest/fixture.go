@@ -0,0 +1,221 @@
+package consensustest
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// errUnknownAccount dikembalikan jika tanda tangan diminta untuk alamat yang tidak
+// ada di kumpulan akun.
+var errUnknownAccount = errors.New("unknown test account")
+
+// Accounts adalah kumpulan akun pengujian beserta kunci pribadinya, misalnya
+// validator, delegasi atau anggota cluster sebuah mesin konsensus.
+type Accounts struct {
+	Keys  map[common.Address]*ecdsa.PrivateKey
+	Addrs []common.Address // Alamat akun, diurutkan
+}
+
+// NewAccounts membuat n akun dengan kunci acak.
+func NewAccounts(n int) *Accounts {
+	accs := &Accounts{Keys: make(map[common.Address]*ecdsa.PrivateKey)}
+	for i := 0; i < n; i++ {
+		key, _ := crypto.GenerateKey()
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		accs.Keys[addr] = key
+		accs.Addrs = append(accs.Addrs, addr)
+	}
+	sort.Slice(accs.Addrs, func(i, j int) bool { return accs.Addrs[i].Hex() < accs.Addrs[j].Hex() })
+	return accs
+}
+
+// Sign menandatangani hash dengan kunci akun yang diberikan.
+func (a *Accounts) Sign(signer common.Address, hash common.Hash) ([]byte, error) {
+	key, ok := a.Keys[signer]
+	if !ok {
+		return nil, errUnknownAccount
+	}
+	return crypto.Sign(hash.Bytes(), key)
+}
+
+// SignFn mengembalikan fungsi penanda tangan untuk akun yang diberikan, dengan
+// bentuk yang sama seperti SignerFn milik mesin konsensus berpenanda tangan.
+func (a *Accounts) SignFn(signer common.Address) func(accounts.Account, string, []byte) ([]byte, error) {
+	return func(account accounts.Account, mimeType string, message []byte) ([]byte, error) {
+		return a.Sign(signer, crypto.Keccak256Hash(message))
+	}
+}
+
+// Other mengembalikan akun mana pun selain yang diberikan.
+func (a *Accounts) Other(signer common.Address) common.Address {
+	for _, addr := range a.Addrs {
+		if addr != signer {
+			return addr
+		}
+	}
+	return signer
+}
+
+// Genesis membuat header genesis untuk mesin berpenanda tangan, dengan waktu jauh di
+// masa lalu agar blok pengujian bisa disegel tanpa menunggu jadwal.
+func Genesis() *types.Header {
+	return &types.Header{
+		Number:     big.NewInt(0),
+		Time:       uint64(time.Now().Unix()) - 100000,
+		Difficulty: big.NewInt(1),
+		GasLimit:   params.GenesisGasLimit,
+		BaseFee:    big.NewInt(params.InitialBaseFee),
+		UncleHash:  types.EmptyUncleHash,
+	}
+}
+
+// CommitState menjalankan fill di atas state kosong, menyimpannya ke database dan
+// mengembalikan root state hasilnya.
+func CommitState(t testing.TB, db ethdb.Database, fill func(statedb *state.StateDB)) common.Hash {
+	t.Helper()
+
+	sdb := state.NewDatabase(db)
+	statedb, _ := state.New(common.Hash{}, sdb, nil)
+	fill(statedb)
+
+	root, err := statedb.Commit(false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	if err := sdb.TrieDB().Commit(root, false, nil); err != nil {
+		t.Fatalf("failed to flush state: %v", err)
+	}
+	return root
+}
+
+// Extend menambahkan n blok ke rantai yang disiapkan oleh engine. Untuk setiap blok,
+// pick memilih penyegel dan mengotorisasinya di engine sebelum Prepare. Karena
+// Prepare memajukan waktu ke sekarang, seal mengembalikan waktu header ke jadwal di
+// masa lalu lalu menyegelnya atas nama penyegel tersebut.
+func Extend(t testing.TB, chain *HeaderChain, engine consensus.Engine, n int, pick func(header, parent *types.Header) common.Address, seal func(header, parent *types.Header, signer common.Address)) []*types.Header {
+	t.Helper()
+
+	headers, err := chain.Extend(n, func(i int, header *types.Header) {
+		parent := chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+		signer := pick(header, parent)
+		if err := engine.Prepare(chain, header); err != nil {
+			t.Fatalf("block %d: failed to prepare: %v", header.Number, err)
+		}
+		seal(header, parent, signer)
+	})
+	if err != nil {
+		t.Fatalf("failed to extend chain: %v", err)
+	}
+	return headers
+}
+
+// VerifyChain memverifikasi header satu per satu dengan engine, lalu dalam satu
+// batch dengan batch, dan melaporkan setiap header yang ditolak.
+func VerifyChain(t testing.TB, chain consensus.ChainHeaderReader, headers []*types.Header, engine, batch consensus.Engine) {
+	t.Helper()
+
+	for i, header := range headers {
+		if err := engine.VerifyHeader(chain, header, true); err != nil {
+			t.Errorf("header %d: verification failed: %v", i, err)
+		}
+	}
+	_, results := batch.VerifyHeaders(chain, headers, make([]bool, len(headers)))
+	for i := range headers {
+		select {
+		case err := <-results:
+			if err != nil {
+				t.Errorf("header %d: batch verification failed: %v", i, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("header %d: batch verification timeout", i)
+		}
+	}
+}
+
+// Reject adalah kasus pengujian header yang harus ditolak atau diterima mesin
+// konsensus dengan error tertentu.
+type Reject struct {
+	Name   string
+	Base   *types.Header              // Header valid yang disalin sebelum diubah, nil berarti header bawaan
+	Mutate func(header *types.Header) // Perubahan pada salinan header
+	Engine consensus.Engine           // Mesin pemverifikasi, nil berarti mesin bawaan
+	Want   error
+}
+
+// HeaderRejects mengembalikan kasus penolakan untuk aturan header yang sama di
+// semua mesin berpenanda tangan: waktu, mix digest, uncle hash, kesulitan dan
+// induk. parent adalah induk header bawaan, dan resign menyegel ulang header
+// setelah diubah.
+func HeaderRejects(parent *types.Header, resign func(header *types.Header)) []Reject {
+	mutate := func(change func(h *types.Header)) func(h *types.Header) {
+		return func(h *types.Header) {
+			change(h)
+			resign(h)
+		}
+	}
+	return []Reject{
+		{Name: "future", Mutate: mutate(func(h *types.Header) { h.Time = uint64(time.Now().Unix()) + 60 }), Want: consensus.ErrFutureBlock},
+		{Name: "mix digest", Mutate: mutate(func(h *types.Header) { h.MixDigest = common.Hash{1} }), Want: consensus.ErrInvalidMixDigest},
+		{Name: "uncle hash", Mutate: mutate(func(h *types.Header) { h.UncleHash = common.Hash{1} }), Want: consensus.ErrInvalidUncleHash},
+		{Name: "difficulty", Mutate: mutate(func(h *types.Header) { h.Difficulty = big.NewInt(3) }), Want: consensus.ErrInvalidDifficulty},
+		{Name: "timestamp", Mutate: mutate(func(h *types.Header) { h.Time = parent.Time - 1 }), Want: consensus.ErrInvalidTimestamp},
+		{Name: "unknown parent", Mutate: mutate(func(h *types.Header) { h.ParentHash = common.Hash{1} }), Want: consensus.ErrUnknownAncestor},
+	}
+}
+
+// VerifyRejects memverifikasi salinan header yang sudah diubah untuk setiap kasus.
+// Kasus yang tidak menentukan header atau mesinnya sendiri memakai base dan mesin
+// baru dari engine.
+func VerifyRejects(t testing.TB, chain consensus.ChainHeaderReader, base *types.Header, engine func() consensus.Engine, tests []Reject) {
+	t.Helper()
+
+	for _, test := range tests {
+		header := types.CopyHeader(base)
+		if test.Base != nil {
+			header = types.CopyHeader(test.Base)
+		}
+		test.Mutate(header)
+
+		verifier := test.Engine
+		if verifier == nil {
+			verifier = engine()
+		}
+		if err := verifier.VerifyHeader(chain, header, true); err != test.Want {
+			t.Errorf("%s: error mismatch: have %v, want %v", test.Name, err, test.Want)
+		}
+	}
+}
+
+// SealBlock menyegel blok dengan engine, menunggu hasilnya, lalu memastikan header
+// hasil segel lolos verifikasi.
+func SealBlock(t testing.TB, chain consensus.ChainHeaderReader, engine consensus.Engine, block *types.Block) *types.Block {
+	t.Helper()
+
+	results := make(chan *types.Block, 1)
+	if err := engine.Seal(chain, block, results, nil); err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	var sealed *types.Block
+	select {
+	case sealed = <-results:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("sealing result timeout")
+	}
+	if err := engine.VerifyHeader(chain, sealed.Header(), true); err != nil {
+		t.Fatalf("sealed header failed verification: %v", err)
+	}
+	return sealed
+}
@@ -0,0 +1,61 @@
+package consensustest
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Menguji bahwa akun pengujian diurutkan, tanda tangannya bisa dipulihkan ke alamat
+// penanda tangan, dan alamat yang tidak dikenal ditolak.
+func TestAccounts(t *testing.T) {
+	accs := NewAccounts(4)
+	for i := 1; i < len(accs.Addrs); i++ {
+		if accs.Addrs[i-1].Hex() >= accs.Addrs[i].Hex() {
+			t.Fatalf("accounts not sorted: %x", accs.Addrs)
+		}
+	}
+	message := []byte("message")
+	for _, addr := range accs.Addrs {
+		sig, err := accs.SignFn(addr)(accounts.Account{Address: addr}, "", message)
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		pubkey, err := crypto.SigToPub(crypto.Keccak256(message), sig)
+		if err != nil {
+			t.Fatalf("failed to recover signer: %v", err)
+		}
+		if signer := crypto.PubkeyToAddress(*pubkey); signer != addr {
+			t.Errorf("signer mismatch: have %x, want %x", signer, addr)
+		}
+		if other := accs.Other(addr); other == addr {
+			t.Errorf("other account mismatch: have %x, want any other", other)
+		}
+	}
+	if _, err := accs.Sign(common.Address{1}, common.Hash{}); err != errUnknownAccount {
+		t.Errorf("error mismatch: have %v, want %v", err, errUnknownAccount)
+	}
+}
+
+// Menguji bahwa state yang disimpan CommitState bisa dibuka kembali dari database.
+func TestCommitState(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	addr, slot, value := common.Address{1}, common.Hash{2}, common.BigToHash(big.NewInt(3))
+
+	root := CommitState(t, db, func(statedb *state.StateDB) {
+		statedb.SetCode(addr, []byte{0x00})
+		statedb.SetState(addr, slot, value)
+	})
+	statedb, err := state.New(root, state.NewDatabase(db), nil)
+	if err != nil {
+		t.Fatalf("failed to open committed state: %v", err)
+	}
+	if have := statedb.GetState(addr, slot); have != value {
+		t.Errorf("storage mismatch: have %x, want %x", have, value)
+	}
+}
@@ -0,0 +1,90 @@
+package dpos
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxDuties adalah jumlah maksimum giliran yang dikembalikan satu panggilan RPC.
+const maxDuties = 1024
+
+// Duty adalah satu slot produksi blok yang akan datang.
+type Duty struct {
+	Number uint64 `json:"number"` // Perkiraan nomor blok pada slot tersebut
+	Time   uint64 `json:"time"`   // Stempel waktu slot
+}
+
+// API adalah API RPC untuk memeriksa delegasi dan jadwal produksi blok dari skema
+// delegated proof-of-stake.
+type API struct {
+	chain consensus.ChainHeaderReader
+	dpos  *DPoS
+}
+
+// header mengambil header untuk nomor blok yang diminta (atau header saat ini
+// jika tidak ada yang diminta).
+func (api *API) header(number *rpc.BlockNumber) *types.Header {
+	if number == nil || *number == rpc.LatestBlockNumber {
+		return api.chain.CurrentHeader()
+	}
+	return api.chain.GetHeaderByNumber(uint64(number.Int64()))
+}
+
+// GetSchedule mengambil jadwal delegasi yang berlaku untuk anak dari blok tertentu.
+func (api *API) GetSchedule(number *rpc.BlockNumber) (*Schedule, error) {
+	header := api.header(number)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return api.dpos.schedule(api.chain, header.Number.Uint64(), header.Hash(), nil)
+}
+
+// GetDelegates mengambil daftar delegasi aktif pada blok tertentu.
+func (api *API) GetDelegates(number *rpc.BlockNumber) ([]common.Address, error) {
+	schedule, err := api.GetSchedule(number)
+	if err != nil {
+		return nil, err
+	}
+	return schedule.Delegates, nil
+}
+
+// GetDuties mengembalikan slot produksi milik delegasi mulai dari slot berikutnya
+// sampai checkpoint berikutnya, agar operator bisa menjadwalkan pemeliharaan di luar
+// slotnya. Slot sesudah checkpoint tidak bisa dihitung sebelumnya karena delegasi
+// epoch berikutnya ditentukan oleh suara di checkpoint tersebut. Nomor blok adalah
+// perkiraan yang mengasumsikan setiap slot menghasilkan satu blok; slot yang
+// terlewat menggeser checkpoint ke slot yang lebih akhir. Paling banyak 1024 slot
+// dikembalikan.
+func (api *API) GetDuties(delegate common.Address) ([]Duty, error) {
+	header := api.chain.CurrentHeader()
+	schedule, err := api.dpos.schedule(api.chain, header.Number.Uint64(), header.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if !schedule.contains(delegate) {
+		return nil, errUnauthorizedDelegate
+	}
+	var (
+		head   = header.Number.Uint64()
+		epoch  = api.dpos.config.Epoch
+		period = api.dpos.config.Period
+		end    = (head/epoch + 1) * epoch // Checkpoint berikutnya masih diproduksi dengan jadwal ini
+
+		duties []Duty
+	)
+	// Slot berikutnya dipilih dengan cara yang sama seperti Prepare
+	slot := header.Time/period + 1
+	if now := uint64(time.Now().Unix()) / period; slot < now {
+		slot = now
+	}
+	for number := head + 1; number <= end && len(duties) < maxDuties; number, slot = number+1, slot+1 {
+		if schedule.producer(slot) == delegate {
+			duties = append(duties, Duty{Number: number, Time: slot * period})
+		}
+	}
+	return duties, nil
+}
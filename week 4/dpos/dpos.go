@@ -0,0 +1,641 @@
+// Paket dpos mengimplementasikan mesin konsensus delegated proof-of-stake, di mana
+// pemegang token memilih sekumpulan delegasi yang bergiliran memproduksi blok.
+package dpos
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+	lru "github.com/hashicorp/golang-lru"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	inmemorySchedules  = 128  // Jumlah jadwal delegasi terbaru yang disimpan di memori
+	inmemorySignatures = 4096 // Jumlah signature blok terbaru yang disimpan di memori
+
+	mimetypeDPoS = "application/x-dpos-header" // Tipe data yang ditandatangani oleh delegasi
+)
+
+// Konstanta protokol delegated proof-of-stake.
+var (
+	epochLength   = uint64(30000) // Jumlah blok default sebelum suara dihitung ulang
+	blockPeriod   = uint64(3)     // Panjang default satu slot produksi blok dalam detik
+	maxDelegates  = 21            // Jumlah default delegasi aktif di setiap epoch
+	maxCandidates = 1024          // Jumlah default kandidat yang dihitung suaranya
+
+	extraVanity = 32                     // Jumlah byte awalan extra-data yang dicadangkan untuk vanity
+	extraSeal   = crypto.SignatureLength // Jumlah byte akhiran extra-data yang dicadangkan untuk segel
+
+	uncleHash = types.CalcUncleHash(nil) // Selalu Keccak256(RLP([])) karena paman tidak berarti di luar PoW.
+
+	defaultDiff = big.NewInt(1) // Kesulitan semua blok dpos, karena setiap slot hanya punya satu produsen
+)
+
+// Berbagai pesan error untuk menandai blok tidak valid. Error ini sengaja dibuat
+// privat agar bagian lain dari kode tidak bergantung pada error spesifik mesin ini.
+var (
+	// errUnknownBlock dikembalikan ketika jadwal delegasi diminta untuk blok yang
+	// bukan bagian dari blockchain lokal.
+	errUnknownBlock = errors.New("unknown block")
+
+	// errMissingVanity dikembalikan jika extra-data lebih pendek dari 32 byte.
+	errMissingVanity = errors.New("extra-data 32 byte vanity prefix missing")
+
+	// errMissingSignature dikembalikan jika extra-data tidak berisi signature 65 byte.
+	errMissingSignature = errors.New("extra-data 65 byte signature suffix missing")
+
+	// errExtraDelegates dikembalikan jika blok non-checkpoint berisi daftar delegasi.
+	errExtraDelegates = errors.New("non-checkpoint block contains extra delegate list")
+
+	// errInvalidCheckpointDelegates dikembalikan jika daftar delegasi pada checkpoint
+	// tidak valid.
+	errInvalidCheckpointDelegates = errors.New("invalid delegate list on checkpoint block")
+
+	// errMismatchingCheckpointDelegates dikembalikan saat pemrosesan blok jika daftar
+	// delegasi pada checkpoint berbeda dari hasil pemilihan yang tercatat di state.
+	errMismatchingCheckpointDelegates = errors.New("mismatching delegate list on checkpoint block")
+
+	// errInvalidCoinbase dikembalikan jika coinbase blok bukan produsennya.
+	errInvalidCoinbase = errors.New("coinbase does not match producer")
+
+	// errUnauthorizedDelegate dikembalikan jika header ditandatangani oleh pihak yang
+	// bukan delegasi aktif.
+	errUnauthorizedDelegate = errors.New("unauthorized delegate")
+
+	// errOutOfSchedule dikembalikan jika blok diproduksi oleh delegasi pada slot
+	// milik delegasi lain.
+	errOutOfSchedule = errors.New("block produced out of schedule")
+)
+
+// Config adalah parameter konsensus dari mesin delegated proof-of-stake.
+type Config struct {
+	Period        uint64         `json:"period"`        // Panjang satu slot produksi blok dalam detik
+	Epoch         uint64         `json:"epoch"`         // Jumlah blok sebelum suara dihitung ulang
+	MaxDelegates  int            `json:"maxDelegates"`  // Jumlah maksimum delegasi aktif di setiap epoch
+	MaxCandidates int            `json:"maxCandidates"` // Jumlah maksimum kandidat yang dihitung suaranya
+	Registry      common.Address `json:"registry"`      // Akun yang storage-nya menyimpan kandidat dan suara
+	BlockReward   *big.Int       `json:"blockReward"`   // Reward untuk produsen setiap blok (opsional)
+}
+
+// SignerFn melakukan hash dan menandatangani data menggunakan akun pendukung.
+type SignerFn func(signer accounts.Account, mimeType string, message []byte) ([]byte, error)
+
+// ecrecover mengekstrak alamat akun Ethereum dari header yang sudah ditandatangani.
+func ecrecover(header *types.Header, sigcache *lru.ARCCache) (common.Address, error) {
+	// Jika signature sudah ada di cache, kembalikan langsung
+	hash := header.Hash()
+	if address, known := sigcache.Get(hash); known {
+		return address.(common.Address), nil
+	}
+	// Ambil signature dari extra-data header
+	if len(header.Extra) < extraSeal {
+		return common.Address{}, errMissingSignature
+	}
+	signature := header.Extra[len(header.Extra)-extraSeal:]
+
+	// Pulihkan public key dan alamat Ethereum
+	pubkey, err := crypto.Ecrecover(SealHash(header).Bytes(), signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	var signer common.Address
+	copy(signer[:], crypto.Keccak256(pubkey[1:])[12:])
+
+	sigcache.Add(hash, signer)
+	return signer, nil
+}
+
+// DPoS adalah mesin konsensus delegated proof-of-stake. Suara pemegang token
+// dihitung dari state di akhir setiap epoch, hasilnya dicatat di extra-data blok
+// checkpoint, lalu delegasi terpilih bergiliran memproduksi blok per slot waktu.
+type DPoS struct {
+	consensus.NoFinality // Mesin ini tidak memiliki finalitas
+
+	config  *Config        // Parameter konfigurasi mesin konsensus
+	statedb state.Database // Database state untuk membaca hasil pemilihan
+
+	recents    *lru.ARCCache // Jadwal delegasi untuk blok terbaru agar reorg lebih cepat
+	signatures *lru.ARCCache // Signature dari blok terbaru agar verifikasi lebih cepat
+
+	signer common.Address // Alamat Ethereum dari kunci penandatangan
+	signFn SignerFn       // Fungsi signer untuk mengotorisasi hash
+	lock   sync.RWMutex   // Melindungi field signer
+}
+
+// New membuat mesin konsensus delegated proof-of-stake yang membaca suara dari
+// state di dalam database yang diberikan.
+func New(config *Config, db ethdb.Database) *DPoS {
+	// Isi parameter konsensus yang kosong dengan nilai default
+	conf := *config
+	if conf.Epoch == 0 {
+		conf.Epoch = epochLength
+	}
+	if conf.Period == 0 {
+		conf.Period = blockPeriod
+	}
+	if conf.MaxDelegates <= 0 {
+		conf.MaxDelegates = maxDelegates
+	}
+	if conf.MaxCandidates <= 0 {
+		conf.MaxCandidates = maxCandidates
+	}
+	recents, _ := lru.NewARC(inmemorySchedules)
+	signatures, _ := lru.NewARC(inmemorySignatures)
+
+	return &DPoS{
+		config:     &conf,
+		statedb:    state.NewDatabase(db),
+		recents:    recents,
+		signatures: signatures,
+	}
+}
+
+// Author mengimplementasikan consensus.Engine, mengembalikan alamat delegasi yang
+// dipulihkan dari signature di bagian extra-data header.
+func (d *DPoS) Author(header *types.Header) (common.Address, error) {
+	return ecrecover(header, d.signatures)
+}
+
+// VerifyHeader memeriksa apakah header sesuai dengan aturan konsensus.
+func (d *DPoS) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, seal bool) error {
+	return d.verifyHeader(chain, header, nil)
+}
+
+// VerifyHeaders mirip dengan VerifyHeader, tetapi memverifikasi sekumpulan header
+// secara berurutan di latar belakang. Metode mengembalikan saluran keluar untuk
+// membatalkan operasi dan saluran hasil (urutannya sama dengan inputan slice).
+func (d *DPoS) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	abort := make(chan struct{})
+	results := make(chan error, len(headers))
+
+	go func() {
+		for i, header := range headers {
+			err := d.verifyHeader(chain, header, headers[:i])
+
+			select {
+			case <-abort:
+				return
+			case results <- err:
+			}
+		}
+	}()
+	return abort, results
+}
+
+// verifyHeader memeriksa apakah header sesuai dengan aturan konsensus. Pemanggil
+// boleh memberikan sekumpulan induk (urutan naik) agar tidak perlu mencarinya dari
+// database.
+func (d *DPoS) verifyHeader(chain consensus.ChainHeaderReader, header *types.Header, parents []*types.Header) error {
+	if header.Number == nil {
+		return errUnknownBlock
+	}
+	number := header.Number.Uint64()
+
+	// Jangan buang waktu memeriksa blok dari masa depan
+	if header.Time > uint64(time.Now().Unix()) {
+		return consensus.ErrFutureBlock
+	}
+	// Pastikan extra-data berisi vanity dan signature
+	if len(header.Extra) < extraVanity {
+		return errMissingVanity
+	}
+	if len(header.Extra) < extraVanity+extraSeal {
+		return errMissingSignature
+	}
+	// Pastikan extra-data berisi daftar delegasi pada checkpoint, dan kosong selain itu
+	checkpoint := number%d.config.Epoch == 0
+	delegatesLen := len(header.Extra) - extraVanity - extraSeal
+	if !checkpoint && delegatesLen != 0 {
+		return errExtraDelegates
+	}
+	if checkpoint && (delegatesLen == 0 || delegatesLen%common.AddressLength != 0 || delegatesLen > d.config.MaxDelegates*common.AddressLength) {
+		return errInvalidCheckpointDelegates
+	}
+	// Pastikan mix digest nol karena tidak dipakai
+	if header.MixDigest != (common.Hash{}) {
+		return consensus.ErrInvalidMixDigest
+	}
+	// Pastikan blok tidak berisi paman yang tidak berarti di DPoS
+	if header.UncleHash != uncleHash {
+		return consensus.ErrInvalidUncleHash
+	}
+	// Pastikan kesulitan blok selalu 1
+	if number > 0 && (header.Difficulty == nil || header.Difficulty.Cmp(defaultDiff) != 0) {
+		return consensus.ErrInvalidDifficulty
+	}
+	// Verifikasi bahwa batas gas <= 2^63-1
+	if header.GasLimit > params.MaxGasLimit {
+		return fmt.Errorf("invalid gasLimit: have %v, max %v", header.GasLimit, params.MaxGasLimit)
+	}
+	// Semua pemeriksaan dasar lolos, verifikasi field yang bergantung pada induk
+	return d.verifyCascadingFields(chain, header, parents)
+}
+
+// verifyCascadingFields memverifikasi semua field header yang bergantung pada
+// sekumpulan header sebelumnya.
+func (d *DPoS) verifyCascadingFields(chain consensus.ChainHeaderReader, header *types.Header, parents []*types.Header) error {
+	// Blok genesis selalu valid
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil
+	}
+	var parent *types.Header
+	if len(parents) > 0 {
+		parent = parents[len(parents)-1]
+	} else {
+		parent = chain.GetHeader(header.ParentHash, number-1)
+	}
+	if parent == nil || parent.Number.Uint64() != number-1 || parent.Hash() != header.ParentHash {
+		return consensus.ErrUnknownAncestor
+	}
+	// Blok harus jatuh tepat di awal slot yang lebih baru dari slot induknya
+	if header.Time%d.config.Period != 0 || header.Time <= parent.Time {
+		return consensus.ErrInvalidTimestamp
+	}
+	// Verifikasi bahwa gas yang dipakai <= batas gas
+	if header.GasUsed > header.GasLimit {
+		return fmt.Errorf("invalid gasUsed: have %d, gasLimit %d", header.GasUsed, header.GasLimit)
+	}
+	if !chain.Config().IsLondon(header.Number) {
+		if header.BaseFee != nil {
+			return fmt.Errorf("invalid baseFee before fork: have %d, want <nil>", header.BaseFee)
+		}
+		if err := misc.VerifyGaslimit(parent.GasLimit, header.GasLimit); err != nil {
+			return err
+		}
+	} else if err := misc.VerifyEip1559Header(chain.Config(), parent, header); err != nil {
+		return err
+	}
+	// Daftar delegasi checkpoint hanya diperiksa bentuknya di sini agar header bisa
+	// disinkronkan tanpa state. Isinya dicocokkan dengan hasil pemilihan di
+	// VerifyUncles, saat blok diproses dan state induknya tersedia.
+	schedule, err := d.schedule(chain, number-1, header.ParentHash, parents)
+	if err != nil {
+		return err
+	}
+	if number%d.config.Epoch == 0 {
+		if err := verifyCheckpointDelegates(header.Extra[extraVanity : len(header.Extra)-extraSeal]); err != nil {
+			return err
+		}
+	}
+	return d.verifySeal(schedule, header)
+}
+
+// verifyCheckpointDelegates memeriksa bentuk daftar delegasi pada checkpoint tanpa
+// mengakses state: setiap delegasi hanya boleh muncul sekali, karena tally tidak
+// pernah memilih kandidat yang sama dua kali.
+func verifyCheckpointDelegates(data []byte) error {
+	seen := make(map[common.Address]struct{})
+	for i := 0; i < len(data); i += common.AddressLength {
+		delegate := common.BytesToAddress(data[i : i+common.AddressLength])
+		if _, ok := seen[delegate]; ok {
+			return errInvalidCheckpointDelegates
+		}
+		seen[delegate] = struct{}{}
+	}
+	return nil
+}
+
+// verifyCheckpointState mencocokkan daftar delegasi pada blok checkpoint dengan
+// hasil pemilihan di state induknya. Pemeriksaan ini bagian dari pemrosesan blok,
+// bukan verifikasi header, karena state induk baru tersedia setelah induk dieksekusi.
+func (d *DPoS) verifyCheckpointState(chain consensus.ChainHeaderReader, header *types.Header) error {
+	number := header.Number.Uint64()
+	if number == 0 || number%d.config.Epoch != 0 {
+		return nil
+	}
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	schedule, err := d.schedule(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	statedb, err := state.New(parent.Root, d.statedb, nil)
+	if err != nil {
+		return consensus.ErrPrunedAncestor
+	}
+	if !bytes.Equal(header.Extra[extraVanity:len(header.Extra)-extraSeal], encodeDelegates(d.elected(statedb, schedule))) {
+		return errMismatchingCheckpointDelegates
+	}
+	return nil
+}
+
+// elected mengembalikan delegasi untuk epoch berikutnya, yaitu hasil penghitungan
+// suara di state akhir epoch sebelumnya. Jika belum ada kandidat dengan suara,
+// jadwal lama dipertahankan agar rantai tidak berhenti.
+func (d *DPoS) elected(statedb *state.StateDB, current *Schedule) []common.Address {
+	if delegates := tally(statedb, d.config.Registry, d.config.MaxCandidates, d.config.MaxDelegates); len(delegates) > 0 {
+		return delegates
+	}
+	return current.Delegates
+}
+
+// encodeDelegates mengubah daftar delegasi menjadi byte untuk extra-data checkpoint.
+func encodeDelegates(delegates []common.Address) []byte {
+	out := make([]byte, 0, len(delegates)*common.AddressLength)
+	for _, delegate := range delegates {
+		out = append(out, delegate[:]...)
+	}
+	return out
+}
+
+// schedule mengambil jadwal delegasi yang berlaku untuk anak dari blok dengan
+// nomor dan hash tertentu, yaitu jadwal dari checkpoint terakhir di rantainya.
+func (d *DPoS) schedule(chain consensus.ChainHeaderReader, number uint64, hash common.Hash, parents []*types.Header) (*Schedule, error) {
+	var (
+		hashes   []common.Hash
+		schedule *Schedule
+	)
+	for schedule == nil {
+		// Jika jadwal di memori ditemukan, gunakan itu
+		if s, ok := d.recents.Get(hash); ok {
+			schedule = s.(*Schedule)
+			break
+		}
+		// Ambil header, dari induk eksplisit jika ada atau dari database
+		var header *types.Header
+		if len(parents) > 0 {
+			header = parents[len(parents)-1]
+			if header.Hash() != hash || header.Number.Uint64() != number {
+				return nil, consensus.ErrUnknownAncestor
+			}
+			parents = parents[:len(parents)-1]
+		} else {
+			header = chain.GetHeader(hash, number)
+			if header == nil {
+				return nil, consensus.ErrUnknownAncestor
+			}
+		}
+		// Checkpoint menetapkan jadwal delegasi baru
+		if number%d.config.Epoch == 0 {
+			if len(header.Extra) < extraVanity+extraSeal {
+				return nil, errMissingSignature
+			}
+			schedule = &Schedule{Number: number, Hash: hash}
+			delegates := header.Extra[extraVanity : len(header.Extra)-extraSeal]
+			for i := 0; i+common.AddressLength <= len(delegates); i += common.AddressLength {
+				schedule.Delegates = append(schedule.Delegates, common.BytesToAddress(delegates[i:i+common.AddressLength]))
+			}
+			break
+		}
+		hashes = append(hashes, hash)
+		number, hash = number-1, header.ParentHash
+	}
+	// Simpan jadwal untuk semua blok yang dilewati agar pencarian berikutnya cepat
+	d.recents.Add(schedule.Hash, schedule)
+	for _, hash := range hashes {
+		d.recents.Add(hash, schedule)
+	}
+	return schedule, nil
+}
+
+// verifySeal memeriksa apakah signature di dalam header berasal dari delegasi yang
+// dijadwalkan untuk slot waktu blok tersebut.
+func (d *DPoS) verifySeal(schedule *Schedule, header *types.Header) error {
+	if header.Number.Uint64() == 0 {
+		return errUnknownBlock
+	}
+	signer, err := ecrecover(header, d.signatures)
+	if err != nil {
+		return err
+	}
+	if !schedule.contains(signer) {
+		return errUnauthorizedDelegate
+	}
+	if header.Coinbase != signer {
+		return errInvalidCoinbase
+	}
+	if schedule.producer(header.Time/d.config.Period) != signer {
+		return errOutOfSchedule
+	}
+	return nil
+}
+
+// VerifyUncles mengimplementasikan consensus.Engine, selalu mengembalikan error untuk
+// paman karena mekanisme konsensus ini tidak mengizinkan paman. Metode ini dipanggil
+// saat body blok divalidasi sebelum blok dieksekusi, jadi daftar delegasi checkpoint
+// juga dicocokkan dengan hasil pemilihan di sini: Finalize tidak bisa menolak blok.
+func (d *DPoS) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
+	if len(block.Uncles()) > 0 {
+		return consensus.ErrTooManyUncles
+	}
+	return d.verifyCheckpointState(chain, block.Header())
+}
+
+// Prepare mengimplementasikan consensus.Engine, menyiapkan semua field konsensus
+// dari header sebelum transaksi dijalankan di atasnya. Stempel waktu diisi dengan
+// slot terdekat milik delegasi lokal.
+func (d *DPoS) Prepare(chain consensus.ChainHeaderReader, header *types.Header) error {
+	number := header.Number.Uint64()
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	schedule, err := d.schedule(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	d.lock.RLock()
+	signer := d.signer
+	d.lock.RUnlock()
+
+	header.Coinbase = signer
+	header.Nonce = types.BlockNonce{}
+	header.MixDigest = common.Hash{}
+	header.Difficulty = new(big.Int).Set(defaultDiff)
+
+	// Pastikan extra data memiliki semua komponennya
+	if len(header.Extra) < extraVanity {
+		header.Extra = append(header.Extra, bytes.Repeat([]byte{0x00}, extraVanity-len(header.Extra))...)
+	}
+	header.Extra = header.Extra[:extraVanity]
+
+	if number%d.config.Epoch == 0 {
+		statedb, err := state.New(parent.Root, d.statedb, nil)
+		if err != nil {
+			return err
+		}
+		header.Extra = append(header.Extra, encodeDelegates(d.elected(statedb, schedule))...)
+	}
+	header.Extra = append(header.Extra, make([]byte, extraSeal)...)
+
+	// Cari slot berikutnya yang menjadi giliran delegasi lokal. Jika delegasi lokal
+	// tidak ada di jadwal, pakai slot terdekat dan biarkan Seal menolaknya.
+	slot := parent.Time/d.config.Period + 1
+	if now := uint64(time.Now().Unix()) / d.config.Period; slot < now {
+		slot = now
+	}
+	if schedule.contains(signer) {
+		for schedule.producer(slot) != signer {
+			slot++
+		}
+	}
+	header.Time = slot * d.config.Period
+	return nil
+}
+
+// Finalize mengimplementasikan consensus.Engine, memberikan block reward (jika
+// dikonfigurasi) kepada produsen.
+func (d *DPoS) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header) {
+	if d.config.BlockReward != nil && d.config.BlockReward.Sign() > 0 {
+		state.AddBalance(header.Coinbase, d.config.BlockReward)
+	}
+	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
+	header.UncleHash = types.CalcUncleHash(nil)
+}
+
+// FinalizeAndAssemble mengimplementasikan consensus.Engine, menjalankan Finalize
+// lalu merakit blok terakhir.
+func (d *DPoS) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
+	d.Finalize(chain, header, state, txs, uncles)
+	return types.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil)), nil
+}
+
+// Authorize menyuntikkan kunci privat ke dalam mesin konsensus untuk memproduksi
+// blok baru.
+func (d *DPoS) Authorize(signer common.Address, signFn SignerFn) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.signer = signer
+	d.signFn = signFn
+}
+
+// Seal mengimplementasikan consensus.Engine, mencoba membuat blok tersegel
+// menggunakan kredensial delegasi lokal pada slot miliknya.
+func (d *DPoS) Seal(chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
+	header := block.Header()
+
+	// Penyegelan blok genesis tidak didukung
+	number := header.Number.Uint64()
+	if number == 0 {
+		return errUnknownBlock
+	}
+	d.lock.RLock()
+	signer, signFn := d.signer, d.signFn
+	d.lock.RUnlock()
+
+	// Berhenti jika slot ini bukan milik delegasi lokal
+	schedule, err := d.schedule(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	if !schedule.contains(signer) {
+		return errUnauthorizedDelegate
+	}
+	if schedule.producer(header.Time/d.config.Period) != signer {
+		return errOutOfSchedule
+	}
+	// Tandatangani header
+	sighash, err := signFn(accounts.Account{Address: signer}, mimetypeDPoS, DPoSRLP(header))
+	if err != nil {
+		return err
+	}
+	copy(header.Extra[len(header.Extra)-extraSeal:], sighash)
+
+	// Tunggu sampai slot dimulai atau penyegelan dihentikan
+	delay := time.Until(time.Unix(int64(header.Time), 0))
+	log.Trace("Waiting for slot to produce block", "number", number, "delay", common.PrettyDuration(delay))
+	go func() {
+		select {
+		case <-stop:
+			return
+		case <-time.After(delay):
+		}
+		select {
+		case results <- block.WithSeal(header):
+		default:
+			log.Warn("Sealing result is not read by miner", "sealhash", SealHash(header))
+		}
+	}()
+	return nil
+}
+
+// CalcDifficulty adalah algoritma penyesuaian kesulitan, selalu mengembalikan 1.
+func (d *DPoS) CalcDifficulty(chain consensus.ChainHeaderReader, time uint64, parent *types.Header) *big.Int {
+	return new(big.Int).Set(defaultDiff)
+}
+
+// SealHash mengembalikan hash dari sebuah blok sebelum disegel.
+func (d *DPoS) SealHash(header *types.Header) common.Hash {
+	return SealHash(header)
+}
+
+// Close mengimplementasikan consensus.Engine. Tidak melakukan apa-apa karena
+// mesin ini tidak memiliki utas latar belakang.
+func (d *DPoS) Close() error {
+	return nil
+}
+
+// APIs mengimplementasikan consensus.Engine, mengembalikan API RPC untuk
+// memeriksa delegasi dan jadwal produksi blok.
+func (d *DPoS) APIs(chain consensus.ChainHeaderReader) []rpc.API {
+	return []rpc.API{{
+		Namespace: "dpos",
+		Service:   &API{chain: chain, dpos: d},
+	}}
+}
+
+// SealHash mengembalikan hash dari sebuah blok sebelum disegel.
+func SealHash(header *types.Header) (hash common.Hash) {
+	hasher := sha3.NewLegacyKeccak256()
+	encodeSigHeader(hasher, header)
+	hasher.(crypto.KeccakState).Read(hash[:])
+	return hash
+}
+
+// DPoSRLP mengembalikan byte rlp yang perlu ditandatangani oleh delegasi, yaitu
+// seluruh header kecuali signature 65 byte di akhir extra data.
+func DPoSRLP(header *types.Header) []byte {
+	b := new(bytes.Buffer)
+	encodeSigHeader(b, header)
+	return b.Bytes()
+}
+
+// encodeSigHeader menulis encoding RLP dari header tanpa segel signature.
+func encodeSigHeader(w io.Writer, header *types.Header) {
+	enc := []interface{}{
+		header.ParentHash,
+		header.UncleHash,
+		header.Coinbase,
+		header.Root,
+		header.TxHash,
+		header.ReceiptHash,
+		header.Bloom,
+		header.Difficulty,
+		header.Number,
+		header.GasLimit,
+		header.GasUsed,
+		header.Time,
+		header.Extra[:len(header.Extra)-crypto.SignatureLength], // Ya, ini akan panic jika extra terlalu pendek
+		header.MixDigest,
+		header.Nonce,
+	}
+	if header.BaseFee != nil {
+		enc = append(enc, header.BaseFee)
+	}
+	if err := rlp.Encode(w, enc); err != nil {
+		panic("can't encode: " + err.Error())
+	}
+}
@@ -0,0 +1,341 @@
+package dpos
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/consensustest"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// tester menyiapkan rantai di memori, state registry suara dan kunci delegasi
+// untuk pengujian mesin delegated proof-of-stake.
+type tester struct {
+	*consensustest.Accounts // Kandidat di registry
+
+	t      *testing.T
+	db     ethdb.Database
+	root   common.Hash // Root state dengan isi registry, dipakai oleh semua blok
+	config *Config
+	chain  *consensustest.HeaderChain
+}
+
+// newTester membuat rantai dengan satu kandidat untuk setiap jumlah suara yang
+// diberikan. Semua kandidat menjadi delegasi genesis, dan registry di state berisi
+// suara masing-masing kandidat sesuai urutan alamat.
+func newTester(t *testing.T, config *Config, votes ...int64) *tester {
+	t.Helper()
+
+	tt := &tester{
+		Accounts: consensustest.NewAccounts(len(votes)),
+		t:        t,
+		db:       rawdb.NewMemoryDatabase(),
+		config:   config,
+	}
+	tt.root = consensustest.CommitState(t, tt.db, func(statedb *state.StateDB) {
+		statedb.SetState(config.Registry, candidatesSlot, common.BigToHash(big.NewInt(int64(len(tt.Addrs)))))
+		for i, addr := range tt.Addrs {
+			statedb.SetState(config.Registry, arraySlot(candidatesSlot, uint64(i)), common.BytesToHash(addr[:]))
+			statedb.SetState(config.Registry, crypto.Keccak256Hash(common.LeftPadBytes(addr[:], 32), votesSlot[:]), common.BigToHash(big.NewInt(votes[i])))
+		}
+	})
+	genesis := consensustest.Genesis()
+	genesis.Root = tt.root
+	genesis.Extra = append(append(make([]byte, extraVanity), encodeDelegates(tt.Addrs)...), make([]byte, extraSeal)...)
+	tt.chain = consensustest.NewHeaderChain(params.AllCliqueProtocolChanges, genesis)
+	return tt
+}
+
+// engine membuat mesin delegated proof-of-stake baru di atas database pengujian.
+func (tt *tester) engine() *DPoS {
+	return New(tt.config, tt.db)
+}
+
+// sign menandatangani header dengan kunci delegasi yang diberikan.
+func (tt *tester) sign(header *types.Header, signer common.Address) {
+	sig, err := tt.Sign(signer, SealHash(header))
+	if err != nil {
+		tt.t.Fatalf("failed to sign header: %v", err)
+	}
+	copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+}
+
+// extend menambahkan n blok ke rantai, masing-masing pada slot tepat setelah
+// induknya dan disegel oleh delegasi yang dijadwalkan untuk slot tersebut.
+func (tt *tester) extend(engine *DPoS, n int) []*types.Header {
+	tt.t.Helper()
+
+	pick := func(header, parent *types.Header) common.Address {
+		number := header.Number.Uint64()
+		schedule, err := engine.schedule(tt.chain, number-1, header.ParentHash, nil)
+		if err != nil {
+			tt.t.Fatalf("block %d: failed to retrieve schedule: %v", number, err)
+		}
+		signer := schedule.producer(parent.Time/engine.config.Period + 1)
+		engine.Authorize(signer, tt.SignFn(signer))
+		return signer
+	}
+	seal := func(header, parent *types.Header, signer common.Address) {
+		header.Time = (parent.Time/engine.config.Period + 1) * engine.config.Period
+		header.Root = tt.root
+		tt.sign(header, signer)
+	}
+	return consensustest.Extend(tt.t, tt.chain, engine, n, pick, seal)
+}
+
+// other mengembalikan delegasi mana pun di jadwal selain yang diberikan.
+func other(schedule *Schedule, signer common.Address) common.Address {
+	for _, delegate := range schedule.Delegates {
+		if delegate != signer {
+			return delegate
+		}
+	}
+	return signer
+}
+
+// Menguji bahwa rantai yang melewati beberapa checkpoint lolos verifikasi satu per
+// satu dan dalam batch.
+func TestVerifyChain(t *testing.T) {
+	tt := newTester(t, &Config{Period: 1, Epoch: 3, MaxDelegates: 2, Registry: common.HexToAddress("0x1000")}, 0, 100, 500)
+	headers := tt.extend(tt.engine(), 10)
+
+	// Verifikasi batch memakai database kosong, seperti sinkronisasi header yang
+	// belum mengeksekusi blok mana pun
+	consensustest.VerifyChain(t, tt.chain, headers, tt.engine(), New(tt.config, rawdb.NewMemoryDatabase()))
+}
+
+// Menguji bahwa header yang melanggar aturan delegated proof-of-stake ditolak dengan
+// error yang sesuai, terutama slot milik delegasi lain dan bentuk daftar delegasi
+// checkpoint.
+func TestVerifyHeaderReject(t *testing.T) {
+	config := &Config{Period: 1, Epoch: 4, Registry: common.HexToAddress("0x1000")}
+	tt := newTester(t, config, 100, 300)
+	headers := tt.extend(tt.engine(), 5)
+
+	block, checkpoint := headers[1], headers[3]
+	signer, _ := tt.engine().Author(block)
+	checkpointSigner, _ := tt.engine().Author(checkpoint)
+	schedule, _ := tt.engine().schedule(tt.chain, 1, headers[0].Hash(), nil)
+	strangers := consensustest.NewAccounts(1)
+
+	resign := func(h *types.Header) { tt.sign(h, signer) }
+	tests := append(consensustest.HeaderRejects(headers[0], resign), []consensustest.Reject{
+		{Name: "missing vanity", Mutate: func(h *types.Header) { h.Extra = h.Extra[:extraVanity-1] }, Want: errMissingVanity},
+		{Name: "missing signature", Mutate: func(h *types.Header) { h.Extra = h.Extra[:extraVanity] }, Want: errMissingSignature},
+		{Name: "extra delegates", Mutate: func(h *types.Header) {
+			h.Extra = append(append(h.Extra[:extraVanity:extraVanity], make([]byte, common.AddressLength)...), make([]byte, extraSeal)...)
+			resign(h)
+		}, Want: errExtraDelegates},
+		{Name: "coinbase", Mutate: func(h *types.Header) { h.Coinbase = other(schedule, signer); resign(h) }, Want: errInvalidCoinbase},
+		{Name: "unauthorized", Mutate: func(h *types.Header) {
+			h.Coinbase = strangers.Addrs[0]
+			sig, _ := strangers.Sign(h.Coinbase, SealHash(h))
+			copy(h.Extra[len(h.Extra)-extraSeal:], sig)
+		}, Want: errUnauthorizedDelegate},
+		{Name: "out of schedule", Mutate: func(h *types.Header) {
+			h.Coinbase = other(schedule, signer)
+			tt.sign(h, h.Coinbase)
+		}, Want: errOutOfSchedule},
+		{Name: "duplicate checkpoint delegate", Base: checkpoint, Mutate: func(h *types.Header) {
+			copy(h.Extra[extraVanity+common.AddressLength:], h.Extra[extraVanity:extraVanity+common.AddressLength])
+			tt.sign(h, checkpointSigner)
+		}, Want: errInvalidCheckpointDelegates},
+		{Name: "truncated checkpoint", Base: checkpoint, Mutate: func(h *types.Header) {
+			h.Extra = append(h.Extra[:extraVanity+1:extraVanity+1], make([]byte, extraSeal)...)
+		}, Want: errInvalidCheckpointDelegates},
+		{Name: "oversized checkpoint", Base: checkpoint, Mutate: func(h *types.Header) {},
+			Engine: New(&Config{Period: 1, Epoch: 4, MaxDelegates: 1, Registry: config.Registry}, tt.db), Want: errInvalidCheckpointDelegates},
+		{Name: "missing checkpoint state", Base: checkpoint, Mutate: func(h *types.Header) {},
+			Engine: New(config, rawdb.NewMemoryDatabase()), Want: nil},
+	}...)
+	consensustest.VerifyRejects(t, tt.chain, block, func() consensus.Engine { return tt.engine() }, tests)
+}
+
+// Menguji bahwa daftar delegasi checkpoint dicocokkan dengan hasil pemilihan di
+// state induk saat body blok divalidasi, bukan saat verifikasi header.
+func TestVerifyCheckpointState(t *testing.T) {
+	config := &Config{Period: 1, Epoch: 4, Registry: common.HexToAddress("0x1000")}
+	tt := newTester(t, config, 100, 300)
+	headers := tt.extend(tt.engine(), 5)
+	checkpoint := headers[3]
+	signer, _ := tt.engine().Author(checkpoint)
+
+	// Urutan delegasi yang ditukar masih berbentuk valid sehingga lolos verifikasi header
+	forged := types.CopyHeader(checkpoint)
+	first := append([]byte{}, forged.Extra[extraVanity:extraVanity+common.AddressLength]...)
+	copy(forged.Extra[extraVanity:], forged.Extra[extraVanity+common.AddressLength:extraVanity+2*common.AddressLength])
+	copy(forged.Extra[extraVanity+common.AddressLength:], first)
+	tt.sign(forged, signer)
+	if err := tt.engine().VerifyHeader(tt.chain, forged, true); err != nil {
+		t.Fatalf("forged checkpoint header rejected: %v", err)
+	}
+	tests := []struct {
+		name   string
+		header *types.Header
+		engine *DPoS
+		want   error
+	}{
+		{"valid", checkpoint, tt.engine(), nil},
+		{"non-checkpoint", headers[4], New(config, rawdb.NewMemoryDatabase()), nil},
+		{"mismatching", forged, tt.engine(), errMismatchingCheckpointDelegates},
+		{"missing state", checkpoint, New(config, rawdb.NewMemoryDatabase()), consensus.ErrPrunedAncestor},
+	}
+	for _, test := range tests {
+		if err := test.engine.VerifyUncles(tt.chain, types.NewBlockWithHeader(test.header)); err != test.want {
+			t.Errorf("%s: error mismatch: have %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+// Menguji bahwa Seal menandatangani blok dengan delegasi yang dijadwalkan dan
+// menolak penyegelan pada slot milik delegasi lain.
+func TestSeal(t *testing.T) {
+	tt := newTester(t, &Config{Period: 1, Epoch: 100, Registry: common.HexToAddress("0x1000")}, 100, 300)
+	engine := tt.engine()
+
+	parent := tt.chain.CurrentHeader()
+	schedule, err := engine.schedule(tt.chain, 0, parent.Hash(), nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve schedule: %v", err)
+	}
+	signer := schedule.producer(parent.Time + 1)
+	engine.Authorize(signer, tt.SignFn(signer))
+
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     big.NewInt(1),
+		GasLimit:   parent.GasLimit,
+		BaseFee:    misc.CalcBaseFee(tt.chain.Config(), parent),
+		UncleHash:  uncleHash,
+	}
+	if err := engine.Prepare(tt.chain, header); err != nil {
+		t.Fatalf("failed to prepare header: %v", err)
+	}
+	header.Time = parent.Time + 1
+	header.Root = tt.root
+
+	sealed := consensustest.SealBlock(t, tt.chain, engine, types.NewBlockWithHeader(header))
+	if author, err := tt.engine().Author(sealed.Header()); err != nil || author != signer {
+		t.Fatalf("author mismatch: have %x (%v), want %x", author, err, signer)
+	}
+	// Delegasi lain tidak boleh menyegel slot yang bukan miliknya
+	backup := tt.engine()
+	backup.Authorize(other(schedule, signer), tt.SignFn(other(schedule, signer)))
+	if err := backup.Seal(tt.chain, types.NewBlockWithHeader(header), make(chan *types.Block, 1), nil); err != errOutOfSchedule {
+		t.Fatalf("error mismatch: have %v, want %v", err, errOutOfSchedule)
+	}
+}
+
+// Menguji bahwa checkpoint menetapkan delegasi sesuai suara terbanyak, dan bahwa
+// jadwal lewat API mengikuti checkpoint terakhir.
+func TestElection(t *testing.T) {
+	tt := newTester(t, &Config{Period: 1, Epoch: 3, MaxDelegates: 2, Registry: common.HexToAddress("0x1000")}, 0, 100, 500)
+	headers := tt.extend(tt.engine(), 4)
+	api := &API{chain: tt.chain, dpos: tt.engine()}
+
+	genesis := rpc.BlockNumber(0)
+	if delegates, err := api.GetDelegates(&genesis); err != nil || len(delegates) != 3 {
+		t.Fatalf("genesis delegates mismatch: have %d (%v), want 3", len(delegates), err)
+	}
+	schedule, err := api.GetSchedule(nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve schedule: %v", err)
+	}
+	if schedule.Number != 3 || schedule.Hash != headers[2].Hash() {
+		t.Fatalf("schedule checkpoint mismatch: have %d/%x, want 3/%x", schedule.Number, schedule.Hash, headers[2].Hash())
+	}
+	if want := []common.Address{tt.Addrs[2], tt.Addrs[1]}; len(schedule.Delegates) != 2 || schedule.Delegates[0] != want[0] || schedule.Delegates[1] != want[1] {
+		t.Fatalf("elected delegates mismatch: have %x, want %x", schedule.Delegates, want)
+	}
+}
+
+// Menguji bahwa checkpoint mempertahankan delegasi lama jika belum ada kandidat
+// dengan suara, sehingga rantai tidak berhenti.
+func TestNoVotesKeepsDelegates(t *testing.T) {
+	tt := newTester(t, &Config{Period: 1, Epoch: 3, Registry: common.HexToAddress("0x1000")}, 0, 0)
+	headers := tt.extend(tt.engine(), 5)
+
+	checkpoint := headers[2]
+	if have, want := checkpoint.Extra[extraVanity:len(checkpoint.Extra)-extraSeal], encodeDelegates(tt.Addrs); string(have) != string(want) {
+		t.Fatalf("checkpoint delegates mismatch: have %x, want %x", have, want)
+	}
+	for i, header := range headers {
+		if err := tt.engine().VerifyHeader(tt.chain, header, true); err != nil {
+			t.Errorf("header %d: verification failed: %v", i, err)
+		}
+	}
+}
+
+// Menguji bahwa penghitungan suara dibatasi walaupun panjang array kandidat di
+// storage sangat besar, dan bahwa hasilnya dibatasi jumlah delegasi maksimum.
+func TestTallyBounded(t *testing.T) {
+	registry := common.HexToAddress("0x1000")
+	tt := newTester(t, &Config{Period: 1, Epoch: 3, Registry: registry}, 10, 20, 0, 40)
+	statedb, _ := state.New(tt.root, state.NewDatabase(tt.db), nil)
+
+	if have := tally(statedb, registry, 100, 100); len(have) != 3 {
+		t.Errorf("delegate count mismatch: have %d, want 3", len(have))
+	}
+	if have := tally(statedb, registry, 100, 2); len(have) != 2 {
+		t.Errorf("delegate count with maximum mismatch: have %d, want 2", len(have))
+	}
+	if have := tally(statedb, registry, 2, 100); len(have) != 2 {
+		t.Errorf("delegate count with candidate limit mismatch: have %d, want 2", len(have))
+	}
+	// Panjang array yang sangat besar tidak boleh membuat penghitungan berjalan lama
+	statedb.SetState(registry, candidatesSlot, common.BigToHash(new(big.Int).Lsh(common.Big1, 200)))
+	start := time.Now()
+	if have := tally(statedb, registry, 16, 100); len(have) != 3 {
+		t.Errorf("delegate count with huge length mismatch: have %d, want 3", len(have))
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("bounded tally took too long: %v", elapsed)
+	}
+}
+
+// Menguji bahwa slot delegasi mencakup setiap blok sampai checkpoint berikutnya tepat
+// satu kali, pada slot berurutan mulai dari sekarang, dan bahwa alamat yang bukan
+// delegasi ditolak.
+func TestDuties(t *testing.T) {
+	tt := newTester(t, &Config{Period: 1, Epoch: 3, MaxDelegates: 2, Registry: common.HexToAddress("0x1000")}, 0, 100, 500)
+	tt.extend(tt.engine(), 4)
+	api := &API{chain: tt.chain, dpos: tt.engine()}
+
+	schedule, err := api.GetSchedule(nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve schedule: %v", err)
+	}
+	now := uint64(time.Now().Unix())
+	slots := make(map[uint64]uint64) // Nomor blok ke stempel waktu slot
+	for _, delegate := range schedule.Delegates {
+		duties, err := api.GetDuties(delegate)
+		if err != nil {
+			t.Fatalf("failed to retrieve duties: %v", err)
+		}
+		for _, duty := range duties {
+			if _, ok := slots[duty.Number]; ok {
+				t.Errorf("block %d: duty assigned twice", duty.Number)
+			}
+			slots[duty.Number] = duty.Time
+			if producer := schedule.producer(duty.Time / tt.config.Period); producer != delegate {
+				t.Errorf("block %d: producer mismatch: have %x, want %x", duty.Number, delegate, producer)
+			}
+		}
+	}
+	if len(slots) != 2 || slots[6] != slots[5]+1 || slots[5] < now || slots[5] > now+1 {
+		t.Errorf("duty slots mismatch: have %v, want blocks 5-6 on consecutive slots from %d", slots, now)
+	}
+	if _, err := api.GetDuties(tt.Addrs[0]); err != errUnauthorizedDelegate {
+		t.Errorf("error mismatch: have %v, want %v", err, errUnauthorizedDelegate)
+	}
+}
@@ -0,0 +1,118 @@
+package dpos
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Tata letak storage akun registry pemungutan suara. Tata letak ini sama dengan
+// kontrak Solidity berikut, jadi pemegang token bisa memberikan suara lewat kontrak
+// biasa yang menjaga penghitungan bobot suaranya sendiri:
+//
+//	contract DelegateVoting {
+//	    address[] candidates;               // slot 0
+//	    mapping(address => uint256) votes;  // slot 1
+//	}
+//
+// Hasil pemilihan tidak disimpan di storage kontrak, karena kontrak bisa menulis
+// storage-nya sendiri. Jadwal delegasi hanya dicatat di extra-data checkpoint dan
+// setiap node menghitung ulang suara untuk memverifikasinya.
+var (
+	candidatesSlot = common.Hash{}                   // Slot panjang array kandidat
+	votesSlot      = common.BigToHash(big.NewInt(1)) // Slot dasar mapping jumlah suara
+)
+
+// candidate adalah satu kandidat delegasi beserta jumlah suaranya.
+type candidate struct {
+	address common.Address
+	votes   *big.Int
+}
+
+// candidatesByVotes mengimplementasikan sort.Interface untuk mengurutkan kandidat
+// berdasarkan suara terbanyak, lalu berdasarkan alamat jika suaranya sama.
+type candidatesByVotes []candidate
+
+func (s candidatesByVotes) Len() int { return len(s) }
+func (s candidatesByVotes) Less(i, j int) bool {
+	if cmp := s[i].votes.Cmp(s[j].votes); cmp != 0 {
+		return cmp > 0
+	}
+	return bytes.Compare(s[i].address[:], s[j].address[:]) < 0
+}
+func (s candidatesByVotes) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+// arraySlot mengembalikan slot storage untuk elemen ke-i dari array dinamis
+// Solidity yang panjangnya disimpan di slot yang diberikan.
+func arraySlot(slot common.Hash, i uint64) common.Hash {
+	base := new(big.Int).SetBytes(crypto.Keccak256(slot[:]))
+	return common.BigToHash(base.Add(base, new(big.Int).SetUint64(i)))
+}
+
+// tally menghitung suara dari state dan mengembalikan paling banyak max delegasi
+// dengan suara terbanyak. Kandidat tanpa suara diabaikan. Panjang array kandidat
+// dikendalikan oleh kontrak, jadi hanya limit kandidat pertama yang dihitung.
+func tally(statedb *state.StateDB, registry common.Address, limit int, max int) []common.Address {
+	count := statedb.GetState(registry, candidatesSlot).Big()
+	if count.Cmp(big.NewInt(int64(limit))) > 0 {
+		count.SetInt64(int64(limit))
+	}
+
+	var (
+		candidates []candidate
+		seen       = make(map[common.Address]struct{})
+	)
+	for i := uint64(0); i < count.Uint64(); i++ {
+		address := common.BytesToAddress(statedb.GetState(registry, arraySlot(candidatesSlot, i)).Bytes())
+		if _, ok := seen[address]; ok {
+			continue
+		}
+		seen[address] = struct{}{}
+
+		votes := statedb.GetState(registry, crypto.Keccak256Hash(common.LeftPadBytes(address[:], 32), votesSlot[:])).Big()
+		if votes.Sign() == 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{address: address, votes: votes})
+	}
+	sort.Sort(candidatesByVotes(candidates))
+	if len(candidates) > max {
+		candidates = candidates[:max]
+	}
+	delegates := make([]common.Address, len(candidates))
+	for i, c := range candidates {
+		delegates[i] = c.address
+	}
+	return delegates
+}
+
+// Schedule adalah daftar delegasi yang ditetapkan oleh sebuah blok checkpoint dan
+// dipakai untuk jadwal produksi blok sepanjang epoch tersebut.
+type Schedule struct {
+	Number    uint64           `json:"number"`    // Nomor blok checkpoint yang menetapkan jadwal ini
+	Hash      common.Hash      `json:"hash"`      // Hash blok checkpoint yang menetapkan jadwal ini
+	Delegates []common.Address `json:"delegates"` // Delegasi sesuai urutan hasil pemilihan
+}
+
+// producer mengembalikan delegasi yang dijadwalkan memproduksi blok pada slot
+// waktu tertentu. Delegasi dirotasi secara round-robin setiap slot.
+func (s *Schedule) producer(slot uint64) common.Address {
+	if len(s.Delegates) == 0 {
+		return common.Address{}
+	}
+	return s.Delegates[slot%uint64(len(s.Delegates))]
+}
+
+// contains mengembalikan apakah alamat yang diberikan adalah delegasi aktif.
+func (s *Schedule) contains(address common.Address) bool {
+	for _, delegate := range s.Delegates {
+		if delegate == address {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package consensus
+
+import "errors"
+
+// Error verifikasi yang dipakai bersama oleh semua mesin konsensus. Pemanggil
+// VerifyHeader dan VerifyUncles bisa membandingkan error dengan errors.Is alih-alih
+// mencocokkan teks pesannya. Error yang hanya berarti untuk satu mesin tetap dibuat
+// privat di paket mesin tersebut.
+var (
+	// ErrUnknownAncestor dikembalikan ketika validasi blok membutuhkan leluhur
+	// yang tidak dikenal.
+	ErrUnknownAncestor = errors.New("unknown ancestor")
+
+	// ErrPrunedAncestor dikembalikan ketika validasi blok membutuhkan leluhur yang
+	// dikenal, tetapi state-nya tidak tersedia.
+	ErrPrunedAncestor = errors.New("pruned ancestor")
+
+	// ErrFutureBlock dikembalikan ketika stempel waktu blok berada di masa depan
+	// menurut node saat ini.
+	ErrFutureBlock = errors.New("block in the future")
+
+	// ErrInvalidNumber dikembalikan jika nomor blok bukan nomor induk ditambah satu.
+	ErrInvalidNumber = errors.New("invalid block number")
+
+	// ErrInvalidTerminalBlock dikembalikan jika blok tidak sesuai dengan terminal
+	// total difficulty.
+	ErrInvalidTerminalBlock = errors.New("invalid terminal block")
+
+	// ErrInvalidDifficulty dikembalikan jika kesulitan blok tidak sesuai dengan
+	// aturan mesin konsensus.
+	ErrInvalidDifficulty = errors.New("invalid difficulty")
+
+	// ErrInvalidTimestamp dikembalikan jika stempel waktu blok tidak sesuai dengan
+	// stempel waktu induknya.
+	ErrInvalidTimestamp = errors.New("invalid timestamp")
+
+	// ErrInvalidMixDigest dikembalikan jika mix digest blok tidak sesuai dengan
+	// aturan mesin konsensus.
+	ErrInvalidMixDigest = errors.New("invalid mix digest")
+
+	// ErrInvalidUncleHash dikembalikan jika uncle hash blok tidak sesuai dengan
+	// aturan mesin konsensus, misalnya tidak kosong pada mesin tanpa paman.
+	ErrInvalidUncleHash = errors.New("invalid uncle hash")
+
+	// ErrTooManyUncles dikembalikan jika blok berisi paman melebihi batas mesin
+	// konsensus, termasuk paman apa pun pada mesin yang tidak mengizinkannya.
+	ErrTooManyUncles = errors.New("too many uncles")
+)
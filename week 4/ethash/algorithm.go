@@ -0,0 +1,313 @@
+package ethash
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/big"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/bitutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	datasetInitBytes   = 1 << 30 // Ukuran dataset dalam byte pada genesis
+	datasetGrowthBytes = 1 << 23 // Pertumbuhan dataset per epoch
+	cacheInitBytes     = 1 << 24 // Ukuran cache dalam byte pada genesis
+	cacheGrowthBytes   = 1 << 17 // Pertumbuhan cache per epoch
+	epochLength        = 30000   // Jumlah blok per epoch
+	mixBytes           = 128     // Lebar mix
+	hashBytes          = 64      // Panjang hash dalam byte
+	hashWords          = 16      // Jumlah int 32 bit dalam satu hash
+	datasetParents     = 256     // Jumlah induk dari setiap elemen dataset
+	cacheRounds        = 3       // Jumlah putaran dalam pembuatan cache
+	loopAccesses       = 64      // Jumlah akses dalam loop hashimoto
+)
+
+// cacheSize mengembalikan ukuran cache verifikasi ethash untuk nomor blok tertentu.
+// Ukuran cache tumbuh linear, tetapi kita selalu mengambil bilangan prima tertinggi
+// di bawah ambang batas untuk mengurangi risiko keteraturan yang menyebabkan siklus.
+func cacheSize(block uint64) uint64 {
+	epoch := block / epochLength
+
+	size := cacheInitBytes + cacheGrowthBytes*epoch - hashBytes
+	for !new(big.Int).SetUint64(size / hashBytes).ProbablyPrime(1) { // Selalu akurat untuk n < 2^64
+		size -= 2 * hashBytes
+	}
+	return size
+}
+
+// datasetSize mengembalikan ukuran dataset penambangan ethash untuk nomor blok tertentu.
+// Aturan bilangan prima yang sama dengan cacheSize juga berlaku di sini.
+func datasetSize(block uint64) uint64 {
+	epoch := block / epochLength
+
+	size := datasetInitBytes + datasetGrowthBytes*epoch - mixBytes
+	for !new(big.Int).SetUint64(size / mixBytes).ProbablyPrime(1) { // Selalu akurat untuk n < 2^64
+		size -= 2 * mixBytes
+	}
+	return size
+}
+
+// hasher adalah fungsi hash berulang yang memakai ulang struktur data hash yang
+// sama di antara pemanggilan, alih-alih membuat yang baru setiap kali.
+type hasher func(dest []byte, data []byte)
+
+// makeHasher membuat hasher berulang dari hash.Hash yang diberikan. Fungsi yang
+// dikembalikan tidak aman dipakai bersamaan oleh beberapa goroutine!
+func makeHasher(h hash.Hash) hasher {
+	// sha3.state mendukung Read untuk mengambil hasil, gunakan itu untuk menghindari
+	// overhead dari Sum. Read mengubah state, tetapi hash selalu direset sebelum dipakai.
+	type readerHash interface {
+		hash.Hash
+		Read([]byte) (int, error)
+	}
+	rh, ok := h.(readerHash)
+	if !ok {
+		panic("can't find Read method on hash")
+	}
+	outputLen := rh.Size()
+	return func(dest []byte, data []byte) {
+		rh.Reset()
+		rh.Write(data)
+		rh.Read(dest[:outputLen])
+	}
+}
+
+// seedHash adalah seed yang dipakai untuk membuat cache verifikasi dan dataset
+// penambangan.
+func seedHash(block uint64) []byte {
+	seed := make([]byte, 32)
+	if block < epochLength {
+		return seed
+	}
+	keccak256 := makeHasher(sha3.NewLegacyKeccak256())
+	for i := 0; i < int(block/epochLength); i++ {
+		keccak256(seed, seed)
+	}
+	return seed
+}
+
+// generateCache membuat cache verifikasi dengan ukuran tertentu untuk seed yang
+// diberikan. Prosesnya mengisi memori secara berurutan terlebih dahulu, lalu
+// menjalankan beberapa putaran algoritma RandMemoHash milik Sergio Demian Lerner.
+// Hasilnya ditulis ke dest sebagai word 32 bit little-endian.
+func generateCache(dest []uint32, epoch uint64, seed []byte) {
+	logger := log.New("epoch", epoch)
+
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+
+		logFn := logger.Debug
+		if elapsed > 3*time.Second {
+			logFn = logger.Info
+		}
+		logFn("Generated ethash verification cache", "elapsed", common.PrettyDuration(elapsed))
+	}()
+	// Hitung jumlah baris teoretis (tetap disimpan dalam satu buffer)
+	cache := make([]byte, len(dest)*4)
+	size := uint64(len(cache))
+	rows := int(size) / hashBytes
+
+	// Buat hasher untuk dipakai ulang di antara pemanggilan
+	keccak512 := makeHasher(sha3.NewLegacyKeccak512())
+
+	// Hasilkan dataset awal secara berurutan
+	keccak512(cache, seed)
+	for offset := uint64(hashBytes); offset < size; offset += hashBytes {
+		keccak512(cache[offset:], cache[offset-hashBytes:offset])
+	}
+	// Gunakan randmemohash versi putaran rendah
+	temp := make([]byte, hashBytes)
+
+	for i := 0; i < cacheRounds; i++ {
+		for j := 0; j < rows; j++ {
+			var (
+				srcOff = ((j - 1 + rows) % rows) * hashBytes
+				dstOff = j * hashBytes
+				xorOff = (binary.LittleEndian.Uint32(cache[dstOff:]) % uint32(rows)) * hashBytes
+			)
+			bitutil.XORBytes(temp, cache[srcOff:srcOff+hashBytes], cache[xorOff:xorOff+hashBytes])
+			keccak512(cache[dstOff:], temp)
+		}
+	}
+	// Ubah buffer byte menjadi word 32 bit
+	for i := range dest {
+		dest[i] = binary.LittleEndian.Uint32(cache[i*4:])
+	}
+}
+
+// fnv adalah algoritma yang terinspirasi dari hash FNV, yang dalam beberapa kasus
+// dipakai sebagai pengganti XOR yang tidak asosiatif. Perhatikan bahwa bilangan
+// prima dikalikan dengan seluruh input 32 bit, berbeda dengan spesifikasi FNV-1.
+func fnv(a, b uint32) uint32 {
+	return a*0x01000193 ^ b
+}
+
+// fnvHash mencampur data ke dalam mix menggunakan metode fnv ethash.
+func fnvHash(mix []uint32, data []uint32) {
+	for i := 0; i < len(mix); i++ {
+		mix[i] = mix[i]*0x01000193 ^ data[i]
+	}
+}
+
+// generateDatasetItem menggabungkan data dari 256 node cache yang dipilih secara
+// pseudo-acak, lalu melakukan hash untuk menghasilkan satu node dataset.
+func generateDatasetItem(cache []uint32, index uint32, keccak512 hasher) []byte {
+	// Hitung jumlah baris teoretis (tetap memakai satu buffer)
+	rows := uint32(len(cache) / hashWords)
+
+	// Inisialisasi mix
+	mix := make([]byte, hashBytes)
+
+	binary.LittleEndian.PutUint32(mix, cache[(index%rows)*hashWords]^index)
+	for i := 1; i < hashWords; i++ {
+		binary.LittleEndian.PutUint32(mix[i*4:], cache[(index%rows)*hashWords+uint32(i)])
+	}
+	keccak512(mix, mix)
+
+	// Ubah mix menjadi uint32 agar tidak perlu terus menggeser bit
+	intMix := make([]uint32, hashWords)
+	for i := 0; i < len(intMix); i++ {
+		intMix[i] = binary.LittleEndian.Uint32(mix[i*4:])
+	}
+	// Campur dengan banyak node cache acak berdasarkan indeks
+	for i := uint32(0); i < datasetParents; i++ {
+		parent := fnv(index^i, intMix[i%16]) % rows
+		fnvHash(intMix, cache[parent*hashWords:])
+	}
+	// Ratakan mix uint32 kembali menjadi biner dan kembalikan
+	for i, val := range intMix {
+		binary.LittleEndian.PutUint32(mix[i*4:], val)
+	}
+	keccak512(mix, mix)
+	return mix
+}
+
+// generateDataset membuat seluruh dataset ethash untuk penambangan. Hasilnya
+// ditulis ke dest sebagai word 32 bit little-endian.
+func generateDataset(dest []uint32, epoch uint64, cache []uint32) {
+	logger := log.New("epoch", epoch)
+
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+
+		logFn := logger.Debug
+		if elapsed > 3*time.Second {
+			logFn = logger.Info
+		}
+		logFn("Generated ethash dataset", "elapsed", common.PrettyDuration(elapsed))
+	}()
+	// Buat dataset di banyak goroutine karena prosesnya cukup lama
+	threads := runtime.NumCPU()
+	items := uint64(len(dest) / hashWords)
+
+	var pend sync.WaitGroup
+	pend.Add(threads)
+
+	var progress uint64
+	for i := 0; i < threads; i++ {
+		go func(id int) {
+			defer pend.Done()
+
+			// Buat hasher untuk dipakai ulang di antara pemanggilan
+			keccak512 := makeHasher(sha3.NewLegacyKeccak512())
+
+			// Hitung segmen data yang harus dibuat oleh thread ini
+			batch := (items + uint64(threads) - 1) / uint64(threads)
+			first := uint64(id) * batch
+			limit := first + batch
+			if limit > items {
+				limit = items
+			}
+			percent := items / 100
+			for index := first; index < limit; index++ {
+				item := generateDatasetItem(cache, uint32(index), keccak512)
+				for j := 0; j < hashWords; j++ {
+					dest[index*hashWords+uint64(j)] = binary.LittleEndian.Uint32(item[j*4:])
+				}
+				if status := atomic.AddUint64(&progress, 1); percent > 0 && status%percent == 0 {
+					logger.Info("Generating DAG in progress", "percentage", (status*100)/items, "elapsed", common.PrettyDuration(time.Since(start)))
+				}
+			}
+		}(i)
+	}
+	// Tunggu semua generator selesai
+	pend.Wait()
+}
+
+// hashimoto mengumpulkan data dari dataset penuh untuk menghasilkan nilai akhir
+// bagi hash header dan nonce tertentu.
+func hashimoto(hash []byte, nonce uint64, size uint64, lookup func(index uint32) []uint32) ([]byte, []byte) {
+	// Hitung jumlah baris teoretis (tetap memakai satu buffer)
+	rows := uint32(size / mixBytes)
+
+	// Gabungkan header+nonce menjadi seed 64 byte
+	seed := make([]byte, 40)
+	copy(seed, hash)
+	binary.LittleEndian.PutUint64(seed[32:], nonce)
+
+	seed = crypto.Keccak512(seed)
+	seedHead := binary.LittleEndian.Uint32(seed)
+
+	// Mulai mix dengan seed yang direplikasi
+	mix := make([]uint32, mixBytes/4)
+	for i := 0; i < len(mix); i++ {
+		mix[i] = binary.LittleEndian.Uint32(seed[i%16*4:])
+	}
+	// Campurkan node dataset acak
+	temp := make([]uint32, len(mix))
+
+	for i := 0; i < loopAccesses; i++ {
+		parent := fnv(uint32(i)^seedHead, mix[i%len(mix)]) % rows
+		for j := uint32(0); j < mixBytes/hashBytes; j++ {
+			copy(temp[j*hashWords:], lookup(2*parent+j))
+		}
+		fnvHash(mix, temp)
+	}
+	// Padatkan mix
+	for i := 0; i < len(mix); i += 4 {
+		mix[i/4] = fnv(fnv(fnv(mix[i], mix[i+1]), mix[i+2]), mix[i+3])
+	}
+	mix = mix[:len(mix)/4]
+
+	digest := make([]byte, common.HashLength)
+	for i, val := range mix {
+		binary.LittleEndian.PutUint32(digest[i*4:], val)
+	}
+	return digest, crypto.Keccak256(append(seed, digest...))
+}
+
+// hashimotoLight menghitung nilai hashimoto hanya dengan cache kecil di memori,
+// membuat ulang item dataset yang dibutuhkan sesuai permintaan.
+func hashimotoLight(size uint64, cache []uint32, hash []byte, nonce uint64) ([]byte, []byte) {
+	keccak512 := makeHasher(sha3.NewLegacyKeccak512())
+
+	lookup := func(index uint32) []uint32 {
+		rawData := generateDatasetItem(cache, index, keccak512)
+
+		data := make([]uint32, len(rawData)/4)
+		for i := 0; i < len(data); i++ {
+			data[i] = binary.LittleEndian.Uint32(rawData[i*4:])
+		}
+		return data
+	}
+	return hashimoto(hash, nonce, size, lookup)
+}
+
+// hashimotoFull menghitung nilai hashimoto menggunakan dataset penuh di memori.
+func hashimotoFull(dataset []uint32, hash []byte, nonce uint64) ([]byte, []byte) {
+	lookup := func(index uint32) []uint32 {
+		offset := index * hashWords
+		return dataset[offset : offset+hashWords]
+	}
+	return hashimoto(hash, nonce, uint64(len(dataset))*4, lookup)
+}
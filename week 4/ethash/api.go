@@ -0,0 +1,78 @@
+package ethash
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var errEthashStopped = errors.New("ethash stopped")
+
+// API adalah API RPC ethash untuk penambang eksternal.
+type API struct {
+	ethash *Ethash
+}
+
+// GetWork mengembalikan paket pekerjaan untuk penambang eksternal.
+//
+// Paket pekerjaan terdiri dari 4 string:
+//
+//	result[0] - 32 byte hex seal hash header blok saat ini
+//	result[1] - 32 byte hex seed hash yang dipakai untuk DAG
+//	result[2] - 32 byte hex batas target, yaitu 2^256/difficulty
+//	result[3] - nomor blok dalam hex
+func (api *API) GetWork() ([4]string, error) {
+	if api.ethash.remote == nil {
+		return [4]string{}, errors.New("not supported")
+	}
+	var (
+		workCh = make(chan [4]string, 1)
+		errc   = make(chan error, 1)
+	)
+	select {
+	case api.ethash.remote.fetchWorkCh <- &sealWork{errc: errc, res: workCh}:
+	case <-api.ethash.remote.exitCh:
+		return [4]string{}, errEthashStopped
+	}
+	select {
+	case work := <-workCh:
+		return work, nil
+	case err := <-errc:
+		return [4]string{}, err
+	}
+}
+
+// SubmitWork dipakai penambang eksternal untuk mengirim solusi PoW. Metode ini
+// mengembalikan apakah solusi diterima; solusi yang salah, basi, atau untuk pekerjaan
+// yang tidak dikenal sama-sama menghasilkan false.
+func (api *API) SubmitWork(nonce types.BlockNonce, hash, digest common.Hash) bool {
+	if api.ethash.remote == nil {
+		return false
+	}
+	errc := make(chan error, 1)
+	select {
+	case api.ethash.remote.submitWorkCh <- &mineResult{
+		nonce:     nonce,
+		mixDigest: digest,
+		hash:      hash,
+		errc:      errc,
+	}:
+	case <-api.ethash.remote.exitCh:
+		return false
+	}
+	return <-errc == nil
+}
+
+// SubmitHashrate dipakai penambang remote untuk melaporkan hashrate-nya, sehingga
+// node bisa melaporkan gabungan hashrate semua penambang yang bekerja lewat node ini.
+// Identitas id harus unik di antara penambang.
+func (api *API) SubmitHashrate(rate hexutil.Uint64, id common.Hash) bool {
+	return api.ethash.SubmitHashrate(id, uint64(rate))
+}
+
+// GetHashrate mengembalikan hashrate gabungan penambang lokal dan remote.
+func (api *API) GetHashrate() uint64 {
+	return uint64(api.ethash.Hashrate())
+}
@@ -0,0 +1,229 @@
+package ethash
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	hashrateWindow       = 128  // Jumlah blok terbaru untuk memperkirakan hashrate jaringan
+	maxConfirmationDepth = 1024 // Kedalaman maksimum yang dicari oleh GetConfirmationDepth
+)
+
+// Berbagai pesan error untuk perkiraan konfirmasi.
+var (
+	errUnknownBlock      = errors.New("unknown block")
+	errNotCanonical      = errors.New("block not canonical")
+	errInvalidRisk       = errors.New("risk must be between 0 and 1")
+	errAttackerMajority  = errors.New("attacker controls the majority of hashrate")
+	errDepthExceeded     = errors.New("risk not reachable within maximum confirmation depth")
+	errNoHashrateHistory = errors.New("not enough blocks to estimate network hashrate")
+)
+
+// confirmedPollInterval adalah jeda pemeriksaan head untuk langganan Confirmed.
+var confirmedPollInterval = time.Second
+
+// ConfirmationAPI adalah API RPC ethash untuk perkiraan konfirmasi blok. API ini
+// terpisah dari API penambang agar hanya tersedia di namespace ethash.
+type ConfirmationAPI struct {
+	chain consensus.ChainHeaderReader
+}
+
+// ConfirmationRisk adalah perkiraan peluang sebuah blok kanonik tergeser oleh reorg.
+type ConfirmationRisk struct {
+	Number          uint64  `json:"number"`          // Nomor blok yang diperkirakan
+	Depth           uint64  `json:"depth"`           // Jumlah blok kanonik di atas blok tersebut
+	NetworkHashrate float64 `json:"networkHashrate"` // Perkiraan hashrate jaringan dalam hash per detik
+	AttackerShare   float64 `json:"attackerShare"`   // Bagian hashrate penyerang dari total hashrate
+	Probability     float64 `json:"probability"`     // Peluang penyerang menyusul rantai kanonik
+}
+
+// reorgProbability menghitung peluang penyerang dengan bagian hashrate q berhasil
+// menyusul rantai jujur yang sudah unggul depth blok, memakai rumus pada bagian 11
+// whitepaper Bitcoin. Suku Poisson dihitung dalam skala log agar tidak underflow
+// untuk kedalaman besar.
+func reorgProbability(q float64, depth uint64) float64 {
+	// Blok tanpa konfirmasi selalu bisa tergeser, dan tanpa penyerang tidak ada reorg
+	if depth == 0 {
+		return 1
+	}
+	if q <= 0 {
+		return 0
+	}
+	p := 1 - q
+	if q >= p {
+		return 1
+	}
+	var (
+		z      = float64(depth)
+		lambda = z * q / p
+		ratio  = math.Log(q / p)
+		sum    = 1.0
+	)
+	for k := uint64(0); k <= depth; k++ {
+		lgamma, _ := math.Lgamma(float64(k) + 1)
+		poisson := math.Exp(-lambda + float64(k)*math.Log(lambda) - lgamma)
+		sum -= poisson * (1 - math.Exp((z-float64(k))*ratio))
+	}
+	return math.Min(math.Max(sum, 0), 1)
+}
+
+// attackerShare mengembalikan bagian hashrate penyerang dari total hashrate jaringan
+// dan penyerang.
+func attackerShare(attacker, network float64) float64 {
+	if attacker <= 0 {
+		return 0
+	}
+	return attacker / (attacker + network)
+}
+
+// networkHashrate memperkirakan hashrate jaringan dari kesulitan dan stempel waktu
+// hingga hashrateWindow blok kanonik terakhir sampai head. Setiap blok membutuhkan
+// rata-rata sebanyak kesulitannya dalam hash, jadi total kesulitan dibagi rentang
+// waktunya memberikan hash per detik.
+func networkHashrate(chain consensus.ChainHeaderReader, head *types.Header) (float64, error) {
+	number := head.Number.Uint64()
+	if number == 0 {
+		return 0, errNoHashrateHistory
+	}
+	window := uint64(hashrateWindow)
+	if number < window {
+		window = number
+	}
+	var (
+		work   = new(big.Int)
+		header = head
+	)
+	for i := uint64(0); i < window; i++ {
+		work.Add(work, header.Difficulty)
+		if header = chain.GetHeader(header.ParentHash, header.Number.Uint64()-1); header == nil {
+			return 0, consensus.ErrUnknownAncestor
+		}
+	}
+	span := head.Time - header.Time
+	if span == 0 {
+		span = 1
+	}
+	rate, _ := new(big.Float).Quo(new(big.Float).SetInt(work), new(big.Float).SetUint64(span)).Float64()
+	return rate, nil
+}
+
+// confirmedHeaders mengembalikan header kanonik mulai dari nomor next yang sudah
+// memiliki setidaknya depth blok di atasnya, beserta nomor berikutnya yang belum
+// dikembalikan.
+func confirmedHeaders(chain consensus.ChainHeaderReader, next, depth uint64) ([]*types.Header, uint64) {
+	head := chain.CurrentHeader().Number.Uint64()
+	if head < depth {
+		return nil, next
+	}
+	var headers []*types.Header
+	for ; next <= head-depth; next++ {
+		header := chain.GetHeaderByNumber(next)
+		if header == nil {
+			break
+		}
+		headers = append(headers, header)
+	}
+	return headers, next
+}
+
+// GetConfirmationRisk memperkirakan peluang blok kanonik dengan hash tertentu
+// tergeser oleh penyerang dengan hashrate attacker (hash per detik). Hashrate
+// jaringan diperkirakan dari blok terbaru, dan kedalaman blok dihitung dari head
+// saat ini.
+func (api *ConfirmationAPI) GetConfirmationRisk(hash common.Hash, attacker hexutil.Uint64) (*ConfirmationRisk, error) {
+	header := api.chain.GetHeaderByHash(hash)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	number := header.Number.Uint64()
+	if canonical := api.chain.GetHeaderByNumber(number); canonical == nil || canonical.Hash() != hash {
+		return nil, errNotCanonical
+	}
+	head := api.chain.CurrentHeader()
+	network, err := networkHashrate(api.chain, head)
+	if err != nil {
+		return nil, err
+	}
+	risk := &ConfirmationRisk{
+		Number:          number,
+		Depth:           head.Number.Uint64() - number,
+		NetworkHashrate: network,
+		AttackerShare:   attackerShare(float64(attacker), network),
+	}
+	risk.Probability = reorgProbability(risk.AttackerShare, risk.Depth)
+	return risk, nil
+}
+
+// GetConfirmationDepth mengembalikan jumlah konfirmasi minimum agar peluang reorg
+// oleh penyerang dengan hashrate attacker (hash per detik) berada di bawah risk,
+// memakai perkiraan hashrate jaringan dari blok terbaru.
+func (api *ConfirmationAPI) GetConfirmationDepth(attacker hexutil.Uint64, risk float64) (hexutil.Uint64, error) {
+	if risk <= 0 || risk >= 1 {
+		return 0, errInvalidRisk
+	}
+	network, err := networkHashrate(api.chain, api.chain.CurrentHeader())
+	if err != nil {
+		return 0, err
+	}
+	q := attackerShare(float64(attacker), network)
+	if q >= 0.5 {
+		return 0, errAttackerMajority
+	}
+	for depth := uint64(0); depth <= maxConfirmationDepth; depth++ {
+		if reorgProbability(q, depth) < risk {
+			return hexutil.Uint64(depth), nil
+		}
+	}
+	return 0, errDepthExceeded
+}
+
+// Confirmed membuat langganan (ethash_subscribe "confirmed") yang mengirim setiap
+// header kanonik begitu memiliki setidaknya depth blok di atasnya. Hanya blok yang
+// terkonfirmasi setelah langganan dibuat yang dikirim, masing-masing satu kali,
+// sesuai urutan nomor. Reorg yang lebih dalam dari depth tidak mengirim ulang blok
+// yang sudah dikirim; pilih depth dengan GetConfirmationDepth agar peluangnya kecil.
+//
+// ChainHeaderReader tidak menyediakan feed head baru, jadi langganan memeriksa head
+// setiap confirmedPollInterval dan blok bisa terkirim hingga satu interval setelah
+// terkonfirmasi.
+func (api *ConfirmationAPI) Confirmed(ctx context.Context, depth hexutil.Uint64) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	var (
+		sub  = notifier.CreateSubscription()
+		next = api.chain.CurrentHeader().Number.Uint64() + 1
+	)
+	if head := next - 1; head >= uint64(depth) {
+		next = head - uint64(depth) + 1
+	}
+	go func() {
+		ticker := time.NewTicker(confirmedPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				var headers []*types.Header
+				headers, next = confirmedHeaders(api.chain, next, uint64(depth))
+				for _, header := range headers {
+					notifier.Notify(sub.ID, header)
+				}
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
@@ -0,0 +1,197 @@
+package ethash
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/consensustest"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// newConfirmationChain membuat rantai di memori dengan 20 header berkesulitan 900
+// dan berjarak 10 detik, sehingga hashrate jaringan tepat 90 hash per detik.
+func newConfirmationChain(t *testing.T) (*consensustest.HeaderChain, []*types.Header) {
+	t.Helper()
+
+	genesis := &types.Header{
+		Number:     big.NewInt(0),
+		Difficulty: big.NewInt(900),
+		GasLimit:   params.GenesisGasLimit,
+		BaseFee:    big.NewInt(params.InitialBaseFee),
+		UncleHash:  types.EmptyUncleHash,
+	}
+	chain := consensustest.NewHeaderChain(params.AllEthashProtocolChanges, genesis)
+	headers, err := chain.Extend(20, spacedHeader)
+	if err != nil {
+		t.Fatalf("failed to extend chain: %v", err)
+	}
+	return chain, headers
+}
+
+// spacedHeader mengisi header dengan kesulitan 900 dan jarak 10 detik dari induk.
+func spacedHeader(i int, header *types.Header) {
+	header.Difficulty = big.NewInt(900)
+	header.Time += 9
+}
+
+// Menguji peluang reorg terhadap tabel pada bagian 11 whitepaper Bitcoin.
+func TestReorgProbability(t *testing.T) {
+	tests := []struct {
+		q     float64
+		depth uint64
+		want  float64
+	}{
+		{0.1, 0, 1},
+		{0.1, 1, 0.2045873},
+		{0.1, 2, 0.0509779},
+		{0.1, 5, 0.0009137},
+		{0.1, 10, 0.0000012},
+		{0.3, 5, 0.1773523},
+		{0.3, 10, 0.0416605},
+		{0.3, 50, 0.0000006},
+		{0, 1, 0},
+		{0.5, 100, 1},
+	}
+	for _, test := range tests {
+		if have := reorgProbability(test.q, test.depth); math.Abs(have-test.want) > 1e-7 {
+			t.Errorf("q %v depth %d: probability mismatch: have %v, want %v", test.q, test.depth, have, test.want)
+		}
+	}
+}
+
+// Menguji perkiraan risiko untuk blok kanonik, blok di cabang samping dan blok
+// yang tidak dikenal.
+func TestConfirmationRisk(t *testing.T) {
+	chain, headers := newConfirmationChain(t)
+	api := &ConfirmationAPI{chain: chain}
+
+	risk, err := api.GetConfirmationRisk(headers[14].Hash(), 10)
+	if err != nil {
+		t.Fatalf("failed to estimate risk: %v", err)
+	}
+	if risk.Number != 15 || risk.Depth != 5 {
+		t.Errorf("position mismatch: have %d/%d, want %d/%d", risk.Number, risk.Depth, 15, 5)
+	}
+	if risk.NetworkHashrate != 90 {
+		t.Errorf("network hashrate mismatch: have %v, want %v", risk.NetworkHashrate, 90)
+	}
+	if math.Abs(risk.AttackerShare-0.1) > 1e-12 {
+		t.Errorf("attacker share mismatch: have %v, want %v", risk.AttackerShare, 0.1)
+	}
+	if math.Abs(risk.Probability-0.0009137) > 1e-7 {
+		t.Errorf("probability mismatch: have %v, want %v", risk.Probability, 0.0009137)
+	}
+	side, err := chain.Fork(headers[13].Hash(), 1, func(i int, header *types.Header) {
+		header.Extra = []byte("side")
+	})
+	if err != nil {
+		t.Fatalf("failed to fork chain: %v", err)
+	}
+	if _, err := api.GetConfirmationRisk(side[0].Hash(), 10); !errors.Is(err, errNotCanonical) {
+		t.Errorf("side block error mismatch: have %v, want %v", err, errNotCanonical)
+	}
+	if _, err := api.GetConfirmationRisk(common.Hash{1}, 10); !errors.Is(err, errUnknownBlock) {
+		t.Errorf("unknown block error mismatch: have %v, want %v", err, errUnknownBlock)
+	}
+}
+
+// Menguji kedalaman konfirmasi minimum dan penolakan parameter yang tidak masuk akal.
+func TestConfirmationDepth(t *testing.T) {
+	chain, _ := newConfirmationChain(t)
+	api := &ConfirmationAPI{chain: chain}
+
+	tests := []struct {
+		name     string
+		attacker hexutil.Uint64
+		risk     float64
+		depth    uint64
+		want     error
+	}{
+		{"whitepaper 10%", 10, 0.001, 5, nil},
+		{"no attacker", 0, 0.001, 1, nil},
+		{"zero risk", 10, 0, 0, errInvalidRisk},
+		{"certain risk", 10, 1, 0, errInvalidRisk},
+		{"majority", 90, 0.001, 0, errAttackerMajority},
+		{"unreachable", 89, 1e-12, 0, errDepthExceeded},
+	}
+	for _, test := range tests {
+		depth, err := api.GetConfirmationDepth(test.attacker, test.risk)
+		if !errors.Is(err, test.want) {
+			t.Errorf("%s: error mismatch: have %v, want %v", test.name, err, test.want)
+			continue
+		}
+		if uint64(depth) != test.depth {
+			t.Errorf("%s: depth mismatch: have %d, want %d", test.name, depth, test.depth)
+		}
+	}
+}
+
+// Menguji bahwa langganan confirmed mengirim setiap blok tepat satu kali, sesuai
+// urutan, begitu blok tersebut mencapai kedalaman yang diminta.
+func TestConfirmedSubscription(t *testing.T) {
+	interval := confirmedPollInterval
+	confirmedPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { confirmedPollInterval = interval })
+
+	chain, _ := newConfirmationChain(t)
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("ethash", &ConfirmationAPI{chain: chain}); err != nil {
+		t.Fatalf("failed to register api: %v", err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	confirmed := make(chan *types.Header)
+	sub, err := client.Subscribe(context.Background(), "ethash", confirmed, "confirmed", hexutil.Uint64(2))
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	if _, err := chain.Extend(3, spacedHeader); err != nil {
+		t.Fatalf("failed to extend chain: %v", err)
+	}
+	for _, want := range []uint64{19, 20, 21} {
+		select {
+		case header := <-confirmed:
+			if have := header.Number.Uint64(); have != want {
+				t.Fatalf("confirmed block mismatch: have %d, want %d", have, want)
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("block %d: confirmation timeout", want)
+		}
+	}
+	select {
+	case header := <-confirmed:
+		t.Errorf("unexpected confirmation of block %d", header.Number.Uint64())
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// Menguji bahwa API konfirmasi hanya didaftarkan di namespace ethash.
+func TestConfirmationNamespace(t *testing.T) {
+	engine := NewFaker()
+	defer engine.Close()
+
+	chain, _ := newConfirmationChain(t)
+	var namespaces []string
+	for _, api := range engine.APIs(chain) {
+		if _, ok := api.Service.(*ConfirmationAPI); ok {
+			namespaces = append(namespaces, api.Namespace)
+		}
+	}
+	if len(namespaces) != 1 || namespaces[0] != "ethash" {
+		t.Errorf("confirmation namespaces mismatch: have %v, want [ethash]", namespaces)
+	}
+}
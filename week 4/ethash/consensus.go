@@ -0,0 +1,553 @@
+package ethash
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"golang.org/x/crypto/sha3"
+)
+
+// Konstanta protokol proof-of-work ethash.
+var (
+	FrontierBlockReward           = big.NewInt(5e+18) // Block reward dalam wei untuk blok yang berhasil ditambang
+	ByzantiumBlockReward          = big.NewInt(3e+18) // Block reward dalam wei mulai dari Byzantium
+	ConstantinopleBlockReward     = big.NewInt(2e+18) // Block reward dalam wei mulai dari Constantinople
+	maxUncles                     = 2                 // Jumlah maksimum paman dalam satu blok
+	maxUncleDepth                 = 7                 // Jarak maksimum paman dari blok yang memasukkannya
+	uncleRewardDenominator        = uint64(8)         // Pembagi reward pembuat paman
+	inclusionRewardDenominator    = uint64(32)        // Pembagi reward untuk memasukkan paman
+	allowedFutureBlockTimeSeconds = int64(15)         // Batas detik ke depan dari waktu sekarang sebelum blok dianggap dari masa depan
+
+	// calcDifficultyEip5133 menggeser bom kesulitan sejauh total 11,4 juta blok (EIP-5133).
+	calcDifficultyEip5133 = makeDifficultyCalculator(big.NewInt(11_400_000))
+
+	// calcDifficultyEip4345 menggeser bom kesulitan sejauh total 10,7 juta blok (EIP-4345).
+	calcDifficultyEip4345 = makeDifficultyCalculator(big.NewInt(10_700_000))
+
+	// calcDifficultyEip3554 menggeser bom kesulitan sejauh total 9,7 juta blok (EIP-3554).
+	calcDifficultyEip3554 = makeDifficultyCalculator(big.NewInt(9_700_000))
+
+	// calcDifficultyEip2384 menggeser bom kesulitan sejauh total 9 juta blok (EIP-2384).
+	calcDifficultyEip2384 = makeDifficultyCalculator(big.NewInt(9_000_000))
+
+	// calcDifficultyConstantinople memakai aturan Byzantium dengan bom digeser 5 juta blok (EIP-1234).
+	calcDifficultyConstantinople = makeDifficultyCalculator(big.NewInt(5_000_000))
+
+	// calcDifficultyByzantium memakai aturan Byzantium dengan bom digeser 3 juta blok (EIP-649).
+	calcDifficultyByzantium = makeDifficultyCalculator(big.NewInt(3_000_000))
+)
+
+// Berbagai pesan error untuk menandai blok tidak valid. Error ini sengaja dibuat
+// privat agar bagian lain dari kode tidak bergantung pada error spesifik mesin ini.
+var (
+	errOlderBlockTime  = fmt.Errorf("%w: older than parent", consensus.ErrInvalidTimestamp)
+	errDuplicateUncle  = errors.New("duplicate uncle")
+	errUncleIsAncestor = errors.New("uncle is ancestor")
+	errDanglingUncle   = errors.New("uncle's parent is not ancestor")
+	errInvalidPoW      = errors.New("invalid proof-of-work")
+)
+
+// Author mengimplementasikan consensus.Engine, mengembalikan coinbase header
+// sebagai penulis blok yang diverifikasi oleh proof-of-work.
+func (ethash *Ethash) Author(header *types.Header) (common.Address, error) {
+	return header.Coinbase, nil
+}
+
+// VerifyHeader memeriksa apakah header sesuai dengan aturan konsensus mesin
+// ethash Ethereum.
+func (ethash *Ethash) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, seal bool) error {
+	// Lewati jika header sudah dikenal, atau induknya tidak dikenal
+	number := header.Number.Uint64()
+	if chain.GetHeader(header.Hash(), number) != nil {
+		return nil
+	}
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	// Pemeriksaan awal lolos, lakukan verifikasi lengkap
+	return ethash.verifyHeader(chain, header, parent, false, seal, time.Now().Unix())
+}
+
+// VerifyHeaders mirip dengan VerifyHeader, tetapi memverifikasi sekumpulan header
+// secara bersamaan. Metode mengembalikan saluran keluar untuk membatalkan operasi
+// dan saluran hasil untuk mengambil verifikasi asinkron.
+func (ethash *Ethash) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	unixNow := time.Now().Unix()
+	return consensus.VerifyParallel(len(headers), ethash.config.VerifyWorkers, func(index int) error {
+		return ethash.verifyHeaderWorker(chain, headers, seals, index, unixNow)
+	})
+}
+
+// verifyHeaderWorker memverifikasi satu header dari batch, mengambil induknya dari
+// batch itu sendiri jika memungkinkan.
+func (ethash *Ethash) verifyHeaderWorker(chain consensus.ChainHeaderReader, headers []*types.Header, seals []bool, index int, unixNow int64) error {
+	var parent *types.Header
+	if index == 0 {
+		parent = chain.GetHeader(headers[0].ParentHash, headers[0].Number.Uint64()-1)
+	} else if headers[index-1].Hash() == headers[index].ParentHash {
+		parent = headers[index-1]
+	}
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	return ethash.verifyHeader(chain, headers[index], parent, false, seals[index], unixNow)
+}
+
+// VerifyUncles memverifikasi bahwa paman dari blok yang diberikan sesuai dengan
+// aturan konsensus mesin ethash Ethereum.
+func (ethash *Ethash) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
+	if ethash.rulesErr != nil {
+		return ethash.rulesErr
+	}
+	// Pastikan jumlah paman dalam blok ini tidak melebihi batas
+	policy := ethash.rules.unclesAt(block.NumberU64())
+	if len(block.Uncles()) > policy.maxUncles {
+		return consensus.ErrTooManyUncles
+	}
+	if len(block.Uncles()) == 0 {
+		return nil
+	}
+	// Kumpulkan paman dari leluhur sebelumnya
+	uncles := make(map[common.Hash]struct{})
+
+	number, parent := block.NumberU64()-1, block.ParentHash()
+	for i := 0; i < policy.maxDepth; i++ {
+		ancestorHeader := chain.GetHeader(parent, number)
+		if ancestorHeader == nil {
+			break
+		}
+		// Jika leluhur tidak punya paman, kita tidak perlu mengiterasinya
+		if ancestorHeader.UncleHash != types.EmptyUncleHash {
+			// Paman leluhur juga harus masuk ke daftar terlarang
+			ancestor := chain.GetBlock(parent, number)
+			if ancestor == nil {
+				break
+			}
+			for _, uncle := range ancestor.Uncles() {
+				uncles[uncle.Hash()] = struct{}{}
+			}
+		}
+		if number == 0 {
+			break
+		}
+		parent, number = ancestorHeader.ParentHash, number-1
+	}
+	uncles[block.Hash()] = struct{}{}
+
+	// Verifikasi setiap paman masih baru, tetapi bukan leluhur. Garis keturunan
+	// diperiksa lewat indeks leluhur alih-alih menyimpan semua leluhur di memori.
+	for _, uncle := range block.Uncles() {
+		// Pastikan setiap paman hanya diberi reward sekali
+		hash := uncle.Hash()
+		if _, ok := uncles[hash]; ok {
+			return errDuplicateUncle
+		}
+		uncles[hash] = struct{}{}
+
+		// Pastikan paman memiliki garis keturunan yang valid
+		if uncle.Number == nil || uncle.Number.Sign() <= 0 || !uncle.Number.IsUint64() {
+			return errDanglingUncle
+		}
+		// Induk paman harus berada paling jauh MaxDepth blok di belakang, sehingga
+		// pencarian di indeks dibatasi jarak tersebut
+		blockNumber, parentNumber := block.NumberU64(), uncle.Number.Uint64()-1
+		if parentNumber >= blockNumber || parentNumber+uint64(policy.maxDepth) < blockNumber {
+			return errDanglingUncle
+		}
+		if parentNumber+1 < blockNumber && ethash.ancestors.IsAncestor(chain, hash, parentNumber+1, block.ParentHash(), blockNumber-1) {
+			return errUncleIsAncestor
+		}
+		if uncle.ParentHash == block.ParentHash() || !ethash.ancestors.IsAncestor(chain, uncle.ParentHash, parentNumber, block.ParentHash(), blockNumber-1) {
+			return errDanglingUncle
+		}
+		uncleParent := chain.GetHeader(uncle.ParentHash, parentNumber)
+		if uncleParent == nil {
+			return errDanglingUncle
+		}
+		if err := ethash.verifyHeader(chain, uncle, uncleParent, true, true, time.Now().Unix()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyHeader memeriksa apakah header sesuai dengan aturan konsensus mesin
+// ethash Ethereum. Lihat YP bagian 4.3.4. "Block Header Validity".
+func (ethash *Ethash) verifyHeader(chain consensus.ChainHeaderReader, header, parent *types.Header, uncle bool, seal bool, unixNow int64) error {
+	if ethash.rulesErr != nil {
+		return ethash.rulesErr
+	}
+	// Pastikan bagian extra-data header berukuran wajar
+	if uint64(len(header.Extra)) > params.MaximumExtraDataSize {
+		return fmt.Errorf("extra-data too long: %d > %d", len(header.Extra), params.MaximumExtraDataSize)
+	}
+	// Verifikasi stempel waktu header
+	if !uncle {
+		if header.Time > uint64(unixNow+allowedFutureBlockTimeSeconds) {
+			return consensus.ErrFutureBlock
+		}
+	}
+	if header.Time <= parent.Time {
+		return errOlderBlockTime
+	}
+	// Verifikasi kesulitan blok berdasarkan stempel waktu dan kesulitan induk
+	expected := ethash.CalcDifficulty(chain, header.Time, parent)
+
+	if expected.Cmp(header.Difficulty) != 0 {
+		return fmt.Errorf("%w: have %v, want %v", consensus.ErrInvalidDifficulty, header.Difficulty, expected)
+	}
+	// Verifikasi bahwa batas gas <= 2^63-1
+	if header.GasLimit > params.MaxGasLimit {
+		return fmt.Errorf("invalid gasLimit: have %v, max %v", header.GasLimit, params.MaxGasLimit)
+	}
+	// Verifikasi bahwa gas yang dipakai <= batas gas
+	if header.GasUsed > header.GasLimit {
+		return fmt.Errorf("invalid gasUsed: have %d, gasLimit %d", header.GasUsed, header.GasLimit)
+	}
+	// Verifikasi pemakaian gas blok dan (jika berlaku) base fee
+	if !chain.Config().IsLondon(header.Number) {
+		// BaseFee tidak boleh ada sebelum fork EIP-1559
+		if header.BaseFee != nil {
+			return fmt.Errorf("invalid baseFee before fork: have %d, expected 'nil'", header.BaseFee)
+		}
+		if err := misc.VerifyGaslimit(parent.GasLimit, header.GasLimit); err != nil {
+			return err
+		}
+	} else if err := misc.VerifyEip1559Header(chain.Config(), parent, header); err != nil {
+		// Verifikasi atribut EIP-1559 dari header
+		return err
+	}
+	// Verifikasi bahwa nomor blok adalah nomor induk + 1
+	if diff := new(big.Int).Sub(header.Number, parent.Number); diff.Cmp(big.NewInt(1)) != 0 {
+		return consensus.ErrInvalidNumber
+	}
+	// Verifikasi segel khusus mesin yang mengamankan blok
+	if seal {
+		if err := ethash.verifySeal(chain, header, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CalcDifficulty adalah algoritma penyesuaian kesulitan. Ini mengembalikan kesulitan
+// yang harus dimiliki oleh blok baru saat dibuat pada waktu tertentu berdasarkan
+// waktu dan kesulitan blok induk, memakai strategi yang aktif menurut aturan chain
+// mesin ini atau, sebelum jadwalnya dimulai, menurut jadwal fork di konfigurasi chain.
+func (ethash *Ethash) CalcDifficulty(chain consensus.ChainHeaderReader, time uint64, parent *types.Header) *big.Int {
+	return ethash.rules.difficultyAt(chain.Config(), new(big.Int).Add(parent.Number, big1))(time, parent)
+}
+
+// CalcDifficulty adalah algoritma penyesuaian kesulitan. Ini mengembalikan kesulitan
+// yang harus dimiliki oleh blok baru saat dibuat pada waktu tertentu berdasarkan
+// waktu dan kesulitan blok induk, menurut jadwal fork di konfigurasi chain.
+func CalcDifficulty(config *params.ChainConfig, time uint64, parent *types.Header) *big.Int {
+	return new(chainRules).difficultyAt(config, new(big.Int).Add(parent.Number, big1))(time, parent)
+}
+
+// Konstanta bilangan besar agar tidak terus dialokasikan ulang.
+var (
+	expDiffPeriod = big.NewInt(100000)
+	big1          = big.NewInt(1)
+	big2          = big.NewInt(2)
+	big9          = big.NewInt(9)
+	big10         = big.NewInt(10)
+	bigMinus99    = big.NewInt(-99)
+)
+
+// makeDifficultyCalculator membuat kalkulator kesulitan dengan jeda bom yang diberikan.
+// Kesulitan dihitung dengan aturan Byzantium, yang berbeda dari Homestead dalam cara
+// paman mempengaruhi perhitungan. Jeda nil berarti bom kesulitan dimatikan.
+func makeDifficultyCalculator(bombDelay *big.Int) DifficultyCalculator {
+	// Perhitungan di bawah melihat nomor induk, yang satu lebih kecil dari nomor blok,
+	// jadi jeda yang diberikan dikurangi satu
+	var bombDelayFromParent *big.Int
+	if bombDelay != nil {
+		bombDelayFromParent = new(big.Int).Sub(bombDelay, big1)
+	}
+	return func(time uint64, parent *types.Header) *big.Int {
+		// https://github.com/ethereum/EIPs/issues/100.
+		// algoritma:
+		// diff = (parent_diff +
+		//         (parent_diff / 2048 * max((2 if len(parent.uncles) else 1) - ((timestamp - parent.timestamp) // 9), -99))
+		//        ) + 2^(periodCount - 2)
+		bigTime := new(big.Int).SetUint64(time)
+		bigParentTime := new(big.Int).SetUint64(parent.Time)
+
+		// menampung nilai sementara agar algoritma mudah dibaca dan diaudit
+		x := new(big.Int)
+		y := new(big.Int)
+
+		// (2 if len(parent_uncles) else 1) - (block_timestamp - parent_timestamp) // 9
+		x.Sub(bigTime, bigParentTime)
+		x.Div(x, big9)
+		if parent.UncleHash == types.EmptyUncleHash {
+			x.Sub(big1, x)
+		} else {
+			x.Sub(big2, x)
+		}
+		// max((2 if len(parent_uncles) else 1) - (block_timestamp - parent_timestamp) // 9, -99)
+		if x.Cmp(bigMinus99) < 0 {
+			x.Set(bigMinus99)
+		}
+		// parent_diff + (parent_diff / 2048 * max(...))
+		y.Div(parent.Difficulty, params.DifficultyBoundDivisor)
+		x.Mul(y, x)
+		x.Add(parent.Difficulty, x)
+
+		// kesulitan minimum yang mungkin (sebelum faktor eksponensial)
+		if x.Cmp(params.MinimumDifficulty) < 0 {
+			x.Set(params.MinimumDifficulty)
+		}
+		if bombDelayFromParent == nil {
+			return x
+		}
+		// hitung nomor blok palsu untuk jeda ice-age (EIP-1234)
+		fakeBlockNumber := new(big.Int)
+		if parent.Number.Cmp(bombDelayFromParent) >= 0 {
+			fakeBlockNumber = fakeBlockNumber.Sub(parent.Number, bombDelayFromParent)
+		}
+		// untuk faktor eksponensial
+		periodCount := fakeBlockNumber
+		periodCount.Div(periodCount, expDiffPeriod)
+
+		// faktor eksponensial, biasa disebut "bom"
+		// diff = diff + 2^(periodCount - 2)
+		if periodCount.Cmp(big1) > 0 {
+			y.Sub(periodCount, big2)
+			y.Exp(big2, y, nil)
+			x.Add(x, y)
+		}
+		return x
+	}
+}
+
+// calcDifficultyHomestead adalah algoritma penyesuaian kesulitan dengan aturan Homestead.
+func calcDifficultyHomestead(time uint64, parent *types.Header) *big.Int {
+	// https://github.com/ethereum/EIPs/blob/master/EIPS/eip-2.md
+	// algoritma:
+	// diff = (parent_diff +
+	//         (parent_diff / 2048 * max(1 - (block_timestamp - parent_timestamp) // 10, -99))
+	//        ) + 2^(periodCount - 2)
+	bigTime := new(big.Int).SetUint64(time)
+	bigParentTime := new(big.Int).SetUint64(parent.Time)
+
+	// menampung nilai sementara agar algoritma mudah dibaca dan diaudit
+	x := new(big.Int)
+	y := new(big.Int)
+
+	// 1 - (block_timestamp - parent_timestamp) // 10
+	x.Sub(bigTime, bigParentTime)
+	x.Div(x, big10)
+	x.Sub(big1, x)
+
+	// max(1 - (block_timestamp - parent_timestamp) // 10, -99)
+	if x.Cmp(bigMinus99) < 0 {
+		x.Set(bigMinus99)
+	}
+	// (parent_diff + parent_diff // 2048 * max(1 - (block_timestamp - parent_timestamp) // 10, -99))
+	y.Div(parent.Difficulty, params.DifficultyBoundDivisor)
+	x.Mul(y, x)
+	x.Add(parent.Difficulty, x)
+
+	// kesulitan minimum yang mungkin (sebelum faktor eksponensial)
+	if x.Cmp(params.MinimumDifficulty) < 0 {
+		x.Set(params.MinimumDifficulty)
+	}
+	// untuk faktor eksponensial
+	periodCount := new(big.Int).Add(parent.Number, big1)
+	periodCount.Div(periodCount, expDiffPeriod)
+
+	// faktor eksponensial, biasa disebut "bom"
+	// diff = diff + 2^(periodCount - 2)
+	if periodCount.Cmp(big1) > 0 {
+		y.Sub(periodCount, big2)
+		y.Exp(big2, y, nil)
+		x.Add(x, y)
+	}
+	return x
+}
+
+// calcDifficultyFrontier adalah algoritma penyesuaian kesulitan dengan aturan Frontier.
+func calcDifficultyFrontier(time uint64, parent *types.Header) *big.Int {
+	diff := new(big.Int)
+	adjust := new(big.Int).Div(parent.Difficulty, params.DifficultyBoundDivisor)
+	bigTime := new(big.Int)
+	bigParentTime := new(big.Int)
+
+	bigTime.SetUint64(time)
+	bigParentTime.SetUint64(parent.Time)
+
+	if bigTime.Sub(bigTime, bigParentTime).Cmp(params.DurationLimit) < 0 {
+		diff.Add(parent.Difficulty, adjust)
+	} else {
+		diff.Sub(parent.Difficulty, adjust)
+	}
+	if diff.Cmp(params.MinimumDifficulty) < 0 {
+		diff.Set(params.MinimumDifficulty)
+	}
+	periodCount := new(big.Int).Add(parent.Number, big1)
+	periodCount.Div(periodCount, expDiffPeriod)
+	if periodCount.Cmp(big1) > 0 {
+		// diff = diff + 2^(periodCount - 2)
+		expDiff := periodCount.Sub(periodCount, big2)
+		expDiff.Exp(big2, expDiff, nil)
+		diff.Add(diff, expDiff)
+		diff = math.BigMax(diff, params.MinimumDifficulty)
+	}
+	return diff
+}
+
+// verifySeal memeriksa apakah blok memenuhi persyaratan kesulitan PoW, baik dengan
+// cache ethash biasa, atau dengan DAG penuh agar verifikasi lebih cepat.
+func (ethash *Ethash) verifySeal(chain consensus.ChainHeaderReader, header *types.Header, fulldag bool) error {
+	// Jika kita menjalankan PoW palsu, terima semua segel sebagai valid
+	if ethash.config.PowMode == ModeFake {
+		return nil
+	}
+	// Pastikan kesulitan blok valid
+	if header.Difficulty.Sign() <= 0 {
+		return consensus.ErrInvalidDifficulty
+	}
+	// Hitung ulang nilai digest dan PoW
+	number := header.Number.Uint64()
+
+	var (
+		digest []byte
+		result []byte
+	)
+	// Jika verifikasi PoW cepat-tapi-berat diminta, gunakan dataset ethash
+	if fulldag {
+		dataset := ethash.dataset(number, true)
+		if dataset.generated() {
+			digest, result = hashimotoFull(dataset.dataset, ethash.SealHash(header).Bytes(), header.Nonce.Uint64())
+		} else {
+			// Dataset belum dibuat, jangan menunggu, gunakan cache saja
+			fulldag = false
+		}
+	}
+	// Jika verifikasi PoW lambat-tapi-ringan diminta (atau DAG belum siap), gunakan cache ethash
+	if !fulldag {
+		cache := ethash.cache(number)
+
+		size := datasetSize(number)
+		if ethash.config.PowMode == ModeTest {
+			size = 32 * 1024
+		}
+		digest, result = hashimotoLight(size, cache.cache, ethash.SealHash(header).Bytes(), header.Nonce.Uint64())
+	}
+	// Verifikasi nilai yang dihitung terhadap nilai di header
+	if !bytes.Equal(header.MixDigest[:], digest) {
+		return consensus.ErrInvalidMixDigest
+	}
+	target := new(big.Int).Div(two256, header.Difficulty)
+	if new(big.Int).SetBytes(result).Cmp(target) > 0 {
+		return errInvalidPoW
+	}
+	return nil
+}
+
+// Prepare mengimplementasikan consensus.Engine, menginisialisasi field kesulitan
+// header agar sesuai dengan protokol ethash. Perubahan dijalankan sebaris.
+func (ethash *Ethash) Prepare(chain consensus.ChainHeaderReader, header *types.Header) error {
+	if ethash.rulesErr != nil {
+		return ethash.rulesErr
+	}
+	parent := chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	header.Difficulty = ethash.CalcDifficulty(chain, header.Time, parent)
+	return nil
+}
+
+// Finalize mengimplementasikan consensus.Engine, mengakumulasi reward blok dan paman,
+// lalu menetapkan state akhir pada header.
+func (ethash *Ethash) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header) {
+	// Akumulasi semua reward blok dan paman lalu tetapkan root state akhir
+	accumulateRewards(chain.Config(), ethash.rules.unclesAt(header.Number.Uint64()), state, header, uncles)
+	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
+}
+
+// FinalizeAndAssemble mengimplementasikan consensus.Engine, mengakumulasi reward
+// blok dan paman, menetapkan state akhir, lalu merakit blok.
+func (ethash *Ethash) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
+	// Finalisasi blok
+	ethash.Finalize(chain, header, state, txs, uncles)
+
+	// Header sudah lengkap, rakit menjadi blok dan kembalikan
+	return types.NewBlock(header, txs, uncles, receipts, trie.NewStackTrie(nil)), nil
+}
+
+// SealHash mengembalikan hash dari sebuah blok sebelum disegel.
+func (ethash *Ethash) SealHash(header *types.Header) (hash common.Hash) {
+	hasher := sha3.NewLegacyKeccak256()
+
+	enc := []interface{}{
+		header.ParentHash,
+		header.UncleHash,
+		header.Coinbase,
+		header.Root,
+		header.TxHash,
+		header.ReceiptHash,
+		header.Bloom,
+		header.Difficulty,
+		header.Number,
+		header.GasLimit,
+		header.GasUsed,
+		header.Time,
+		header.Extra,
+	}
+	if header.BaseFee != nil {
+		enc = append(enc, header.BaseFee)
+	}
+	rlp.Encode(hasher, enc)
+	hasher.Sum(hash[:0])
+	return hash
+}
+
+// accumulateRewards mengkreditkan reward penambangan ke coinbase blok. Total reward
+// terdiri dari block reward statis dan reward untuk paman yang dimasukkan. Coinbase
+// setiap paman juga diberi reward sesuai policy paman.
+func accumulateRewards(config *params.ChainConfig, policy unclePolicy, state *state.StateDB, header *types.Header, uncles []*types.Header) {
+	// Pilih block reward yang benar berdasarkan perkembangan rantai
+	blockReward := FrontierBlockReward
+	if config.IsByzantium(header.Number) {
+		blockReward = ByzantiumBlockReward
+	}
+	if config.IsConstantinople(header.Number) {
+		blockReward = ConstantinopleBlockReward
+	}
+	// Akumulasi reward untuk penambang dan paman yang dimasukkan
+	var (
+		uncleDenominator     = new(big.Int).SetUint64(policy.uncleRewardDenominator)
+		inclusionDenominator = new(big.Int).SetUint64(policy.inclusionRewardDenominator)
+	)
+	reward := new(big.Int).Set(blockReward)
+	r := new(big.Int)
+	for _, uncle := range uncles {
+		r.Add(uncle.Number, uncleDenominator)
+		r.Sub(r, header.Number)
+		if r.Sign() > 0 {
+			r.Mul(r, blockReward)
+			r.Div(r, uncleDenominator)
+			state.AddBalance(uncle.Coinbase, r)
+		}
+		r.Div(blockReward, inclusionDenominator)
+		reward.Add(reward, r)
+	}
+	state.AddBalance(header.Coinbase, reward)
+}
@@ -0,0 +1,310 @@
+package ethash
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/consensustest"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// newTestChain membuat rantai di memori dengan n header ethash di atas genesis.
+// Setiap header memiliki kesulitan yang benar sehingga lolos verifikasi mesin
+// palsu.
+func newTestChain(t *testing.T, config *params.ChainConfig, engine *Ethash, n int) (*consensustest.HeaderChain, []*types.Header) {
+	t.Helper()
+
+	genesis := &types.Header{
+		Number:     big.NewInt(0),
+		Difficulty: new(big.Int).Set(params.MinimumDifficulty),
+		GasLimit:   params.GenesisGasLimit,
+		BaseFee:    big.NewInt(params.InitialBaseFee),
+		UncleHash:  types.EmptyUncleHash,
+	}
+	chain := consensustest.NewHeaderChain(config, genesis)
+	headers, err := chain.Extend(n, mine(chain, engine, nil))
+	if err != nil {
+		t.Fatalf("failed to extend chain: %v", err)
+	}
+	return chain, headers
+}
+
+// mine mengembalikan fungsi gen untuk HeaderChain yang mengisi stempel waktu dan
+// kesulitan header sesuai aturan ethash, dengan extra-data yang diberikan agar
+// cabang yang berbeda menghasilkan hash yang berbeda.
+func mine(chain *consensustest.HeaderChain, engine *Ethash, extra []byte) func(int, *types.Header) {
+	return func(i int, header *types.Header) {
+		parent := chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+		header.Time = parent.Time + 10
+		header.Difficulty = engine.CalcDifficulty(chain, header.Time, parent)
+		header.UncleHash = types.EmptyUncleHash
+		header.Extra = extra
+	}
+}
+
+// Menguji bahwa paman hanya diterima jika induknya leluhur dekat yang bukan induk
+// blok, dan bahwa paman yang merupakan leluhur, ganda atau terlalu tua ditolak.
+func TestVerifyUncles(t *testing.T) {
+	engine := NewFaker()
+	defer engine.Close()
+
+	chain, headers := newTestChain(t, params.AllEthashProtocolChanges, engine, 10)
+	parent := headers[9]
+
+	// uncle membuat satu header samping di atas leluhur yang diberikan
+	uncle := func(ancestor *types.Header, tag string) *types.Header {
+		side, err := chain.Fork(ancestor.Hash(), 1, mine(chain, engine, []byte(tag)))
+		if err != nil {
+			t.Fatalf("failed to fork at %d: %v", ancestor.Number, err)
+		}
+		return side[0]
+	}
+	valid, recent := uncle(headers[6], "valid"), uncle(headers[3], "recent")
+	orphan := types.CopyHeader(valid)
+	orphan.ParentHash = common.Hash{1}
+
+	tests := []struct {
+		name   string
+		uncles []*types.Header
+		want   error
+	}{
+		{"none", nil, nil},
+		{"valid", []*types.Header{valid}, nil},
+		{"two valid", []*types.Header{valid, recent}, nil},
+		{"too many", []*types.Header{valid, recent, uncle(headers[5], "extra")}, consensus.ErrTooManyUncles},
+		{"duplicate", []*types.Header{valid, valid}, errDuplicateUncle},
+		{"ancestor", []*types.Header{headers[7]}, errUncleIsAncestor},
+		{"sibling", []*types.Header{uncle(parent, "sibling")}, errDanglingUncle},
+		{"too old", []*types.Header{uncle(headers[1], "old")}, errDanglingUncle},
+		{"unknown parent", []*types.Header{orphan}, errDanglingUncle},
+		{"deep ancestor", []*types.Header{headers[0]}, errDanglingUncle},
+	}
+	for _, test := range tests {
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number, common.Big1),
+			UncleHash:  types.CalcUncleHash(test.uncles),
+		}
+		block := types.NewBlockWithHeader(header).WithBody(nil, test.uncles)
+		if err := engine.VerifyUncles(chain, block); err != test.want {
+			t.Errorf("%s: error mismatch: have %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+// Menguji bahwa policy paman dari aturan mesin dipakai untuk validasi dan reward
+// mulai dari blok fork-nya, dan MaxUncles nol yang diisi eksplisit menolak semua
+// paman alih-alih memakai nilai mainnet.
+func TestUnclePolicy(t *testing.T) {
+	one, three, zero := 1, 3, 0
+	engine := New(Config{PowMode: ModeFake, Rules: ChainRules{
+		Uncles: []UncleFork{
+			{Block: 11, Policy: UnclePolicy{
+				MaxUncles:                  &one,
+				MaxDepth:                   &three,
+				UncleRewardDenominator:     4,
+				InclusionRewardDenominator: 16,
+			}},
+			{Block: 12, Policy: UnclePolicy{MaxUncles: &zero}},
+		},
+	}})
+	defer engine.Close()
+
+	chain, headers := newTestChain(t, params.AllEthashProtocolChanges, engine, 11)
+	uncle := func(ancestor *types.Header, tag string) *types.Header {
+		side, err := chain.Fork(ancestor.Hash(), 1, mine(chain, engine, []byte(tag)))
+		if err != nil {
+			t.Fatalf("failed to fork at %d: %v", ancestor.Number, err)
+		}
+		return side[0]
+	}
+	near, far := uncle(headers[7], "near"), uncle(headers[6], "far")
+
+	tests := []struct {
+		name   string
+		parent *types.Header
+		uncles []*types.Header
+		want   error
+	}{
+		{"one", headers[9], []*types.Header{near}, nil},
+		{"too many", headers[9], []*types.Header{near, far}, consensus.ErrTooManyUncles},
+		{"too deep", headers[9], []*types.Header{far}, errDanglingUncle},
+		{"before fork", headers[8], []*types.Header{near, far}, nil},
+		{"no uncles allowed", headers[10], []*types.Header{near}, consensus.ErrTooManyUncles},
+	}
+	for _, test := range tests {
+		header := &types.Header{
+			ParentHash: test.parent.Hash(),
+			Number:     new(big.Int).Add(test.parent.Number, common.Big1),
+			UncleHash:  types.CalcUncleHash(test.uncles),
+		}
+		block := types.NewBlockWithHeader(header).WithBody(nil, test.uncles)
+		if err := engine.VerifyUncles(chain, block); err != test.want {
+			t.Errorf("%s: error mismatch: have %v, want %v", test.name, err, test.want)
+		}
+	}
+	// Reward memakai pembagi dari policy: paman berjarak 2 mendapat (4-2)/4 dan
+	// pembuat blok mendapat 1/16 untuk setiap paman
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	rewarded := types.CopyHeader(near)
+	rewarded.Coinbase = common.Address{0xbb}
+	header := &types.Header{Number: big.NewInt(11), Coinbase: common.Address{0xaa}}
+	engine.Finalize(chain, header, statedb, nil, []*types.Header{rewarded})
+
+	if have, want := statedb.GetBalance(rewarded.Coinbase), new(big.Int).Div(ConstantinopleBlockReward, big.NewInt(2)); have.Cmp(want) != 0 {
+		t.Errorf("uncle reward mismatch: have %v, want %v", have, want)
+	}
+	want := new(big.Int).Div(ConstantinopleBlockReward, big.NewInt(16))
+	if want.Add(want, ConstantinopleBlockReward); statedb.GetBalance(header.Coinbase).Cmp(want) != 0 {
+		t.Errorf("miner reward mismatch: have %v, want %v", statedb.GetBalance(header.Coinbase), want)
+	}
+}
+
+// nextHeader membuat header yang valid di atas induk tanpa memasukkannya ke rantai.
+func nextHeader(chain *consensustest.HeaderChain, engine *Ethash, parent *types.Header) *types.Header {
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		Time:       parent.Time + 10,
+		GasLimit:   parent.GasLimit,
+		BaseFee:    misc.CalcBaseFee(chain.Config(), parent),
+		UncleHash:  types.EmptyUncleHash,
+	}
+	header.Difficulty = engine.CalcDifficulty(chain, header.Time, parent)
+	return header
+}
+
+// Menguji bahwa rantai yang valid lolos verifikasi satu per satu maupun per batch,
+// dan bahwa kesalahan di tengah batch dilaporkan pada header yang tepat.
+func TestVerifyHeaders(t *testing.T) {
+	engine := NewFaker()
+	defer engine.Close()
+
+	_, headers := newTestChain(t, params.AllEthashProtocolChanges, engine, 8)
+	verifier, _ := newTestChain(t, params.AllEthashProtocolChanges, engine, 0)
+	for i, header := range headers {
+		if err := engine.VerifyHeader(verifier, header, true); err != nil {
+			t.Fatalf("header %d: verification failed: %v", i, err)
+		}
+		if err := verifier.Insert(header); err != nil {
+			t.Fatalf("header %d: failed to insert: %v", i, err)
+		}
+	}
+	broken := make([]*types.Header, len(headers))
+	copy(broken, headers)
+	broken[4] = types.CopyHeader(headers[4])
+	broken[4].Difficulty = new(big.Int).Add(broken[4].Difficulty, common.Big1)
+
+	for _, batch := range [][]*types.Header{headers, broken} {
+		seals := make([]bool, len(batch))
+		abort, results := engine.VerifyHeaders(verifier, batch, seals)
+		for i := range batch {
+			var err error
+			select {
+			case err = <-results:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("header %d: verification timeout", i)
+			}
+			var want error
+			switch {
+			case batch[i] != headers[i]:
+				want = consensus.ErrInvalidDifficulty
+			case i > 0 && batch[i-1] != headers[i-1]:
+				want = consensus.ErrUnknownAncestor
+			}
+			if !errors.Is(err, want) {
+				t.Errorf("header %d: error mismatch: have %v, want %v", i, err, want)
+			}
+		}
+		close(abort)
+	}
+}
+
+// Menguji bahwa header yang melanggar aturan ethash ditolak.
+func TestVerifyHeaderReject(t *testing.T) {
+	engine := NewFaker()
+	defer engine.Close()
+
+	chain, headers := newTestChain(t, params.AllEthashProtocolChanges, engine, 2)
+	parent := headers[1]
+
+	tests := []struct {
+		name   string
+		mutate func(header *types.Header)
+		want   error
+	}{
+		{"valid", func(h *types.Header) {}, nil},
+		{"unknown ancestor", func(h *types.Header) { h.ParentHash = common.Hash{1} }, consensus.ErrUnknownAncestor},
+		{"future", func(h *types.Header) { h.Time = uint64(time.Now().Unix()) + 60 }, consensus.ErrFutureBlock},
+		{"same timestamp", func(h *types.Header) { h.Time = parent.Time }, errOlderBlockTime},
+		{"difficulty", func(h *types.Header) { h.Difficulty = new(big.Int).Add(h.Difficulty, common.Big1) }, consensus.ErrInvalidDifficulty},
+		{"extra too long", func(h *types.Header) { h.Extra = make([]byte, params.MaximumExtraDataSize+1) }, errAnyFailure},
+		{"gas limit too high", func(h *types.Header) { h.GasLimit = params.MaxGasLimit + 1 }, errAnyFailure},
+		{"gas used over limit", func(h *types.Header) { h.GasUsed = h.GasLimit + 1 }, errAnyFailure},
+		{"gas limit jump", func(h *types.Header) { h.GasLimit *= 3 }, errAnyFailure},
+		{"base fee", func(h *types.Header) { h.BaseFee = new(big.Int).Add(h.BaseFee, common.Big1) }, errAnyFailure},
+		{"no base fee", func(h *types.Header) { h.BaseFee = nil }, errAnyFailure},
+	}
+	for _, test := range tests {
+		header := nextHeader(chain, engine, parent)
+		test.mutate(header)
+
+		err := engine.VerifyHeader(chain, header, true)
+		switch {
+		case test.want == errAnyFailure && err == nil:
+			t.Errorf("%s: invalid header accepted", test.name)
+		case test.want != errAnyFailure && !errors.Is(err, test.want):
+			t.Errorf("%s: error mismatch: have %v, want %v", test.name, err, test.want)
+		}
+	}
+	// Nomor blok yang melompat tidak cocok dengan induknya
+	header := nextHeader(chain, engine, parent)
+	header.Number = big.NewInt(5)
+	if err := engine.verifyHeader(chain, header, parent, false, false, time.Now().Unix()); err != consensus.ErrInvalidNumber {
+		t.Errorf("number error mismatch: have %v, want %v", err, consensus.ErrInvalidNumber)
+	}
+}
+
+// errAnyFailure menandai kasus tes yang harus gagal dengan error tanpa sentinel.
+var errAnyFailure = errors.New("any failure")
+
+// Menguji bahwa penambang mode tes menemukan segel yang lolos verifikasi, dan bahwa
+// segel yang diubah ditolak.
+func TestSeal(t *testing.T) {
+	engine := NewTester()
+	defer engine.Close()
+
+	header := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(100)}
+	results := make(chan *types.Block)
+	if err := engine.Seal(nil, types.NewBlockWithHeader(header), results, nil); err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	select {
+	case block := <-results:
+		header.Nonce = types.EncodeNonce(block.Nonce())
+		header.MixDigest = block.MixDigest()
+	case <-time.After(5 * time.Second):
+		t.Fatalf("sealing result timeout")
+	}
+	if err := engine.verifySeal(nil, header, false); err != nil {
+		t.Fatalf("sealed header rejected: %v", err)
+	}
+	tampered := types.CopyHeader(header)
+	tampered.MixDigest = common.Hash{1}
+	if err := engine.verifySeal(nil, tampered, false); err != consensus.ErrInvalidMixDigest {
+		t.Errorf("mix digest error mismatch: have %v, want %v", err, consensus.ErrInvalidMixDigest)
+	}
+	tampered = types.CopyHeader(header)
+	tampered.Difficulty = big.NewInt(0)
+	if err := engine.verifySeal(nil, tampered, false); err != consensus.ErrInvalidDifficulty {
+		t.Errorf("difficulty error mismatch: have %v, want %v", err, consensus.ErrInvalidDifficulty)
+	}
+}
@@ -0,0 +1,86 @@
+package ethash
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// DifficultyCalculator adalah satu strategi penyesuaian kesulitan. Strategi menerima
+// waktu blok baru dan header induk, lalu mengembalikan kesulitan blok baru.
+type DifficultyCalculator func(time uint64, parent *types.Header) *big.Int
+
+// Nama strategi kesulitan bawaan.
+const (
+	DifficultyFrontier       = "frontier"       // Aturan Frontier dengan bom penuh
+	DifficultyHomestead      = "homestead"      // Aturan Homestead (EIP-2) dengan bom penuh
+	DifficultyByzantium      = "byzantium"      // Aturan Byzantium dengan bom digeser 3 juta blok (EIP-649)
+	DifficultyConstantinople = "constantinople" // Bom digeser 5 juta blok (EIP-1234)
+	DifficultyMuirGlacier    = "muirglacier"    // Bom digeser 9 juta blok (EIP-2384)
+	DifficultyLondon         = "london"         // Bom digeser 9,7 juta blok (EIP-3554)
+	DifficultyArrowGlacier   = "arrowglacier"   // Bom digeser 10,7 juta blok (EIP-4345)
+	DifficultyGrayGlacier    = "grayglacier"    // Bom digeser 11,4 juta blok (EIP-5133)
+	DifficultyNoBomb         = "nobomb"         // Aturan Byzantium tanpa bom kesulitan
+)
+
+// errUnknownDifficulty dikembalikan jika nama strategi kesulitan tidak terdaftar.
+var errUnknownDifficulty = errors.New("unknown difficulty strategy")
+
+// difficultyStrategies adalah strategi kesulitan bawaan berdasarkan namanya. Map
+// ini tidak pernah diubah, strategi khusus dipasang langsung lewat DifficultyFork.
+var difficultyStrategies = map[string]DifficultyCalculator{
+	DifficultyFrontier:       calcDifficultyFrontier,
+	DifficultyHomestead:      calcDifficultyHomestead,
+	DifficultyByzantium:      calcDifficultyByzantium,
+	DifficultyConstantinople: calcDifficultyConstantinople,
+	DifficultyMuirGlacier:    calcDifficultyEip2384,
+	DifficultyLondon:         calcDifficultyEip3554,
+	DifficultyArrowGlacier:   calcDifficultyEip4345,
+	DifficultyGrayGlacier:    calcDifficultyEip5133,
+	DifficultyNoBomb:         makeDifficultyCalculator(nil),
+}
+
+// BombDifficulty membuat strategi kesulitan dengan aturan Byzantium dan bom
+// kesulitan yang digeser sejauh delay blok, seperti yang dilakukan fork mainnet.
+// Delay nol berarti bom dihitung dari nomor blok asli. Strategi ini dipasang di
+// field Calc DifficultyFork dan dijadwalkan lewat ChainRules bersama
+// DifficultyNoBomb sehingga operator bisa menyalakan, menggeser dan mematikan bom
+// pada blok yang direncanakan.
+func BombDifficulty(delay uint64) DifficultyCalculator {
+	return makeDifficultyCalculator(new(big.Int).SetUint64(delay))
+}
+
+// DifficultyStrategy mengambil strategi kesulitan bawaan berdasarkan namanya.
+func DifficultyStrategy(name string) (DifficultyCalculator, error) {
+	calc, ok := difficultyStrategies[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", errUnknownDifficulty, name)
+	}
+	return calc, nil
+}
+
+// ForkDifficulty mengembalikan nama strategi kesulitan yang aktif pada nomor blok
+// tertentu menurut jadwal fork di konfigurasi chain.
+func ForkDifficulty(config *params.ChainConfig, number *big.Int) string {
+	switch {
+	case config.IsGrayGlacier(number):
+		return DifficultyGrayGlacier
+	case config.IsArrowGlacier(number):
+		return DifficultyArrowGlacier
+	case config.IsLondon(number):
+		return DifficultyLondon
+	case config.IsMuirGlacier(number):
+		return DifficultyMuirGlacier
+	case config.IsConstantinople(number):
+		return DifficultyConstantinople
+	case config.IsByzantium(number):
+		return DifficultyByzantium
+	case config.IsHomestead(number):
+		return DifficultyHomestead
+	default:
+		return DifficultyFrontier
+	}
+}
@@ -0,0 +1,203 @@
+package ethash
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/consensustest"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Menguji kesulitan di sekitar setiap fork mainnet. Blok 1 adalah nilai mainnet
+// asli, sisanya dihitung dengan implementasi referensi go-ethereum dari induk di
+// tinggi fork yang sebenarnya.
+func TestCalcDifficultyMainnet(t *testing.T) {
+	tests := []struct {
+		name   string
+		parent uint64
+		diff   string
+		delta  uint64
+		uncles bool
+		want   string
+	}{
+		{"frontier block 1", 0, "17179869184", 1438269988, false, "17171480576"},
+		{"frontier bomb", 1149998, "20472449798864", 12, false, "20482446112754"},
+		{"homestead", 1149999, "20472449798864", 12, false, "20472449799376"},
+		{"homestead slow", 1149999, "20472449798864", 55, false, "20432464545864"},
+		{"homestead bomb", 4369998, "1715798426992627", 11, false, "1717997450248179"},
+		{"byzantium", 4369999, "1715798426992627", 11, false, "1715798426994675"},
+		{"byzantium uncles", 4369999, "1715798426992627", 3, true, "1717474011396033"},
+		{"constantinople", 7279999, "2958989377840759", 17, false, "2958989378889335"},
+		{"constantinople uncles", 7279999, "2958989377840759", 9, true, "2960434197921483"},
+		{"muir glacier", 9199999, "2459939180268022", 14, false, "2459939180268023"},
+		{"muir glacier slow", 9199999, "2459939180268022", 40, true, "2457536895912293"},
+		{"london", 12964999, "7742494561645080", 7, false, "7746276150309582"},
+		{"london uncles", 12964999, "7742494561645080", 13, true, "7746276150309582"},
+		{"gray glacier", 15049999, "12014457941989910", 12, false, "12014475121859094"},
+		{"gray glacier uncles", 15049999, "12014457941989910", 2, true, "12026207990943068"},
+	}
+	for _, test := range tests {
+		diff, _ := new(big.Int).SetString(test.diff, 10)
+		want, _ := new(big.Int).SetString(test.want, 10)
+
+		parent := &types.Header{
+			Number:     new(big.Int).SetUint64(test.parent),
+			Difficulty: diff,
+			UncleHash:  types.EmptyUncleHash,
+		}
+		if test.parent > 0 {
+			parent.Time = 1000000
+		}
+		if test.uncles {
+			parent.UncleHash = common.Hash{1}
+		}
+		if have := CalcDifficulty(params.MainnetChainConfig, parent.Time+test.delta, parent); have.Cmp(want) != 0 {
+			t.Errorf("%s: difficulty mismatch: have %v, want %v", test.name, have, want)
+		}
+	}
+}
+
+// Menguji bahwa jadwal di aturan mesin menggantikan jadwal fork mulai dari blok
+// pertamanya dan tidak berpengaruh pada mesin lain maupun CalcDifficulty.
+func TestChainRulesDifficulty(t *testing.T) {
+	engine := New(Config{PowMode: ModeFake, Rules: ChainRules{
+		Difficulty: []DifficultyFork{{Block: 200000, Strategy: DifficultyNoBomb}},
+	}})
+	defer engine.Close()
+	plain := NewFaker()
+	defer plain.Close()
+
+	chain := newRulesChain(params.MainnetChainConfig)
+	parent := &types.Header{
+		Difficulty: big.NewInt(1000000),
+		Time:       1000000,
+		UncleHash:  types.EmptyUncleHash,
+	}
+	bombless := makeDifficultyCalculator(nil)
+	for _, number := range []uint64{300000, 5000000} {
+		parent.Number = new(big.Int).SetUint64(number)
+		if have, want := engine.CalcDifficulty(chain, parent.Time+20, parent), bombless(parent.Time+20, parent); have.Cmp(want) != 0 {
+			t.Errorf("block %d: difficulty mismatch: have %v, want %v", number+1, have, want)
+		}
+		if have, want := plain.CalcDifficulty(chain, parent.Time+20, parent), bombless(parent.Time+20, parent); have.Cmp(want) == 0 {
+			t.Errorf("block %d: engine without rules picked up bombless schedule", number+1)
+		}
+		if have, want := CalcDifficulty(params.MainnetChainConfig, parent.Time+20, parent), plain.CalcDifficulty(chain, parent.Time+20, parent); have.Cmp(want) != 0 {
+			t.Errorf("block %d: package difficulty mismatch: have %v, want %v", number+1, have, want)
+		}
+	}
+	// Sebelum fork pertama di jadwal, chain tetap mengikuti ChainConfig
+	parent.Number = big.NewInt(199998)
+	if have, want := engine.CalcDifficulty(chain, parent.Time+20, parent), CalcDifficulty(params.MainnetChainConfig, parent.Time+20, parent); have.Cmp(want) != 0 {
+		t.Errorf("pre-schedule difficulty mismatch: have %v, want %v", have, want)
+	}
+}
+
+// Menguji bahwa bom kesulitan bisa dinyalakan, digeser dan dimatikan pada blok
+// yang dijadwalkan di aturan mesin.
+func TestBombSchedule(t *testing.T) {
+	if have, want := BombDifficulty(9_700_000), calcDifficultyEip3554; !sameDifficulty(have, want) {
+		t.Fatalf("bomb delay does not match london")
+	}
+	engine := New(Config{PowMode: ModeFake, Rules: ChainRules{
+		Difficulty: []DifficultyFork{
+			{Block: 0, Strategy: DifficultyNoBomb},
+			{Block: 1_000_000, Calc: BombDifficulty(0)},
+			{Block: 4_000_000, Calc: BombDifficulty(4_000_000)},
+			{Block: 6_000_000, Strategy: DifficultyNoBomb},
+		},
+	}})
+	defer engine.Close()
+
+	chain := newRulesChain(params.MainnetChainConfig)
+	parent := &types.Header{
+		Difficulty: big.NewInt(1_000_000_000),
+		Time:       1000000,
+		UncleHash:  types.EmptyUncleHash,
+	}
+	base := makeDifficultyCalculator(nil)
+	tests := []struct {
+		number uint64 // Nomor blok induk
+		bomb   uint64 // Periode bom yang diharapkan, nol berarti tanpa bom
+	}{
+		{999_998, 0},
+		{2_999_999, 30},
+		{4_099_999, 0},
+		{5_999_998, 19},
+		{6_999_999, 0},
+	}
+	for _, test := range tests {
+		parent.Number = new(big.Int).SetUint64(test.number)
+		want := base(parent.Time+10, parent)
+		if test.bomb > 1 {
+			want.Add(want, new(big.Int).Lsh(common.Big1, uint(test.bomb-2)))
+		}
+		if have := engine.CalcDifficulty(chain, parent.Time+10, parent); have.Cmp(want) != 0 {
+			t.Errorf("block %d: difficulty mismatch: have %v, want %v", test.number+1, have, want)
+		}
+	}
+}
+
+// newRulesChain membuat rantai kosong dengan konfigurasi tertentu, cukup untuk
+// memanggil CalcDifficulty mesin pada induk buatan.
+func newRulesChain(config *params.ChainConfig) *consensustest.HeaderChain {
+	return consensustest.NewHeaderChain(config, &types.Header{
+		Number:     big.NewInt(0),
+		Difficulty: new(big.Int).Set(params.MinimumDifficulty),
+		UncleHash:  types.EmptyUncleHash,
+	})
+}
+
+// sameDifficulty membandingkan dua strategi pada beberapa induk contoh.
+func sameDifficulty(a, b DifficultyCalculator) bool {
+	for _, number := range []int64{0, 5_000_000, 12_000_000, 20_000_000} {
+		parent := &types.Header{Number: big.NewInt(number), Difficulty: big.NewInt(1 << 40), UncleHash: types.EmptyUncleHash}
+		if a(12, parent).Cmp(b(12, parent)) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Menguji bahwa aturan yang tidak valid ditolak oleh Validate, dan mesin yang
+// dibuat dengan aturan tersebut menolak header alih-alih diam-diam memakai jadwal
+// fork bawaan.
+func TestChainRulesValidate(t *testing.T) {
+	negative := -1
+	tests := []struct {
+		name  string
+		rules ChainRules
+		want  error
+	}{
+		{"unknown strategy", ChainRules{Difficulty: []DifficultyFork{{Strategy: "nope"}}}, errUnknownDifficulty},
+		{"unordered", ChainRules{Difficulty: []DifficultyFork{
+			{Block: 10, Strategy: DifficultyNoBomb}, {Block: 10, Strategy: DifficultyLondon},
+		}}, errUnorderedSchedule},
+		{"unordered uncles", ChainRules{Uncles: []UncleFork{{Block: 5}, {Block: 4}}}, errUnorderedSchedule},
+		{"negative uncles", ChainRules{Uncles: []UncleFork{{Policy: UnclePolicy{MaxUncles: &negative}}}}, errNegativeUncleLimit},
+		{"custom", ChainRules{Difficulty: []DifficultyFork{{Calc: BombDifficulty(0)}}}, nil},
+		{"empty", ChainRules{}, nil},
+	}
+	for _, test := range tests {
+		if err := test.rules.Validate(); !errors.Is(err, test.want) {
+			t.Errorf("%s: error mismatch: have %v, want %v", test.name, err, test.want)
+		}
+	}
+	if _, err := DifficultyStrategy("nope"); !errors.Is(err, errUnknownDifficulty) {
+		t.Errorf("lookup error mismatch: have %v, want %v", err, errUnknownDifficulty)
+	}
+	engine := New(Config{PowMode: ModeFake, Rules: tests[1].rules})
+	defer engine.Close()
+
+	faker := NewFaker()
+	defer faker.Close()
+
+	_, headers := newTestChain(t, params.AllEthashProtocolChanges, faker, 1)
+	verifier, _ := newTestChain(t, params.AllEthashProtocolChanges, faker, 0)
+	if err := engine.VerifyHeader(verifier, headers[0], true); !errors.Is(err, errUnorderedSchedule) {
+		t.Errorf("verification error mismatch: have %v, want %v", err, errUnorderedSchedule)
+	}
+}
@@ -0,0 +1,396 @@
+// Paket ethash mengimplementasikan mesin konsensus proof-of-work ethash.
+package ethash
+
+import (
+	"math/big"
+	"math/rand"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/hashicorp/golang-lru/simplelru"
+)
+
+// two256 adalah bilangan besar yang mewakili 2^256.
+var two256 = new(big.Int).Exp(big.NewInt(2), big.NewInt(256), big.NewInt(0))
+
+// lru melacak cache atau dataset berdasarkan waktu terakhir dipakai, dan hanya
+// menyimpan paling banyak N item.
+type lru struct {
+	what string
+	new  func(epoch uint64) interface{}
+	mu   sync.Mutex
+
+	// Item disimpan dalam cache LRU, dengan satu kasus khusus: kita selalu menyimpan
+	// item untuk (epoch tertinggi yang pernah dilihat) + 1 sebagai 'item masa depan'.
+	cache      *simplelru.LRU
+	future     uint64
+	futureItem interface{}
+}
+
+// newlru membuat cache least-recently-used baru untuk cache verifikasi atau
+// dataset penambangan.
+func newlru(what string, maxItems int, new func(epoch uint64) interface{}) *lru {
+	if maxItems <= 0 {
+		maxItems = 1
+	}
+	cache, _ := simplelru.NewLRU(maxItems, func(key, value interface{}) {
+		log.Trace("Evicted ethash "+what, "epoch", key)
+	})
+	return &lru{what: what, new: new, cache: cache}
+}
+
+// get mengambil atau membuat item untuk epoch tertentu. Nilai kembalian pertama
+// selalu tidak nil. Nilai kembalian kedua tidak nil jika lru menganggap sebuah item
+// akan berguna dalam waktu dekat.
+func (lru *lru) get(epoch uint64) (item, future interface{}) {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+
+	// Ambil atau buat item untuk epoch yang diminta
+	item, ok := lru.cache.Get(epoch)
+	if !ok {
+		if lru.future > 0 && lru.future == epoch {
+			item = lru.futureItem
+		} else {
+			log.Trace("Requiring new ethash "+lru.what, "epoch", epoch)
+			item = lru.new(epoch)
+		}
+		lru.cache.Add(epoch, item)
+	}
+	// Perbarui 'item masa depan' jika epoch lebih besar dari yang pernah dilihat
+	if lru.future < epoch+1 {
+		log.Trace("Requiring new future ethash "+lru.what, "epoch", epoch+1)
+		future = lru.new(epoch + 1)
+		lru.future = epoch + 1
+		lru.futureItem = future
+	}
+	return item, future
+}
+
+// cache membungkus cache ethash dengan sedikit metadata agar mudah dipakai bersamaan.
+type cache struct {
+	epoch uint64    // Epoch tempat cache ini berlaku
+	cache []uint32  // Isi data cache
+	once  sync.Once // Memastikan cache hanya dibuat sekali
+}
+
+// newCache membuat cache verifikasi ethash baru dan mengembalikannya sebagai
+// interface Go biasa agar bisa disimpan dalam cache LRU.
+func newCache(epoch uint64) interface{} {
+	return &cache{epoch: epoch}
+}
+
+// generate memastikan isi cache sudah dibuat sebelum dipakai.
+func (c *cache) generate(test bool) {
+	c.once.Do(func() {
+		size := cacheSize(c.epoch*epochLength + 1)
+		seed := seedHash(c.epoch*epochLength + 1)
+		if test {
+			size = 1024
+		}
+		c.cache = make([]uint32, size/4)
+		generateCache(c.cache, c.epoch, seed)
+	})
+}
+
+// dataset membungkus dataset ethash dengan sedikit metadata agar mudah dipakai bersamaan.
+type dataset struct {
+	epoch   uint64     // Epoch tempat dataset ini berlaku
+	dataset []uint32   // Isi data dataset
+	once    sync.Once  // Memastikan dataset hanya dibuat sekali
+	done    bool       // Apakah pembuatan dataset sudah selesai
+	lock    sync.Mutex // Melindungi field dataset dan done
+}
+
+// newDataset membuat dataset penambangan ethash baru dan mengembalikannya sebagai
+// interface Go biasa agar bisa disimpan dalam cache LRU.
+func newDataset(epoch uint64) interface{} {
+	return &dataset{epoch: epoch}
+}
+
+// generate memastikan isi dataset sudah dibuat sebelum dipakai.
+func (d *dataset) generate(test bool) {
+	d.once.Do(func() {
+		csize := cacheSize(d.epoch*epochLength + 1)
+		dsize := datasetSize(d.epoch*epochLength + 1)
+		seed := seedHash(d.epoch*epochLength + 1)
+		if test {
+			csize = 1024
+			dsize = 32 * 1024
+		}
+		cache := make([]uint32, csize/4)
+		generateCache(cache, d.epoch, seed)
+
+		dataset := make([]uint32, dsize/4)
+		generateDataset(dataset, d.epoch, cache)
+
+		d.lock.Lock()
+		d.dataset, d.done = dataset, true
+		d.lock.Unlock()
+	})
+}
+
+// generated mengembalikan apakah dataset ini sudah selesai dibuat atau belum.
+func (d *dataset) generated() bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.done
+}
+
+// Mode menentukan jenis dan jumlah verifikasi PoW yang dilakukan mesin ethash.
+type Mode uint
+
+const (
+	ModeNormal Mode = iota
+	ModeTest
+	ModeFake
+)
+
+// Config adalah parameter konfigurasi dari ethash.
+type Config struct {
+	CachesInMem   int  // Jumlah cache verifikasi yang disimpan di memori
+	DatasetsInMem int  // Jumlah dataset penambangan yang disimpan di memori
+	PowMode       Mode // Jenis verifikasi PoW yang dilakukan
+	VerifyWorkers int  // Batas pekerja verifikasi header paralel, nol berarti GOMAXPROCS
+
+	// Rules adalah aturan khusus chain yang diverifikasi mesin ini. Aturan kosong
+	// berarti mengikuti jadwal fork di ChainConfig.
+	Rules ChainRules
+
+	// Hashers adalah backend pencarian nonce tambahan, misalnya GPU, yang dijalankan
+	// bersama thread CPU setiap kali blok disegel.
+	Hashers []Hasher `toml:"-"`
+
+	Log log.Logger `toml:"-"`
+}
+
+// Ethash adalah mesin konsensus berdasarkan bukti kerja yang mengimplementasikan
+// algoritma ethash.
+type Ethash struct {
+	consensus.NoFinality // Mesin ini tidak memiliki finalitas
+
+	config   Config
+	rules    *chainRules // Aturan chain yang sudah diresolusi dari config.Rules
+	rulesErr error       // Error resolusi aturan, dikembalikan saat verifikasi
+
+	caches   *lru // Cache di memori agar tidak terlalu sering dibuat ulang
+	datasets *lru // Dataset di memori agar tidak terlalu sering dibuat ulang
+
+	// Field yang berkaitan dengan penambangan
+	rand     *rand.Rand    // Sumber acak untuk nonce
+	threads  int           // Jumlah thread penambangan jika sedang menambang
+	update   chan struct{} // Saluran notifikasi untuk memperbarui parameter penambangan
+	hashrate metrics.Meter // Meter yang melacak rata-rata hashrate
+
+	remote    *remoteSealer            // Penyegel untuk penambang eksternal lewat RPC
+	ancestors *consensus.AncestorIndex // Indeks leluhur untuk memeriksa garis keturunan paman
+
+	lock      sync.Mutex // Menjaga keamanan thread untuk field penambangan
+	closeOnce sync.Once  // Memastikan penyegel remote hanya dihentikan sekali
+}
+
+// New membuat skema PoW ethash berukuran penuh. Aturan chain di config diresolusi
+// sekali di sini. Jika aturan tidak valid, mesin menolak semua header dan blok
+// dengan error tersebut, jadi pemanggil sebaiknya memeriksanya lebih dulu lewat
+// ChainRules.Validate.
+func New(config Config) *Ethash {
+	if config.Log == nil {
+		config.Log = log.Root()
+	}
+	if config.CachesInMem <= 0 {
+		config.Log.Warn("One ethash cache must always be in memory", "requested", config.CachesInMem)
+		config.CachesInMem = 1
+	}
+	rules, err := config.Rules.parse()
+	if err != nil {
+		config.Log.Error("Invalid ethash chain rules", "err", err)
+		rules = new(chainRules)
+	}
+	ethash := &Ethash{
+		config:    config,
+		rules:     rules,
+		rulesErr:  err,
+		caches:    newlru("cache", config.CachesInMem, newCache),
+		datasets:  newlru("dataset", config.DatasetsInMem, newDataset),
+		update:    make(chan struct{}),
+		hashrate:  metrics.NewMeterForced(),
+		ancestors: consensus.NewAncestorIndex(0),
+	}
+	ethash.remote = startRemoteSealer(ethash)
+	return ethash
+}
+
+// NewTester membuat skema PoW ethash berukuran kecil yang hanya berguna untuk
+// keperluan pengujian.
+func NewTester() *Ethash {
+	return New(Config{PowMode: ModeTest})
+}
+
+// NewFaker membuat mesin konsensus ethash dengan skema PoW palsu yang menerima
+// semua segel blok sebagai valid, meskipun blok tetap harus mengikuti aturan
+// konsensus Ethereum lainnya.
+func NewFaker() *Ethash {
+	return New(Config{PowMode: ModeFake})
+}
+
+// cache mencoba mengambil cache verifikasi untuk nomor blok tertentu dari daftar
+// cache di memori, dan membuatnya jika belum ada.
+func (ethash *Ethash) cache(block uint64) *cache {
+	epoch := block / epochLength
+	currentI, futureI := ethash.caches.get(epoch)
+	current := currentI.(*cache)
+
+	// Tunggu sampai pembuatan selesai
+	current.generate(ethash.config.PowMode == ModeTest)
+
+	// Jika kita butuh cache masa depan, sekarang saat yang tepat untuk membuatnya
+	if futureI != nil {
+		future := futureI.(*cache)
+		go future.generate(ethash.config.PowMode == ModeTest)
+	}
+	return current
+}
+
+// dataset mencoba mengambil dataset penambangan untuk nomor blok tertentu dari
+// daftar dataset di memori, dan membuatnya jika belum ada.
+//
+// Jika async diminta, dataset saat ini juga dibuat di thread latar belakang.
+func (ethash *Ethash) dataset(block uint64, async bool) *dataset {
+	epoch := block / epochLength
+	currentI, futureI := ethash.datasets.get(epoch)
+	current := currentI.(*dataset)
+
+	test := ethash.config.PowMode == ModeTest
+	if async && !current.generated() {
+		go func() {
+			current.generate(test)
+
+			if futureI != nil {
+				futureI.(*dataset).generate(test)
+			}
+		}()
+	} else {
+		// Pembuatan blocking diminta, atau sudah selesai
+		current.generate(test)
+
+		if futureI != nil {
+			go futureI.(*dataset).generate(test)
+		}
+	}
+	return current
+}
+
+// Threads mengembalikan jumlah thread penambangan yang sedang diaktifkan. Ini tidak
+// berarti penambangan sedang berjalan.
+func (ethash *Ethash) Threads() int {
+	ethash.lock.Lock()
+	defer ethash.lock.Unlock()
+
+	return ethash.threads
+}
+
+// SetThreads mengimplementasikan PoW, memperbarui jumlah thread penambangan yang
+// dipakai. Jika sedang menambang, semua thread penambang dimulai ulang dengan jumlah
+// baru. Nol berarti memakai semua CPU, negatif berarti penambangan lokal dimatikan.
+func (ethash *Ethash) SetThreads(threads int) {
+	ethash.lock.Lock()
+	defer ethash.lock.Unlock()
+
+	ethash.threads = threads
+	select {
+	case ethash.update <- struct{}{}:
+	default:
+	}
+}
+
+// SubmitHashrate mengimplementasikan PoW, mencatat hashrate yang dilaporkan oleh
+// penambang remote dengan identitas unik id. Hashrate yang tidak diperbarui selama
+// 10 detik dibuang dari total.
+func (ethash *Ethash) SubmitHashrate(id common.Hash, rate uint64) bool {
+	if ethash.remote == nil {
+		return false
+	}
+	done := make(chan struct{}, 1)
+	select {
+	case ethash.remote.submitRateCh <- &hashrate{done: done, rate: rate, id: id}:
+	case <-ethash.remote.exitCh:
+		return false
+	}
+	// Tunggu sampai hashrate selesai dicatat
+	<-done
+	return true
+}
+
+// Hashrate mengimplementasikan PoW, mengembalikan total hashrate dari semua
+// backend, yaitu thread CPU, backend Hasher tambahan dan penambang remote.
+func (ethash *Ethash) Hashrate() float64 {
+	var total float64
+	for _, rate := range ethash.Hashrates() {
+		total += rate
+	}
+	return total
+}
+
+// Hashrates mengembalikan hashrate setiap backend berdasarkan namanya: "cpu" untuk
+// laju thread lokal selama satu menit terakhir, nama setiap Hasher tambahan, dan
+// "remote" untuk total yang dilaporkan penambang remote selama mesin berjalan.
+func (ethash *Ethash) Hashrates() map[string]float64 {
+	rates := map[string]float64{"cpu": ethash.hashrate.Rate1()}
+	for _, hasher := range ethash.config.Hashers {
+		rates[hasher.Name()] += hasher.Hashrate()
+	}
+	if ethash.remote == nil {
+		return rates
+	}
+	res := make(chan uint64, 1)
+	select {
+	case ethash.remote.fetchRateCh <- res:
+		rates["remote"] = float64(<-res)
+	case <-ethash.remote.exitCh:
+		// Hashrate remote tidak tersedia lagi jika ethash sudah dihentikan
+	}
+	return rates
+}
+
+// APIs mengimplementasikan consensus.Engine, mengembalikan API RPC untuk penambang
+// remote dan perkiraan konfirmasi blok. API penambang disediakan di namespace eth
+// dan ethash demi kompatibilitas, sedangkan API konfirmasi hanya di namespace ethash.
+func (ethash *Ethash) APIs(chain consensus.ChainHeaderReader) []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "eth",
+			Service:   &API{ethash},
+		},
+		{
+			Namespace: "ethash",
+			Service:   &API{ethash},
+		},
+		{
+			Namespace: "ethash",
+			Service:   &ConfirmationAPI{chain: chain},
+		},
+	}
+}
+
+// Close mengimplementasikan consensus.Engine, menghentikan penyegel remote.
+func (ethash *Ethash) Close() error {
+	ethash.closeOnce.Do(func() {
+		if ethash.remote == nil {
+			return
+		}
+		close(ethash.remote.requestExit)
+		<-ethash.remote.exitCh
+	})
+	return nil
+}
+
+// SeedHash adalah seed yang dipakai untuk membuat cache verifikasi dan dataset
+// penambangan.
+func SeedHash(block uint64) []byte {
+	return seedHash(block)
+}
@@ -0,0 +1,138 @@
+package ethash
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Work adalah paket pekerjaan yang diberikan ke backend Hasher, berisi data yang
+// sama dengan paket pekerjaan penambang remote.
+type Work struct {
+	Number   uint64      // Nomor blok yang ditambang, menentukan epoch DAG
+	SealHash common.Hash // Seal hash header blok
+	SeedHash common.Hash // Seed hash yang dipakai untuk DAG
+	Target   *big.Int    // Batas target, yaitu 2^256/difficulty
+}
+
+// Solution adalah nonce dan mix digest yang ditemukan backend Hasher untuk sebuah
+// paket pekerjaan.
+type Solution struct {
+	SealHash  common.Hash      // Seal hash pekerjaan yang diselesaikan
+	Nonce     types.BlockNonce // Nonce yang memenuhi target
+	MixDigest common.Hash      // Mix digest hasil hashimoto untuk nonce tersebut
+}
+
+// Hasher adalah backend pencarian nonce ethash, misalnya penambang GPU atau
+// perangkat eksternal bergaya stratum. Mesin menjalankan semua backend bersamaan
+// dengan thread CPU dan penambang remote, memverifikasi setiap solusi, lalu
+// memakai solusi valid pertama.
+type Hasher interface {
+	// Name mengembalikan nama unik backend untuk log dan laporan hashrate.
+	Name() string
+
+	// Search mencari nonce untuk pekerjaan yang diberikan dan mengirim solusinya ke
+	// found, sampai abort ditutup. Search harus kembali setelah abort ditutup dan
+	// tidak boleh mengirim ke found tanpa ikut memilih abort.
+	Search(work *Work, abort <-chan struct{}, found chan<- *Solution)
+
+	// Hashrate mengembalikan laju pencarian backend dalam hash per detik.
+	Hashrate() float64
+}
+
+// newWork membuat paket pekerjaan untuk blok yang diberikan.
+func (ethash *Ethash) newWork(block *types.Block) *Work {
+	return &Work{
+		Number:   block.NumberU64(),
+		SealHash: ethash.SealHash(block.Header()),
+		SeedHash: common.BytesToHash(SeedHash(block.NumberU64())),
+		Target:   new(big.Int).Div(two256, block.Difficulty()),
+	}
+}
+
+// cpuHasher adalah backend Hasher bawaan yang menambang dengan DAG penuh di thread
+// CPU lokal, satu thread untuk setiap seed.
+type cpuHasher struct {
+	ethash *Ethash
+	seeds  []uint64 // Nonce awal setiap thread
+}
+
+// Name mengimplementasikan Hasher.
+func (h *cpuHasher) Name() string {
+	return "cpu"
+}
+
+// Hashrate mengimplementasikan Hasher, mengembalikan laju pencarian per detik
+// selama satu menit terakhir dari semua thread CPU.
+func (h *cpuHasher) Hashrate() float64 {
+	return h.ethash.hashrate.Rate1()
+}
+
+// Search mengimplementasikan Hasher, menjalankan satu thread pencarian untuk setiap
+// seed dan menunggu semuanya selesai.
+func (h *cpuHasher) Search(work *Work, abort <-chan struct{}, found chan<- *Solution) {
+	var pend sync.WaitGroup
+	for i, seed := range h.seeds {
+		pend.Add(1)
+		go func(id int, seed uint64) {
+			defer pend.Done()
+			h.mine(work, id, seed, abort, found)
+		}(i, seed)
+	}
+	pend.Wait()
+}
+
+// mine adalah penambang proof-of-work sesungguhnya yang mencari nonce mulai dari
+// seed yang menghasilkan kesulitan akhir blok yang benar.
+func (h *cpuHasher) mine(work *Work, id int, seed uint64, abort <-chan struct{}, found chan<- *Solution) {
+	var (
+		hash    = work.SealHash.Bytes()
+		dataset = h.ethash.dataset(work.Number, false)
+	)
+	// Mulai mencoba nonce sampai dihentikan atau menemukan yang cocok
+	var (
+		attempts  = int64(0)
+		nonce     = seed
+		powBuffer = new(big.Int)
+	)
+	logger := h.ethash.config.Log.New("miner", id)
+	logger.Trace("Started ethash search for new nonces", "seed", seed)
+search:
+	for {
+		select {
+		case <-abort:
+			// Penambangan dihentikan, perbarui statistik dan keluar
+			logger.Trace("Ethash nonce search aborted", "attempts", nonce-seed)
+			h.ethash.hashrate.Mark(attempts)
+			break search
+
+		default:
+			// Hashrate tidak perlu diperbarui di setiap nonce, cukup setiap 2^X nonce
+			attempts++
+			if (attempts % (1 << 15)) == 0 {
+				h.ethash.hashrate.Mark(attempts)
+				attempts = 0
+			}
+			// Hitung nilai PoW dari nonce ini
+			digest, result := hashimotoFull(dataset.dataset, hash, nonce)
+			if powBuffer.SetBytes(result).Cmp(work.Target) <= 0 {
+				// Nonce yang benar ditemukan, kirim solusinya (jika masih dibutuhkan)
+				solution := &Solution{
+					SealHash:  work.SealHash,
+					Nonce:     types.EncodeNonce(nonce),
+					MixDigest: common.BytesToHash(digest),
+				}
+				select {
+				case found <- solution:
+					logger.Trace("Ethash nonce found and reported", "attempts", nonce-seed, "nonce", nonce)
+				case <-abort:
+					logger.Trace("Ethash nonce found but discarded", "attempts", nonce-seed, "nonce", nonce)
+				}
+				break search
+			}
+			nonce++
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package ethash
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// testHasher adalah backend Hasher yang mencari nonce dengan cache ringan mode tes,
+// atau mengirim solusi palsu jika bogus bernilai true.
+type testHasher struct {
+	ethash *Ethash
+	name   string
+	rate   float64
+	bogus  bool
+}
+
+func (h *testHasher) Name() string      { return h.name }
+func (h *testHasher) Hashrate() float64 { return h.rate }
+
+func (h *testHasher) Search(work *Work, abort <-chan struct{}, found chan<- *Solution) {
+	solution := &Solution{SealHash: work.SealHash, MixDigest: common.Hash{1}}
+	if !h.bogus {
+		cache := h.ethash.cache(work.Number)
+		for nonce := uint64(0); ; nonce++ {
+			digest, result := hashimotoLight(32*1024, cache.cache, work.SealHash.Bytes(), nonce)
+			if new(big.Int).SetBytes(result).Cmp(work.Target) <= 0 {
+				solution.Nonce, solution.MixDigest = types.EncodeNonce(nonce), common.BytesToHash(digest)
+				break
+			}
+		}
+	}
+	select {
+	case found <- solution:
+	case <-abort:
+	}
+	<-abort
+}
+
+// Menguji bahwa backend Hasher tambahan bisa menyegel blok tanpa thread CPU,
+// solusi yang tidak valid diabaikan, dan hashrate setiap backend dilaporkan dan
+// dijumlahkan ke Hashrate.
+func TestSealHasher(t *testing.T) {
+	var (
+		good  = &testHasher{name: "gpu", rate: 100}
+		bogus = &testHasher{name: "broken", rate: 20, bogus: true}
+	)
+	engine := New(Config{PowMode: ModeTest, Hashers: []Hasher{bogus, good}})
+	defer engine.Close()
+	good.ethash, bogus.ethash = engine, engine
+	engine.SetThreads(-1)
+
+	header := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(100)}
+	results := make(chan *types.Block)
+	if err := engine.Seal(nil, types.NewBlockWithHeader(header), results, nil); err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	select {
+	case block := <-results:
+		if err := engine.verifySeal(nil, block.Header(), false); err != nil {
+			t.Fatalf("sealed header rejected: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("sealing result timeout")
+	}
+	rates := engine.Hashrates()
+	if rates["gpu"] != 100 || rates["broken"] != 20 {
+		t.Errorf("backend hashrates mismatch: have %v", rates)
+	}
+	if have := engine.Hashrate(); have < 120 {
+		t.Errorf("total hashrate mismatch: have %v, want at least %v", have, 120)
+	}
+}
+
+// Menguji bahwa blok tidak disegel jika semua backend hanya mengirim solusi yang
+// tidak valid.
+func TestSealHasherInvalid(t *testing.T) {
+	bogus := &testHasher{name: "broken", bogus: true}
+	engine := New(Config{PowMode: ModeTest, Hashers: []Hasher{bogus}})
+	defer engine.Close()
+	bogus.ethash = engine
+	engine.SetThreads(-1)
+
+	header := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(100)}
+	results := make(chan *types.Block)
+	stop := make(chan struct{})
+	defer close(stop)
+	if err := engine.Seal(nil, types.NewBlockWithHeader(header), results, stop); err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	select {
+	case block := <-results:
+		t.Fatalf("invalid solution sealed block %x", block.Hash())
+	case <-time.After(200 * time.Millisecond):
+	}
+}
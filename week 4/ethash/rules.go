@@ -0,0 +1,176 @@
+package ethash
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Berbagai pesan error untuk aturan chain yang tidak valid.
+var (
+	errUnorderedSchedule  = errors.New("fork schedule not strictly ascending")
+	errNegativeUncleLimit = errors.New("negative uncle limit")
+)
+
+// DifficultyFork menjadwalkan satu strategi kesulitan mulai dari blok tertentu.
+type DifficultyFork struct {
+	Block    uint64               // Nomor blok pertama yang memakai strategi ini
+	Strategy string               // Nama strategi bawaan, diabaikan jika Calc diisi
+	Calc     DifficultyCalculator `toml:"-"` // Strategi khusus, misalnya dari BombDifficulty
+}
+
+// UncleFork menjadwalkan satu policy paman mulai dari blok tertentu.
+type UncleFork struct {
+	Block  uint64      // Nomor blok pertama yang memakai policy ini
+	Policy UnclePolicy // Policy paman, field yang tidak diisi memakai nilai mainnet
+}
+
+// UnclePolicy adalah parameter validasi dan reward paman. Field yang tidak diisi
+// (nil atau nol) diganti dengan nilai mainnet saat aturan diresolusi di New. Batas
+// jumlah dan jarak berupa pointer agar nol yang diisi eksplisit tetap berlaku,
+// misalnya MaxUncles nol untuk chain yang sama sekali tidak menerima paman.
+type UnclePolicy struct {
+	MaxUncles *int // Jumlah maksimum paman dalam satu blok (mainnet 2)
+	MaxDepth  *int // Jarak maksimum paman dari blok yang memasukkannya (mainnet 7)
+
+	// UncleRewardDenominator menentukan reward pembuat paman, yaitu
+	// (nomor paman + D - nomor blok) * block reward / D (mainnet 8). Paman yang
+	// terlalu jauh untuk rumus ini tidak mendapat reward.
+	UncleRewardDenominator uint64
+
+	// InclusionRewardDenominator menentukan reward tambahan bagi pembuat blok untuk
+	// setiap paman yang dimasukkan, yaitu block reward / D (mainnet 32).
+	InclusionRewardDenominator uint64
+}
+
+// unclePolicy adalah UnclePolicy yang sudah diresolusi, tanpa field kosong.
+type unclePolicy struct {
+	maxUncles                  int
+	maxDepth                   int
+	uncleRewardDenominator     uint64
+	inclusionRewardDenominator uint64
+}
+
+// resolve memvalidasi policy dan mengisi field kosong dengan nilai mainnet.
+func (p UnclePolicy) resolve() (unclePolicy, error) {
+	policy := unclePolicy{
+		maxUncles:                  maxUncles,
+		maxDepth:                   maxUncleDepth,
+		uncleRewardDenominator:     uncleRewardDenominator,
+		inclusionRewardDenominator: inclusionRewardDenominator,
+	}
+	if p.MaxUncles != nil {
+		if *p.MaxUncles < 0 {
+			return unclePolicy{}, fmt.Errorf("%w: max uncles %d", errNegativeUncleLimit, *p.MaxUncles)
+		}
+		policy.maxUncles = *p.MaxUncles
+	}
+	if p.MaxDepth != nil {
+		if *p.MaxDepth < 0 {
+			return unclePolicy{}, fmt.Errorf("%w: max depth %d", errNegativeUncleLimit, *p.MaxDepth)
+		}
+		policy.maxDepth = *p.MaxDepth
+	}
+	if p.UncleRewardDenominator != 0 {
+		policy.uncleRewardDenominator = p.UncleRewardDenominator
+	}
+	if p.InclusionRewardDenominator != 0 {
+		policy.inclusionRewardDenominator = p.InclusionRewardDenominator
+	}
+	return policy, nil
+}
+
+// ChainRules adalah aturan ethash khusus sebuah chain yang tidak bisa dinyatakan
+// lewat params.ChainConfig. Aturan dibawa oleh Config setiap mesin dan diresolusi
+// sekali di New, sehingga tidak ada state global yang bisa berubah setelah mesin
+// mulai memverifikasi. Sebelum fork pertama di jadwal, chain mengikuti jadwal fork
+// mainnet di ChainConfig.
+type ChainRules struct {
+	Difficulty []DifficultyFork // Jadwal strategi kesulitan, urut naik menurut blok
+	Uncles     []UncleFork      // Jadwal policy paman, urut naik menurut blok
+}
+
+// chainRules adalah ChainRules yang sudah divalidasi, dengan nama strategi yang
+// sudah diganti dengan kalkulatornya.
+type chainRules struct {
+	difficulty []difficultyFork
+	uncles     []uncleFork
+}
+
+// difficultyFork adalah DifficultyFork yang sudah divalidasi.
+type difficultyFork struct {
+	block uint64
+	calc  DifficultyCalculator
+}
+
+// uncleFork adalah UncleFork yang sudah diresolusi.
+type uncleFork struct {
+	block  uint64
+	policy unclePolicy
+}
+
+// Validate memeriksa aturan tanpa membuat mesin, sehingga pemanggil bisa menolak
+// konfigurasi yang salah sebelum memanggil New. Error dikembalikan jika jadwal
+// tidak urut naik, ada nama strategi yang tidak dikenal atau batas paman negatif.
+func (r ChainRules) Validate() error {
+	_, err := r.parse()
+	return err
+}
+
+// parse memvalidasi aturan dan mengganti nama strategi dengan kalkulatornya.
+func (r ChainRules) parse() (*chainRules, error) {
+	parsed := new(chainRules)
+	for i, fork := range r.Difficulty {
+		if i > 0 && fork.Block <= r.Difficulty[i-1].Block {
+			return nil, fmt.Errorf("%w: difficulty fork %d at block %d", errUnorderedSchedule, i, fork.Block)
+		}
+		calc := fork.Calc
+		if calc == nil {
+			var err error
+			if calc, err = DifficultyStrategy(fork.Strategy); err != nil {
+				return nil, err
+			}
+		}
+		parsed.difficulty = append(parsed.difficulty, difficultyFork{block: fork.Block, calc: calc})
+	}
+	for i, fork := range r.Uncles {
+		if i > 0 && fork.Block <= r.Uncles[i-1].Block {
+			return nil, fmt.Errorf("%w: uncle fork %d at block %d", errUnorderedSchedule, i, fork.Block)
+		}
+		policy, err := fork.Policy.resolve()
+		if err != nil {
+			return nil, fmt.Errorf("uncle fork %d: %w", i, err)
+		}
+		parsed.uncles = append(parsed.uncles, uncleFork{block: fork.Block, policy: policy})
+	}
+	return parsed, nil
+}
+
+// difficultyAt mengembalikan kalkulator kesulitan yang aktif pada nomor blok
+// tertentu, dari jadwal aturan jika sudah dimulai atau dari jadwal fork di
+// konfigurasi chain.
+func (r *chainRules) difficultyAt(config *params.ChainConfig, number *big.Int) DifficultyCalculator {
+	for i := len(r.difficulty) - 1; i >= 0; i-- {
+		if r.difficulty[i].block <= number.Uint64() {
+			return r.difficulty[i].calc
+		}
+	}
+	// Strategi bawaan selalu ada, jadi pencarian ini tidak pernah gagal
+	calc, _ := DifficultyStrategy(ForkDifficulty(config, number))
+	return calc
+}
+
+// unclesAt mengembalikan policy paman yang aktif pada nomor blok tertentu, dari
+// jadwal aturan jika sudah dimulai atau policy mainnet.
+func (r *chainRules) unclesAt(number uint64) unclePolicy {
+	for i := len(r.uncles) - 1; i >= 0; i-- {
+		if r.uncles[i].block <= number {
+			return r.uncles[i].policy
+		}
+	}
+	// Policy kosong selalu valid, jadi resolusi ini tidak pernah gagal
+	policy, _ := UnclePolicy{}.resolve()
+	return policy
+}
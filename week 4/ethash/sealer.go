@@ -0,0 +1,345 @@
+package ethash
+
+import (
+	crand "crypto/rand"
+	"errors"
+	"math"
+	"math/big"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// staleThreshold adalah kedalaman maksimum solusi ethash basi yang masih diterima.
+	staleThreshold = 7
+)
+
+var (
+	errNoMiningWork      = errors.New("no mining work available yet")
+	errInvalidSealResult = errors.New("invalid or stale proof-of-work solution")
+)
+
+// Seal mengimplementasikan consensus.Engine, mencoba menemukan nonce yang memenuhi
+// persyaratan kesulitan blok.
+func (ethash *Ethash) Seal(chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
+	// Jika kita menjalankan PoW palsu, langsung kembalikan nonce 0
+	if ethash.config.PowMode == ModeFake {
+		header := block.Header()
+		header.Nonce, header.MixDigest = types.BlockNonce{}, common.Hash{}
+		select {
+		case results <- block.WithSeal(header):
+		default:
+			ethash.config.Log.Warn("Sealing result is not read by miner", "mode", "fake", "sealhash", ethash.SealHash(block.Header()))
+		}
+		return nil
+	}
+	// Buat runner dan beberapa thread pencarian yang diarahkannya
+	abort := make(chan struct{})
+
+	ethash.lock.Lock()
+	if ethash.rand == nil {
+		seed, err := crand.Int(crand.Reader, big.NewInt(math.MaxInt64))
+		if err != nil {
+			ethash.lock.Unlock()
+			return err
+		}
+		ethash.rand = rand.New(rand.NewSource(seed.Int64()))
+	}
+	threads := ethash.threads
+	ethash.lock.Unlock()
+
+	// Serahkan pekerjaan ke penyegel remote agar penambang eksternal bisa ikut mencari
+	if ethash.remote != nil {
+		select {
+		case ethash.remote.workCh <- &sealTask{block: block, results: results}:
+		case <-ethash.remote.exitCh:
+		}
+	}
+	if threads == 0 {
+		threads = runtime.NumCPU()
+	}
+	// Thread negatif mematikan penambangan CPU, hanya backend lain dan penambang
+	// remote yang bekerja
+	var hashers []Hasher
+	if threads > 0 {
+		cpu := &cpuHasher{ethash: ethash, seeds: make([]uint64, threads)}
+		for i := range cpu.seeds {
+			cpu.seeds[i] = uint64(ethash.rand.Int63())
+		}
+		hashers = append(hashers, cpu)
+	}
+	hashers = append(hashers, ethash.config.Hashers...)
+
+	var (
+		pend   sync.WaitGroup
+		work   = ethash.newWork(block)
+		locals = make(chan *Solution)
+	)
+	for _, hasher := range hashers {
+		pend.Add(1)
+		go func(hasher Hasher) {
+			defer pend.Done()
+			hasher.Search(work, abort, locals)
+		}(hasher)
+	}
+	// Tunggu sampai penyegelan dihentikan atau solusi valid ditemukan
+	go func() {
+		defer pend.Wait() // Tunggu semua backend selesai
+		for {
+			select {
+			case <-stop:
+				// Dihentikan dari luar, hentikan semua backend
+				close(abort)
+				return
+
+			case solution := <-locals:
+				// Backend tidak dipercaya, jadi solusi diverifikasi seperti solusi remote
+				result, err := ethash.sealSolution(block, work, solution)
+				if err != nil {
+					ethash.config.Log.Warn("Invalid proof-of-work from hasher", "sealhash", solution.SealHash, "err", err)
+					continue
+				}
+				select {
+				case results <- result:
+				default:
+					ethash.config.Log.Warn("Sealing result is not read by miner", "mode", "local", "sealhash", work.SealHash)
+				}
+				close(abort)
+				return
+
+			case <-ethash.update:
+				// Jumlah thread diubah oleh pengguna, mulai ulang semua backend
+				close(abort)
+				if err := ethash.Seal(chain, block, results, stop); err != nil {
+					ethash.config.Log.Error("Failed to restart sealing after update", "err", err)
+				}
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// sealSolution memverifikasi solusi dari backend Hasher untuk pekerjaan yang
+// diberikan dan mengembalikan blok yang sudah disegel.
+func (ethash *Ethash) sealSolution(block *types.Block, work *Work, solution *Solution) (*types.Block, error) {
+	if solution.SealHash != work.SealHash {
+		return nil, errInvalidSealResult
+	}
+	header := block.Header()
+	header.Nonce, header.MixDigest = solution.Nonce, solution.MixDigest
+	if err := ethash.verifySeal(nil, header, true); err != nil {
+		return nil, err
+	}
+	return block.WithSeal(header), nil
+}
+
+// remoteSealer melayani penambang eksternal yang mengambil pekerjaan dan mengirim
+// solusi PoW lewat RPC.
+type remoteSealer struct {
+	works        map[common.Hash]*types.Block // Blok yang sedang ditambang, berdasarkan seal hash
+	rates        map[common.Hash]hashrate     // Hashrate terakhir dari setiap penambang remote
+	currentBlock *types.Block                 // Blok terbaru yang diserahkan untuk disegel
+	currentWork  [4]string                    // Paket pekerjaan untuk blok terbaru
+
+	ethash  *Ethash
+	results chan<- *types.Block // Saluran hasil dari permintaan penyegelan terbaru
+
+	workCh       chan *sealTask   // Saluran untuk menyerahkan pekerjaan baru beserta saluran hasilnya
+	fetchWorkCh  chan *sealWork   // Saluran bagi penambang remote untuk mengambil pekerjaan
+	submitWorkCh chan *mineResult // Saluran bagi penambang remote untuk mengirim solusi
+	fetchRateCh  chan chan uint64 // Saluran untuk mengambil total hashrate yang dilaporkan
+	submitRateCh chan *hashrate   // Saluran bagi penambang remote untuk melaporkan hashrate
+	requestExit  chan struct{}
+	exitCh       chan struct{}
+}
+
+// sealTask membungkus blok yang akan disegel beserta saluran hasilnya.
+type sealTask struct {
+	block   *types.Block
+	results chan<- *types.Block
+}
+
+// mineResult membungkus solusi PoW untuk blok tertentu.
+type mineResult struct {
+	nonce     types.BlockNonce
+	mixDigest common.Hash
+	hash      common.Hash
+
+	errc chan error
+}
+
+// hashrate membungkus hashrate yang dilaporkan oleh penambang remote.
+type hashrate struct {
+	id   common.Hash
+	ping time.Time
+	rate uint64
+
+	done chan struct{}
+}
+
+// sealWork membungkus permintaan paket pekerjaan dari penambang remote.
+type sealWork struct {
+	errc chan error
+	res  chan [4]string
+}
+
+// startRemoteSealer membuat penyegel remote dan menjalankan loop utamanya.
+func startRemoteSealer(ethash *Ethash) *remoteSealer {
+	s := &remoteSealer{
+		ethash:       ethash,
+		works:        make(map[common.Hash]*types.Block),
+		rates:        make(map[common.Hash]hashrate),
+		workCh:       make(chan *sealTask),
+		fetchWorkCh:  make(chan *sealWork),
+		submitWorkCh: make(chan *mineResult),
+		fetchRateCh:  make(chan chan uint64),
+		submitRateCh: make(chan *hashrate),
+		requestExit:  make(chan struct{}),
+		exitCh:       make(chan struct{}),
+	}
+	go s.loop()
+	return s
+}
+
+// loop melayani semua permintaan ke penyegel remote secara berurutan, sehingga
+// state pekerjaan tidak perlu dikunci.
+func (s *remoteSealer) loop() {
+	defer func() {
+		s.ethash.config.Log.Trace("Ethash remote sealer is exiting")
+		close(s.exitCh)
+	}()
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case work := <-s.workCh:
+			// Perbarui pekerjaan saat ini dengan blok baru
+			s.results = work.results
+			s.makeWork(work.block)
+
+		case work := <-s.fetchWorkCh:
+			// Kembalikan pekerjaan saat ini ke penambang remote
+			if s.currentBlock == nil {
+				work.errc <- errNoMiningWork
+			} else {
+				work.res <- s.currentWork
+			}
+
+		case result := <-s.submitWorkCh:
+			// Verifikasi solusi berdasarkan blok yang sedang ditambang
+			if s.submitWork(result.nonce, result.mixDigest, result.hash) {
+				result.errc <- nil
+			} else {
+				result.errc <- errInvalidSealResult
+			}
+
+		case result := <-s.submitRateCh:
+			// Catat hashrate yang dilaporkan penambang remote
+			s.rates[result.id] = hashrate{rate: result.rate, ping: time.Now()}
+			close(result.done)
+
+		case req := <-s.fetchRateCh:
+			// Jumlahkan semua hashrate yang dilaporkan penambang remote
+			var total uint64
+			for _, rate := range s.rates {
+				total += rate.rate
+			}
+			req <- total
+
+		case <-ticker.C:
+			// Buang hashrate dari penambang yang sudah lama tidak melapor
+			for id, rate := range s.rates {
+				if time.Since(rate.ping) > 10*time.Second {
+					delete(s.rates, id)
+				}
+			}
+			// Buang pekerjaan yang sudah terlalu basi
+			if s.currentBlock != nil {
+				for hash, block := range s.works {
+					if block.NumberU64()+staleThreshold <= s.currentBlock.NumberU64() {
+						delete(s.works, hash)
+					}
+				}
+			}
+
+		case <-s.requestExit:
+			return
+		}
+	}
+}
+
+// makeWork membuat paket pekerjaan untuk penambang eksternal.
+//
+// Paket pekerjaan terdiri dari 4 string:
+//
+//	result[0], 32 byte hex seal hash header blok saat ini
+//	result[1], 32 byte hex seed hash yang dipakai untuk DAG
+//	result[2], 32 byte hex batas target, yaitu 2^256/difficulty
+//	result[3], nomor blok dalam hex
+func (s *remoteSealer) makeWork(block *types.Block) {
+	hash := s.ethash.SealHash(block.Header())
+	s.currentWork[0] = hash.Hex()
+	s.currentWork[1] = common.BytesToHash(SeedHash(block.NumberU64())).Hex()
+	s.currentWork[2] = common.BytesToHash(new(big.Int).Div(two256, block.Difficulty()).Bytes()).Hex()
+	s.currentWork[3] = hexutil.EncodeBig(block.Number())
+
+	// Lacak pekerjaan agar solusi untuk blok sebelumnya masih bisa diterima
+	s.currentBlock = block
+	s.works[hash] = block
+}
+
+// submitWork memverifikasi solusi PoW yang dikirim dan mengembalikan apakah solusi
+// diterima. Solusi ditolak jika PoW salah, pekerjaan tidak dikenal, atau blok sudah
+// terlalu basi dibandingkan pekerjaan saat ini.
+func (s *remoteSealer) submitWork(nonce types.BlockNonce, mixDigest common.Hash, sealhash common.Hash) bool {
+	if s.currentBlock == nil {
+		s.ethash.config.Log.Error("Pending work without block", "sealhash", sealhash)
+		return false
+	}
+	// Pastikan pekerjaan yang dikirim memang ada
+	block := s.works[sealhash]
+	if block == nil {
+		s.ethash.config.Log.Warn("Work submitted but none pending", "sealhash", sealhash, "curnumber", s.currentBlock.NumberU64())
+		return false
+	}
+	// Verifikasi kebenaran solusi
+	header := block.Header()
+	header.Nonce = nonce
+	header.MixDigest = mixDigest
+
+	start := time.Now()
+	if err := s.ethash.verifySeal(nil, header, true); err != nil {
+		s.ethash.config.Log.Warn("Invalid proof-of-work submitted", "sealhash", sealhash, "elapsed", common.PrettyDuration(time.Since(start)), "err", err)
+		return false
+	}
+	if s.results == nil {
+		s.ethash.config.Log.Warn("Ethash result channel is empty, submitted mining result is rejected")
+		return false
+	}
+	s.ethash.config.Log.Trace("Verified correct proof-of-work", "sealhash", sealhash, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	// Solusi valid, tolak hanya jika bloknya sudah terlalu basi
+	solution := block.WithSeal(header)
+	if solution.NumberU64()+staleThreshold > s.currentBlock.NumberU64() {
+		select {
+		case s.results <- solution:
+			s.ethash.config.Log.Debug("Work submitted is acceptable", "number", solution.NumberU64(), "sealhash", sealhash, "hash", solution.Hash())
+			return true
+		default:
+			s.ethash.config.Log.Warn("Sealing result is not read by miner", "mode", "remote", "sealhash", sealhash)
+			return false
+		}
+	}
+	s.ethash.config.Log.Warn("Work submitted is too old", "number", solution.NumberU64(), "sealhash", sealhash, "hash", solution.Hash())
+	return false
+}
@@ -0,0 +1,265 @@
+package consensus
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// errMissingTd dikembalikan jika total kesulitan header yang dibandingkan tidak
+// tersedia di rantai.
+var errMissingTd = errors.New("missing total difficulty")
+
+// defaultGHOSTDepth adalah kedalaman pohon GHOST jika NewGHOST diberi depth nol.
+const defaultGHOSTDepth = 256
+
+// ForkChoice adalah aturan pemilihan head kanonik. Penyisipan rantai memanggil
+// ReorgNeeded untuk setiap header baru yang berhasil diverifikasi, sehingga mesin
+// konsensus atau transisi ke PoS bisa mengganti aturan tanpa mengubah rantai.
+type ForkChoice interface {
+	// ReorgNeeded mengembalikan apakah header harus menggantikan current sebagai
+	// head kanonik.
+	ReorgNeeded(chain ChainHeaderReader, current *types.Header, header *types.Header) (bool, error)
+}
+
+// HeaviestTD adalah aturan fork choice berdasarkan total kesulitan terbesar, seperti
+// yang dipakai rantai proof-of-work. Jika total kesulitannya sama, header dengan
+// nomor lebih rendah dipilih agar rantai tidak bisa diperpanjang dengan blok murah.
+type HeaviestTD struct{}
+
+// ReorgNeeded mengimplementasikan ForkChoice.
+func (HeaviestTD) ReorgNeeded(chain ChainHeaderReader, current *types.Header, header *types.Header) (bool, error) {
+	localTD := chain.GetTd(current.Hash(), current.Number.Uint64())
+	externTD := chain.GetTd(header.Hash(), header.Number.Uint64())
+	if localTD == nil || externTD == nil {
+		return false, errMissingTd
+	}
+	if cmp := externTD.Cmp(localTD); cmp != 0 {
+		return cmp > 0, nil
+	}
+	return header.Number.Uint64() < current.Number.Uint64(), nil
+}
+
+// LongestChain adalah aturan fork choice berdasarkan nomor blok tertinggi, cocok
+// untuk mesin dengan kesulitan tetap. Jika nomornya sama, head saat ini dipertahankan.
+type LongestChain struct{}
+
+// ReorgNeeded mengimplementasikan ForkChoice.
+func (LongestChain) ReorgNeeded(chain ChainHeaderReader, current *types.Header, header *types.Header) (bool, error) {
+	return header.Number.Uint64() > current.Number.Uint64(), nil
+}
+
+// ghostNode adalah satu header di pohon GHOST beserta bobot subtree-nya.
+type ghostNode struct {
+	parent common.Hash // Hash induk langsung
+	number uint64      // Nomor blok header
+	weight *big.Int    // Jumlah kesulitan header ini dan semua turunannya yang dikenal
+}
+
+// GHOST adalah aturan fork choice greedy heaviest observed subtree. Di setiap titik
+// percabangan, cabang dengan subtree terberat dipilih, sehingga blok paman ikut
+// menyumbang bobot ke cabangnya.
+//
+// Pohon header disimpan di memori dan hanya dibangun dari header yang dilewatkan ke
+// ReorgNeeded. Header yang dimasukkan ke rantai sebelum aturan ini dipasang tidak
+// menyumbang bobot, jadi pasang aturan sejak genesis atau terima bahwa percabangan
+// lama diputuskan oleh HeaviestTD. Pohon dipangkas otomatis sehingga ukurannya dan
+// jalan ke leluhur di setiap penyisipan dibatasi kedalaman pohon.
+type GHOST struct {
+	nodes     map[common.Hash]*ghostNode // Semua header di pohon, berdasarkan hash
+	ancestors *AncestorIndex             // Indeks leluhur untuk mencari titik percabangan
+	engine    Engine                     // Mesin yang menentukan header final, boleh nil
+	depth     uint64                     // Jumlah blok di bawah header tertinggi yang disimpan
+	highest   uint64                     // Nomor header tertinggi yang pernah dimasukkan
+	cutoff    uint64                     // Header di bawah nomor ini sudah dipangkas
+	lock      sync.Mutex                 // Melindungi pohon dari penyisipan bersamaan
+}
+
+// NewGHOST membuat aturan fork choice GHOST dengan pohon kosong. Header yang lebih
+// dari depth blok di bawah header tertinggi, atau di bawah header final engine jika
+// engine tidak nil, dipangkas otomatis. Percabangan di bawah batas tersebut
+// diputuskan oleh HeaviestTD. Depth nol berarti memakai kedalaman bawaan.
+func NewGHOST(engine Engine, depth uint64) *GHOST {
+	if depth == 0 {
+		depth = defaultGHOSTDepth
+	}
+	return &GHOST{
+		nodes:     make(map[common.Hash]*ghostNode),
+		ancestors: NewAncestorIndex(0),
+		engine:    engine,
+		depth:     depth,
+	}
+}
+
+// ReorgNeeded mengimplementasikan ForkChoice. Titik percabangan antara current dan
+// header dicari lewat indeks leluhur di rantai, lalu bobot subtree anak titik
+// percabangan di kedua cabang dibandingkan. Jika salah satu anak tersebut tidak ada
+// di pohon, misalnya setelah Prune, keputusan diserahkan ke HeaviestTD.
+func (g *GHOST) ReorgNeeded(chain ChainHeaderReader, current *types.Header, header *types.Header) (bool, error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.add(current)
+	g.add(header)
+	g.prune(chain)
+
+	var (
+		local, localNumber   = current.Hash(), current.Number.Uint64()
+		extern, externNumber = header.Hash(), header.Number.Uint64()
+	)
+	if local == extern {
+		return false, nil
+	}
+	// Header yang melanjutkan head saat ini selalu dipilih, leluhurnya tidak pernah
+	if externNumber > localNumber && g.ancestors.IsAncestor(chain, local, localNumber, extern, externNumber) {
+		return true, nil
+	}
+	if localNumber > externNumber && g.ancestors.IsAncestor(chain, extern, externNumber, local, localNumber) {
+		return false, nil
+	}
+	localChild, externChild := g.forkChildren(chain, local, localNumber, extern, externNumber)
+	localNode, externNode := g.nodes[localChild], g.nodes[externChild]
+	if localNode == nil || externNode == nil {
+		return HeaviestTD{}.ReorgNeeded(chain, current, header)
+	}
+	return externNode.weight.Cmp(localNode.weight) > 0, nil
+}
+
+// forkChildren mengembalikan anak titik percabangan di cabang local dan extern.
+// Kedua cabang disamakan tingginya, lalu titik percabangan dicari secara biner
+// lewat indeks leluhur. Hash kosong dikembalikan jika leluhur tidak ditemukan.
+// Pemanggil harus memegang kunci.
+func (g *GHOST) forkChildren(chain ChainHeaderReader, local common.Hash, localNumber uint64, extern common.Hash, externNumber uint64) (common.Hash, common.Hash) {
+	number := localNumber
+	if externNumber < number {
+		number = externNumber
+	}
+	local, _ = g.ancestors.GetAncestor(chain, local, localNumber, localNumber-number)
+	extern, _ = g.ancestors.GetAncestor(chain, extern, externNumber, externNumber-number)
+	if local == (common.Hash{}) || extern == (common.Hash{}) {
+		return common.Hash{}, common.Hash{}
+	}
+	// at mengembalikan leluhur kedua cabang pada tinggi tertentu
+	at := func(height uint64) (common.Hash, common.Hash, bool) {
+		l, _ := g.ancestors.GetAncestor(chain, local, number, number-height)
+		e, _ := g.ancestors.GetAncestor(chain, extern, number, number-height)
+		return l, e, l != (common.Hash{}) && e != (common.Hash{})
+	}
+	// Pertahankan invarian: cabang sama pada tinggi lo dan berbeda pada tinggi hi
+	if l, e, ok := at(0); !ok || l != e {
+		return common.Hash{}, common.Hash{}
+	}
+	lo, hi := uint64(0), number
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		l, e, ok := at(mid)
+		if !ok {
+			return common.Hash{}, common.Hash{}
+		}
+		if l == e {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	l, e, _ := at(hi)
+	return l, e
+}
+
+// Prune menghapus semua header di bawah nomor tertentu dari pohon, misalnya setelah
+// blok tersebut final. Bobot header yang tersisa tidak berubah.
+func (g *GHOST) Prune(number uint64) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.pruneBelow(number)
+}
+
+// prune memangkas pohon sampai batas kedalaman atau header final engine, mana yang
+// lebih tinggi. Pemanggil harus memegang kunci.
+func (g *GHOST) prune(chain ChainHeaderReader) {
+	var cutoff uint64
+	if g.highest > g.depth {
+		cutoff = g.highest - g.depth
+	}
+	if g.engine != nil {
+		if final := g.engine.FinalizedHeader(chain); final != nil && final.Number.Uint64() > cutoff {
+			cutoff = final.Number.Uint64()
+		}
+	}
+	g.pruneBelow(cutoff)
+}
+
+// pruneBelow menghapus semua header di bawah nomor tertentu jika batas pemangkasan
+// naik. Pemanggil harus memegang kunci.
+func (g *GHOST) pruneBelow(number uint64) {
+	if number <= g.cutoff {
+		return
+	}
+	g.cutoff = number
+	for hash, node := range g.nodes {
+		if node.number < number {
+			delete(g.nodes, hash)
+		}
+	}
+}
+
+// add memasukkan header ke pohon dan menambahkan kesulitannya ke bobot semua
+// leluhurnya yang dikenal. Header di bawah batas pemangkasan diabaikan, dan leluhur
+// di bawah batas sudah dihapus, jadi jalan ke leluhur paling panjang sejauh
+// kedalaman pohon. Pemanggil harus memegang kunci.
+func (g *GHOST) add(header *types.Header) {
+	hash, number := header.Hash(), header.Number.Uint64()
+	if _, ok := g.nodes[hash]; ok || number < g.cutoff {
+		return
+	}
+	difficulty := new(big.Int)
+	if header.Difficulty != nil {
+		difficulty.Set(header.Difficulty)
+	}
+	g.nodes[hash] = &ghostNode{
+		parent: header.ParentHash,
+		number: number,
+		weight: difficulty,
+	}
+	if number > g.highest {
+		g.highest = number
+	}
+	for node := g.nodes[header.ParentHash]; node != nil; node = g.nodes[node.parent] {
+		node.weight.Add(node.weight, difficulty)
+	}
+}
+
+// External adalah aturan fork choice yang dikendalikan dari luar, misalnya oleh
+// klien beacon lewat engine API. Hanya header yang ditetapkan lewat SetHead yang
+// bisa menjadi head kanonik, dan hanya pada saat header tersebut dimasukkan.
+type External struct {
+	head common.Hash  // Hash head yang ditetapkan dari luar
+	lock sync.RWMutex // Melindungi field head
+}
+
+// NewExternal membuat aturan fork choice eksternal tanpa head yang ditetapkan.
+func NewExternal() *External {
+	return &External{}
+}
+
+// SetHead menetapkan hash header yang harus menjadi head kanonik. SetHead tidak
+// mengubah rantai: jika header sudah dimasukkan sebelumnya, pemanggil juga harus
+// melakukan reorg ke header tersebut (misalnya lewat HeaderChain.Reorg).
+func (e *External) SetHead(hash common.Hash) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.head = hash
+}
+
+// ReorgNeeded mengimplementasikan ForkChoice.
+func (e *External) ReorgNeeded(chain ChainHeaderReader, current *types.Header, header *types.Header) (bool, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	return e.head != (common.Hash{}) && header.Hash() == e.head && current.Hash() != e.head, nil
+}
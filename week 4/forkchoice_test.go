@@ -0,0 +1,41 @@
+package consensus
+
+import "testing"
+
+// Menguji bahwa pohon GHOST dipangkas otomatis sampai kedalamannya, sehingga
+// ukurannya tetap terbatas pada rantai yang panjang, dan bahwa Prune manual hanya
+// bisa menaikkan batas pemangkasan.
+func TestGHOSTPrune(t *testing.T) {
+	chain, headers := newCountingChain(100)
+	ghost := NewGHOST(nil, 8)
+
+	for i := 1; i < len(headers); i++ {
+		if _, err := ghost.ReorgNeeded(chain, headers[i-1], headers[i]); err != nil {
+			t.Fatalf("header %d: fork choice failed: %v", i, err)
+		}
+		if have := len(ghost.nodes); have > 9 {
+			t.Fatalf("header %d: tree size mismatch: have %d, want at most %d", i, have, 9)
+		}
+	}
+	// Bobot header tertua yang tersisa hanya mencakup turunannya di pohon
+	oldest := ghost.nodes[headers[91].Hash()]
+	if oldest == nil || ghost.nodes[headers[90].Hash()] != nil {
+		t.Fatalf("pruning boundary mismatch: want lowest header 91")
+	}
+	if have := oldest.weight.Uint64(); have != 9 {
+		t.Errorf("oldest weight mismatch: have %d, want %d", have, 9)
+	}
+	// Header lama tidak dimasukkan lagi ke pohon
+	ghost.ReorgNeeded(chain, headers[99], headers[50])
+	if ghost.nodes[headers[50].Hash()] != nil {
+		t.Errorf("pruned header re-added to tree")
+	}
+	ghost.Prune(95)
+	if have := len(ghost.nodes); have != 5 {
+		t.Errorf("tree size after prune mismatch: have %d, want %d", have, 5)
+	}
+	ghost.Prune(10)
+	if ghost.cutoff != 95 {
+		t.Errorf("prune lowered cutoff: have %d, want %d", ghost.cutoff, 95)
+	}
+}
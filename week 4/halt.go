@@ -0,0 +1,175 @@
+package consensus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// maxHaltPollInterval adalah jeda terlama di antara dua pemeriksaan kepala rantai.
+const maxHaltPollInterval = time.Second
+
+// Metrik untuk sistem peringatan: gauge bernilai satu selama rantai berhenti.
+var (
+	haltedGauge       = metrics.NewRegisteredGauge("consensus/halt/halted", nil)
+	haltsCounter      = metrics.NewRegisteredCounter("consensus/halt/halts", nil)
+	recoveriesCounter = metrics.NewRegisteredCounter("consensus/halt/recoveries", nil)
+)
+
+// HaltStatus adalah keadaan rantai menurut HaltMonitor.
+type HaltStatus struct {
+	Halted     bool        `json:"halted"`     // Apakah rantai sedang dianggap berhenti
+	Number     uint64      `json:"number"`     // Nomor kepala rantai yang terakhir terlihat
+	Hash       common.Hash `json:"hash"`       // Hash kepala rantai yang terakhir terlihat
+	Stalled    uint64      `json:"stalled"`    // Jumlah detik sejak kepala rantai terakhir maju
+	Halts      uint64      `json:"halts"`      // Jumlah kejadian rantai berhenti sejak monitor dibuat
+	Recoveries uint64      `json:"recoveries"` // Jumlah pemulihan yang dijalankan sejak monitor dibuat
+}
+
+// HaltMonitor mendeteksi rantai yang berhenti, yaitu ketika kepala rantai lokal
+// tidak maju selama threshold kali periode blok yang diharapkan. Saat berhenti,
+// monitor mencatat peringatan di log dan metrik consensus/halt, lalu menjalankan
+// fungsi pemulihan milik mesin konsensus, misalnya pergantian ronde di mesin BFT.
+// Pemulihan diulang setiap kali rantai tetap diam selama satu jendela lagi, sampai
+// kepala rantai maju.
+//
+// ChainHeaderReader tidak menyediakan feed head baru, jadi monitor memeriksa kepala
+// rantai secara berkala. Waktu diukur dari saat monitor melihat kepala rantai maju,
+// bukan dari stempel waktu header, agar node yang baru dijalankan di atas rantai
+// lama tidak langsung menganggap rantai berhenti.
+type HaltMonitor struct {
+	chain    ChainHeaderReader
+	window   time.Duration            // Lama kepala rantai boleh diam sebelum rantai dianggap berhenti
+	recovery func(head *types.Header) // Fungsi pemulihan mesin konsensus, boleh nil
+
+	head     *types.Header // Kepala rantai yang terakhir terlihat
+	progress time.Time     // Waktu lokal saat kepala rantai terakhir maju
+	deadline time.Time     // Waktu paling awal untuk peringatan atau pemulihan berikutnya
+	status   HaltStatus
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+	lock sync.Mutex
+}
+
+// NewHaltMonitor membuat monitor yang menganggap rantai berhenti jika kepala rantai
+// tidak maju selama threshold kali period. Threshold nol dianggap satu. Fungsi
+// recovery boleh nil jika mesin hanya membutuhkan peringatan.
+func NewHaltMonitor(chain ChainHeaderReader, period time.Duration, threshold uint64, recovery func(head *types.Header)) *HaltMonitor {
+	if threshold == 0 {
+		threshold = 1
+	}
+	return &HaltMonitor{
+		chain:    chain,
+		window:   period * time.Duration(threshold),
+		recovery: recovery,
+	}
+}
+
+// Start mulai memeriksa kepala rantai di latar belakang. Memanggil Start pada
+// monitor yang sudah berjalan tidak berpengaruh.
+func (m *HaltMonitor) Start() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.quit != nil {
+		return
+	}
+	m.quit = make(chan struct{})
+	m.wg.Add(1)
+	go m.loop(m.quit)
+}
+
+// Stop menghentikan pemeriksaan dan menunggu pemulihan yang sedang berjalan selesai.
+func (m *HaltMonitor) Stop() {
+	m.lock.Lock()
+	if m.quit != nil {
+		close(m.quit)
+		m.quit = nil
+	}
+	m.lock.Unlock()
+
+	m.wg.Wait()
+}
+
+// Status mengembalikan keadaan rantai menurut pemeriksaan terakhir.
+func (m *HaltMonitor) Status() HaltStatus {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	status := m.status
+	if !m.progress.IsZero() {
+		status.Stalled = uint64(time.Since(m.progress) / time.Second)
+	}
+	return status
+}
+
+// loop memeriksa kepala rantai sampai quit ditutup. Jeda pemeriksaan paling lama
+// sedetik dan paling lama seperempat jendela, agar jendela yang pendek tetap
+// terdeteksi tepat waktu.
+func (m *HaltMonitor) loop(quit chan struct{}) {
+	defer m.wg.Done()
+
+	interval := m.window / 4
+	if interval <= 0 || interval > maxHaltPollInterval {
+		interval = maxHaltPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	m.check(time.Now())
+	for {
+		select {
+		case now := <-ticker.C:
+			m.check(now)
+		case <-quit:
+			return
+		}
+	}
+}
+
+// check membandingkan kepala rantai dengan pemeriksaan sebelumnya pada waktu now,
+// lalu memperingatkan dan menjalankan pemulihan jika jendela sudah lewat.
+func (m *HaltMonitor) check(now time.Time) {
+	head := m.chain.CurrentHeader()
+	if head == nil {
+		return
+	}
+	m.lock.Lock()
+	if m.head == nil || head.Number.Cmp(m.head.Number) > 0 {
+		if m.status.Halted {
+			log.Info("Chain resumed after halt", "number", head.Number, "hash", head.Hash(), "stalled", common.PrettyDuration(now.Sub(m.progress)))
+			haltedGauge.Update(0)
+		}
+		m.head, m.progress, m.deadline = head, now, now.Add(m.window)
+		m.status.Halted, m.status.Number, m.status.Hash = false, head.Number.Uint64(), head.Hash()
+		m.lock.Unlock()
+		return
+	}
+	if now.Before(m.deadline) {
+		m.lock.Unlock()
+		return
+	}
+	if !m.status.Halted {
+		log.Warn("Chain halted", "number", m.head.Number, "hash", m.head.Hash(), "stalled", common.PrettyDuration(now.Sub(m.progress)))
+		haltedGauge.Update(1)
+		haltsCounter.Inc(1)
+		m.status.Halted = true
+		m.status.Halts++
+	}
+	m.deadline = now.Add(m.window)
+	recovery := m.recovery
+	if recovery != nil {
+		m.status.Recoveries++
+		recoveriesCounter.Inc(1)
+	}
+	m.lock.Unlock()
+
+	if recovery != nil {
+		recovery(head)
+	}
+}
@@ -0,0 +1,113 @@
+package consensus
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// headChain adalah countingChain dengan kepala rantai yang diatur langsung oleh tes.
+type headChain struct {
+	*countingChain
+	head *types.Header
+	lock sync.Mutex
+}
+
+func (c *headChain) CurrentHeader() *types.Header {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.head
+}
+
+// setHead mengganti kepala rantai.
+func (c *headChain) setHead(head *types.Header) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.head = head
+}
+
+// Menguji bahwa rantai dianggap berhenti setelah satu jendela tanpa kepala baru,
+// pemulihan diulang setiap jendela selama rantai masih diam, dan rantai dianggap
+// pulih begitu kepala baru terlihat.
+func TestHaltMonitor(t *testing.T) {
+	counting, headers := newCountingChain(3)
+	chain := &headChain{countingChain: counting, head: headers[0]}
+
+	var recovered []uint64
+	monitor := NewHaltMonitor(chain, time.Second, 3, func(head *types.Header) {
+		recovered = append(recovered, head.Number.Uint64())
+	})
+	start := time.Now()
+
+	tests := []struct {
+		name       string
+		head       *types.Header
+		elapsed    time.Duration
+		halted     bool
+		recoveries int
+	}{
+		{"first head", headers[0], 0, false, 0},
+		{"within window", headers[0], 2 * time.Second, false, 0},
+		{"window passed", headers[0], 3 * time.Second, true, 1},
+		{"still halted", headers[0], 5 * time.Second, true, 1},
+		{"second window passed", headers[0], 6 * time.Second, true, 2},
+		{"resumed", headers[1], 7 * time.Second, false, 2},
+		{"new window", headers[1], 9 * time.Second, false, 2},
+		{"halted again", headers[1], 10 * time.Second, true, 3},
+		{"repeated recovery", headers[1], 13 * time.Second, true, 4},
+	}
+	for _, test := range tests {
+		chain.setHead(test.head)
+		monitor.check(start.Add(test.elapsed))
+
+		status := monitor.Status()
+		if status.Halted != test.halted {
+			t.Errorf("%s: halted mismatch: have %v, want %v", test.name, status.Halted, test.halted)
+		}
+		if len(recovered) != test.recoveries || status.Recoveries != uint64(test.recoveries) {
+			t.Errorf("%s: recoveries mismatch: have %d/%d, want %d", test.name, len(recovered), status.Recoveries, test.recoveries)
+		}
+		if status.Number != test.head.Number.Uint64() {
+			t.Errorf("%s: head mismatch: have %d, want %d", test.name, status.Number, test.head.Number)
+		}
+	}
+	if status := monitor.Status(); status.Halts != 2 {
+		t.Errorf("halt count mismatch: have %d, want %d", status.Halts, 2)
+	}
+	for i, number := range recovered {
+		if want := uint64(i) / 2; number != want {
+			t.Errorf("recovery %d: head mismatch: have %d, want %d", i, number, want)
+		}
+	}
+}
+
+// Menguji bahwa monitor yang dijalankan mendeteksi rantai berhenti dengan jendela
+// pendek, dan bahwa Stop menghentikan pemulihan berikutnya.
+func TestHaltMonitorLoop(t *testing.T) {
+	counting, headers := newCountingChain(1)
+	chain := &headChain{countingChain: counting, head: headers[0]}
+
+	recovered := make(chan struct{}, 16)
+	monitor := NewHaltMonitor(chain, 10*time.Millisecond, 2, func(head *types.Header) {
+		recovered <- struct{}{}
+	})
+	monitor.Start()
+	monitor.Start()
+
+	select {
+	case <-recovered:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("halt recovery timeout")
+	}
+	monitor.Stop()
+	for len(recovered) > 0 {
+		<-recovered
+	}
+	select {
+	case <-recovered:
+		t.Errorf("recovery after stop")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
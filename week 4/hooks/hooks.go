@@ -0,0 +1,180 @@
+// Paket hooks menyediakan pembungkus mesin konsensus yang menjalankan hook berurutan
+// di sekitar persiapan, finalisasi dan penyegelan blok, sehingga logika reward atau
+// telemetri tambahan bisa disisipkan tanpa mengimplementasikan ulang consensus.Engine.
+package hooks
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Hook adalah kumpulan callback yang dipanggil di sekitar mesin konsensus yang
+// dibungkus. Setiap field boleh nil jika hook tidak tertarik pada tahap tersebut.
+type Hook struct {
+	// OnPrepare dipanggil setelah mesin asli menyiapkan header. Hook boleh mengubah
+	// header, dan error akan membatalkan persiapan blok.
+	OnPrepare func(chain consensus.ChainHeaderReader, header *types.Header) error
+
+	// OnFinalize dipanggil sebelum mesin asli memfinalisasi blok, sehingga perubahan
+	// state dari hook, misalnya reward tambahan, ikut masuk ke state root.
+	OnFinalize func(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header)
+
+	// OnSeal dipanggil sebelum mesin asli mulai menyegel blok. Error akan membatalkan
+	// penyegelan.
+	OnSeal func(chain consensus.ChainHeaderReader, block *types.Block) error
+}
+
+// Engine adalah mesin konsensus yang membungkus mesin lain dan menjalankan hook
+// sesuai urutan pendaftarannya. Semua metode lain diteruskan apa adanya.
+type Engine struct {
+	inner consensus.Engine // Mesin konsensus yang dibungkus
+	hooks []Hook           // Hook yang dijalankan sesuai urutan
+}
+
+// New membungkus mesin konsensus dengan hook yang diberikan. Hook dijalankan sesuai
+// urutan argumen. Hasilnya hanya memenuhi consensus.PoW jika mesin asli juga PoW,
+// sehingga pemanggil yang memeriksa consensus.PoW untuk memutuskan penambangan
+// tetap mendapat jawaban yang benar.
+func New(inner consensus.Engine, hooks ...Hook) consensus.Engine {
+	engine := &Engine{inner: inner, hooks: hooks}
+	if pow, ok := inner.(consensus.PoW); ok {
+		return &powEngine{Engine: engine, pow: pow}
+	}
+	return engine
+}
+
+// InnerEngine mengembalikan mesin konsensus yang dibungkus.
+func (e *Engine) InnerEngine() consensus.Engine {
+	return e.inner
+}
+
+// Author mengimplementasikan consensus.Engine, meneruskan ke mesin asli.
+func (e *Engine) Author(header *types.Header) (common.Address, error) {
+	return e.inner.Author(header)
+}
+
+// VerifyHeader mengimplementasikan consensus.Engine, meneruskan ke mesin asli.
+func (e *Engine) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, seal bool) error {
+	return e.inner.VerifyHeader(chain, header, seal)
+}
+
+// VerifyHeaders mengimplementasikan consensus.Engine, meneruskan ke mesin asli.
+func (e *Engine) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	return e.inner.VerifyHeaders(chain, headers, seals)
+}
+
+// VerifyUncles mengimplementasikan consensus.Engine, meneruskan ke mesin asli.
+func (e *Engine) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
+	return e.inner.VerifyUncles(chain, block)
+}
+
+// Prepare mengimplementasikan consensus.Engine, menyiapkan header dengan mesin asli
+// lalu menjalankan semua hook OnPrepare.
+func (e *Engine) Prepare(chain consensus.ChainHeaderReader, header *types.Header) error {
+	if err := e.inner.Prepare(chain, header); err != nil {
+		return err
+	}
+	for _, hook := range e.hooks {
+		if hook.OnPrepare == nil {
+			continue
+		}
+		if err := hook.OnPrepare(chain, header); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Finalize mengimplementasikan consensus.Engine, menjalankan semua hook OnFinalize
+// lalu memfinalisasi blok dengan mesin asli.
+func (e *Engine) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header) {
+	e.finalize(chain, header, state, txs, uncles)
+	e.inner.Finalize(chain, header, state, txs, uncles)
+}
+
+// FinalizeAndAssemble mengimplementasikan consensus.Engine, menjalankan semua hook
+// OnFinalize lalu memfinalisasi dan merakit blok dengan mesin asli.
+func (e *Engine) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
+	e.finalize(chain, header, state, txs, uncles)
+	return e.inner.FinalizeAndAssemble(chain, header, state, txs, uncles, receipts)
+}
+
+// finalize menjalankan semua hook OnFinalize sesuai urutan.
+func (e *Engine) finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header) {
+	for _, hook := range e.hooks {
+		if hook.OnFinalize != nil {
+			hook.OnFinalize(chain, header, state, txs, uncles)
+		}
+	}
+}
+
+// Seal mengimplementasikan consensus.Engine, menjalankan semua hook OnSeal lalu
+// menyegel blok dengan mesin asli.
+func (e *Engine) Seal(chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
+	for _, hook := range e.hooks {
+		if hook.OnSeal == nil {
+			continue
+		}
+		if err := hook.OnSeal(chain, block); err != nil {
+			return err
+		}
+	}
+	return e.inner.Seal(chain, block, results, stop)
+}
+
+// SealHash mengimplementasikan consensus.Engine, meneruskan ke mesin asli.
+func (e *Engine) SealHash(header *types.Header) common.Hash {
+	return e.inner.SealHash(header)
+}
+
+// CalcDifficulty mengimplementasikan consensus.Engine, meneruskan ke mesin asli.
+func (e *Engine) CalcDifficulty(chain consensus.ChainHeaderReader, time uint64, parent *types.Header) *big.Int {
+	return e.inner.CalcDifficulty(chain, time, parent)
+}
+
+// FinalizedHeader mengimplementasikan consensus.Engine, meneruskan ke mesin asli.
+func (e *Engine) FinalizedHeader(chain consensus.ChainHeaderReader) *types.Header {
+	return e.inner.FinalizedHeader(chain)
+}
+
+// SafeHeader mengimplementasikan consensus.Engine, meneruskan ke mesin asli.
+func (e *Engine) SafeHeader(chain consensus.ChainHeaderReader) *types.Header {
+	return e.inner.SafeHeader(chain)
+}
+
+// APIs mengimplementasikan consensus.Engine, meneruskan ke mesin asli.
+func (e *Engine) APIs(chain consensus.ChainHeaderReader) []rpc.API {
+	return e.inner.APIs(chain)
+}
+
+// Close mengimplementasikan consensus.Engine, menghentikan mesin asli.
+func (e *Engine) Close() error {
+	return e.inner.Close()
+}
+
+// powEngine adalah Engine untuk mesin asli berbasis PoW, yang juga meneruskan
+// metode consensus.PoW ke mesin asli.
+type powEngine struct {
+	*Engine
+	pow consensus.PoW
+}
+
+// SetThreads mengimplementasikan consensus.PoW, meneruskan ke mesin asli.
+func (e *powEngine) SetThreads(threads int) {
+	e.pow.SetThreads(threads)
+}
+
+// Hashrate mengimplementasikan consensus.PoW, meneruskan ke mesin asli.
+func (e *powEngine) Hashrate() float64 {
+	return e.pow.Hashrate()
+}
+
+// SubmitHashrate mengimplementasikan consensus.PoW, meneruskan ke mesin asli.
+func (e *powEngine) SubmitHashrate(id common.Hash, rate uint64) bool {
+	return e.pow.SubmitHashrate(id, rate)
+}
@@ -0,0 +1,234 @@
+package hooks
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/consensus/consensustest"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// errHook adalah error yang dikembalikan hook untuk membatalkan sebuah tahap.
+var errHook = errors.New("hook failure")
+
+// newTestChain membuat rantai di memori berisi genesis saja beserta header anak
+// yang belum dimasukkan ke rantai.
+func newTestChain() (*consensustest.HeaderChain, *types.Header) {
+	genesis := &types.Header{
+		Number:     big.NewInt(0),
+		Difficulty: big.NewInt(1),
+		GasLimit:   params.GenesisGasLimit,
+		UncleHash:  types.EmptyUncleHash,
+	}
+	chain := consensustest.NewHeaderChain(params.TestChainConfig, genesis)
+	header := &types.Header{
+		ParentHash: genesis.Hash(),
+		Number:     big.NewInt(1),
+		GasLimit:   genesis.GasLimit,
+		Time:       genesis.Time + 1,
+		UncleHash:  types.EmptyUncleHash,
+	}
+	return chain, header
+}
+
+// recordPrepare mengembalikan hook OnPrepare yang mencatat tag ke dalam log lalu
+// mengembalikan err.
+func recordPrepare(log *[]string, tag string, err error) Hook {
+	return Hook{OnPrepare: func(chain consensus.ChainHeaderReader, header *types.Header) error {
+		*log = append(*log, tag)
+		return err
+	}}
+}
+
+// Menguji bahwa hook OnPrepare dijalankan sesuai urutan setelah mesin asli, dan
+// error dari satu hook membatalkan persiapan serta melewati hook berikutnya.
+func TestPrepare(t *testing.T) {
+	tests := []struct {
+		name  string
+		hooks []string // Tag hook, "!" berarti hook mengembalikan error
+		calls []string
+		want  error
+	}{
+		{"in order", []string{"a", "b", "c"}, []string{"a", "b", "c"}, nil},
+		{"abort", []string{"a", "!", "c"}, []string{"a", "!"}, errHook},
+	}
+	for _, test := range tests {
+		var (
+			calls []string
+			hooks = []Hook{{}} // Hook kosong harus dilewati
+		)
+		for _, tag := range test.hooks {
+			var err error
+			if tag == "!" {
+				err = errHook
+			}
+			hooks = append(hooks, recordPrepare(&calls, tag, err))
+		}
+		chain, header := newTestChain()
+		engine := New(consensustest.NewFakeEngine(), hooks...)
+
+		if err := engine.Prepare(chain, header); err != test.want {
+			t.Errorf("%s: error mismatch: have %v, want %v", test.name, err, test.want)
+		}
+		if header.Difficulty == nil {
+			t.Errorf("%s: inner engine did not prepare header", test.name)
+		}
+		if len(calls) != len(test.calls) {
+			t.Errorf("%s: hook calls mismatch: have %v, want %v", test.name, calls, test.calls)
+			continue
+		}
+		for i := range calls {
+			if calls[i] != test.calls[i] {
+				t.Errorf("%s: hook calls mismatch: have %v, want %v", test.name, calls, test.calls)
+				break
+			}
+		}
+	}
+}
+
+// Menguji bahwa error OnSeal membatalkan penyegelan sebelum mesin asli menerima
+// blok, dan tanpa error blok disegel seperti biasa.
+func TestSeal(t *testing.T) {
+	chain, header := newTestChain()
+	block := types.NewBlockWithHeader(header)
+
+	var calls int
+	count := Hook{OnSeal: func(chain consensus.ChainHeaderReader, block *types.Block) error {
+		calls++
+		return nil
+	}}
+	fail := Hook{OnSeal: func(chain consensus.ChainHeaderReader, block *types.Block) error {
+		return errHook
+	}}
+	results := make(chan *types.Block, 1)
+	if err := New(consensustest.NewFakeEngine(), count, fail, count).Seal(chain, block, results, nil); err != errHook {
+		t.Fatalf("seal error mismatch: have %v, want %v", err, errHook)
+	}
+	if calls != 1 {
+		t.Errorf("hook calls mismatch: have %d, want %d", calls, 1)
+	}
+	select {
+	case <-results:
+		t.Fatalf("aborted block was sealed")
+	case <-time.After(100 * time.Millisecond):
+	}
+	if err := New(consensustest.NewFakeEngine(), count).Seal(chain, block, results, nil); err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	select {
+	case sealed := <-results:
+		if sealed.Hash() != block.Hash() {
+			t.Errorf("sealed block mismatch: have %x, want %x", sealed.Hash(), block.Hash())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("seal timeout")
+	}
+}
+
+// Menguji bahwa perubahan state dari hook OnFinalize ikut masuk ke state root
+// header, baik lewat Finalize maupun FinalizeAndAssemble.
+func TestFinalize(t *testing.T) {
+	var (
+		first  = common.Address{0x01}
+		second = common.Address{0x02}
+	)
+	// Hook kedua menggandakan saldo dari hook pertama, jadi urutan ikut diperiksa
+	hooks := []Hook{
+		{OnFinalize: func(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header) {
+			state.AddBalance(first, big.NewInt(100))
+		}},
+		{OnFinalize: func(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header) {
+			state.AddBalance(second, new(big.Int).Mul(state.GetBalance(first), big.NewInt(2)))
+		}},
+	}
+	expected, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	expected.AddBalance(first, big.NewInt(100))
+	expected.AddBalance(second, big.NewInt(200))
+	want := expected.IntermediateRoot(true)
+
+	engine := New(consensustest.NewFakeEngine(), hooks...)
+	chain, header := newTestChain()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	engine.Finalize(chain, header, statedb, nil, nil)
+	if header.Root != want {
+		t.Errorf("finalize root mismatch: have %x, want %x", header.Root, want)
+	}
+	statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	header = types.CopyHeader(header)
+	header.Root = common.Hash{}
+	block, err := engine.FinalizeAndAssemble(chain, header, statedb, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to assemble block: %v", err)
+	}
+	if block.Root() != want {
+		t.Errorf("assembled root mismatch: have %x, want %x", block.Root(), want)
+	}
+}
+
+// finalityEngine adalah mesin palsu dengan header final dan aman yang tetap.
+type finalityEngine struct {
+	*consensustest.FakeEngine
+	finalized, safe *types.Header
+}
+
+func (f *finalityEngine) FinalizedHeader(chain consensus.ChainHeaderReader) *types.Header {
+	return f.finalized
+}
+
+func (f *finalityEngine) SafeHeader(chain consensus.ChainHeaderReader) *types.Header {
+	return f.safe
+}
+
+// Menguji bahwa header final dan aman diteruskan dari mesin asli.
+func TestFinality(t *testing.T) {
+	chain, header := newTestChain()
+	inner := &finalityEngine{
+		FakeEngine: consensustest.NewFakeEngine(),
+		finalized:  chain.CurrentHeader(),
+		safe:       header,
+	}
+	engine := New(inner, Hook{})
+	if have := engine.FinalizedHeader(chain); have != inner.finalized {
+		t.Errorf("finalized header mismatch: have %p, want %p", have, inner.finalized)
+	}
+	if have := engine.SafeHeader(chain); have != inner.safe {
+		t.Errorf("safe header mismatch: have %p, want %p", have, inner.safe)
+	}
+}
+
+// Menguji bahwa metode PoW hanya tersedia dan diteruskan jika mesin asli adalah
+// PoW, sehingga pembungkus clique tidak terlihat seperti mesin PoW.
+func TestPoWForwarding(t *testing.T) {
+	inner := consensustest.NewFakePoW()
+	engine, ok := New(inner).(consensus.PoW)
+	if !ok {
+		t.Fatalf("wrapped PoW engine does not implement consensus.PoW")
+	}
+	engine.SetThreads(3)
+	if !engine.SubmitHashrate(common.Hash{1}, 100) {
+		t.Fatalf("hashrate submission rejected")
+	}
+	if have := inner.Threads(); have != 3 {
+		t.Errorf("threads mismatch: have %d, want %d", have, 3)
+	}
+	if have := engine.Hashrate(); have != 100 {
+		t.Errorf("hashrate mismatch: have %v, want %v", have, 100)
+	}
+	for name, inner := range map[string]consensus.Engine{
+		"fake":   consensustest.NewFakeEngine(),
+		"clique": clique.New(params.AllCliqueProtocolChanges.Clique, rawdb.NewMemoryDatabase()),
+	} {
+		if _, ok := New(inner).(consensus.PoW); ok {
+			t.Errorf("%s: wrapped engine implements consensus.PoW", name)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package istanbul
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// API adalah API RPC untuk memeriksa kumpulan validator dari skema Istanbul BFT.
+type API struct {
+	chain    consensus.ChainHeaderReader
+	istanbul *Istanbul
+}
+
+// header mengambil header untuk nomor blok yang diminta (atau header saat ini
+// jika tidak ada yang diminta).
+func (api *API) header(number *rpc.BlockNumber) *types.Header {
+	if number == nil || *number == rpc.LatestBlockNumber {
+		return api.chain.CurrentHeader()
+	}
+	return api.chain.GetHeaderByNumber(uint64(number.Int64()))
+}
+
+// GetValidators mengambil daftar validator pada blok tertentu.
+func (api *API) GetValidators(number *rpc.BlockNumber) ([]common.Address, error) {
+	header := api.header(number)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	valSet, err := api.istanbul.validators(header)
+	if err != nil {
+		return nil, err
+	}
+	return valSet.validators, nil
+}
+
+// GetValidatorsAtHash mengambil daftar validator pada blok dengan hash tertentu.
+func (api *API) GetValidatorsAtHash(hash common.Hash) ([]common.Address, error) {
+	header := api.chain.GetHeaderByHash(hash)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	valSet, err := api.istanbul.validators(header)
+	if err != nil {
+		return nil, err
+	}
+	return valSet.validators, nil
+}
+
+// GetHaltStatus mengembalikan keadaan pendeteksi rantai berhenti, yaitu apakah
+// rantai sedang berhenti dan berapa kali pemulihan ronde sudah dijalankan.
+func (api *API) GetHaltStatus() (*consensus.HaltStatus, error) {
+	api.istanbul.lock.RLock()
+	monitor := api.istanbul.monitor
+	api.istanbul.lock.RUnlock()
+
+	if monitor == nil {
+		return nil, errNotStarted
+	}
+	status := monitor.Status()
+	return &status, nil
+}
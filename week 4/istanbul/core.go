@@ -0,0 +1,496 @@
+package istanbul
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+const (
+	maxBacklog     = 1024 // Jumlah maksimum pesan untuk nomor blok berikutnya yang ditahan
+	maxTimeoutStep = 8    // Batas pelipatan batas waktu ronde agar tidak meluap
+)
+
+var (
+	// errInvalidProposal dikembalikan jika blok di dalam PRE-PREPARE tidak cocok
+	// dengan digest atau nomor blok yang sedang disepakati.
+	errInvalidProposal = errors.New("invalid proposal")
+
+	// errNotFromProposer dikembalikan jika PRE-PREPARE tidak dikirim oleh pengusul
+	// ronde yang sedang berjalan.
+	errNotFromProposer = errors.New("message does not come from proposer")
+
+	// errLockedProposal dikembalikan jika PRE-PREPARE mengusulkan blok lain padahal
+	// node sudah terkunci pada usulan yang prepared.
+	errLockedProposal = errors.New("proposal conflicts with locked proposal")
+)
+
+// core adalah mesin status ronde Istanbul BFT untuk satu node. Setiap nomor blok
+// disepakati dalam satu atau lebih ronde; setiap ronde memiliki satu pengusul yang
+// mengirim PRE-PREPARE, lalu validator bertukar PREPARE dan COMMIT sampai quorum
+// commit seal terkumpul. Jika ronde macet, validator meminta ROUND-CHANGE.
+type core struct {
+	engine *Istanbul
+	chain  consensus.ChainHeaderReader
+
+	sequence     uint64         // Nomor blok yang sedang disepakati
+	round        uint64         // Ronde saat ini untuk nomor blok tersebut
+	valSet       *validatorSet  // Validator yang berlaku untuk nomor blok ini
+	lastProposer common.Address // Pengusul blok induk, titik awal rotasi pengusul
+
+	pending *types.Block        // Usulan lokal dari Seal yang menunggu giliran
+	results chan<- *types.Block // Saluran hasil Seal untuk usulan lokal
+	locked  *types.Block        // Usulan yang sudah prepared dan dipertahankan lintas ronde
+	stopped bool                // Apakah mesin konsensus sudah dihentikan
+	timer   *time.Timer         // Batas waktu ronde saat ini
+	backlog []*message          // Pesan untuk nomor blok berikutnya
+	sentRC  uint64              // Ronde tertinggi yang pernah diminta node ini
+
+	proposal   *types.Block                   // Usulan yang diterima di ronde ini
+	prepares   map[common.Address]common.Hash // PREPARE yang diterima di ronde ini
+	commits    map[common.Address]*message    // COMMIT yang diterima di ronde ini
+	sentCommit bool                           // Apakah node sudah mengirim COMMIT di ronde ini
+	committed  bool                           // Apakah nomor blok ini sudah final
+
+	roundChanges map[uint64]map[common.Address]struct{} // ROUND-CHANGE per ronde tujuan
+
+	lock sync.Mutex
+}
+
+// newCore membuat mesin status ronde untuk mesin istanbul yang diberikan.
+func newCore(engine *Istanbul) *core {
+	return &core{engine: engine}
+}
+
+// request menyerahkan usulan lokal dari Seal ke ronde konsensus. Jika node ini
+// adalah pengusul ronde yang sedang berjalan, usulan langsung dikirim.
+func (c *core) request(chain consensus.ChainHeaderReader, proposal *types.Block, results chan<- *types.Block, stop <-chan struct{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.sync(chain); err != nil {
+		log.Warn("Failed to start istanbul sequence", "err", err)
+		return
+	}
+	if proposal.NumberU64() != c.sequence || c.committed {
+		return
+	}
+	c.pending, c.results = proposal, results
+
+	if c.proposal == nil && c.isProposer() {
+		c.propose(proposal)
+	}
+	// Jika penambangan dibatalkan, hasilnya tidak lagi ditunggu oleh miner
+	go func() {
+		<-stop
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		if c.pending == proposal {
+			c.pending, c.results = nil, nil
+		}
+	}()
+}
+
+// handle memproses pesan konsensus yang diterima dari jaringan.
+func (c *core) handle(msg *message) error {
+	c.engine.lock.RLock()
+	chain := c.engine.chain
+	c.engine.lock.RUnlock()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.sync(chain); err != nil {
+		return err
+	}
+	return c.handleMsg(msg)
+}
+
+// stop menghentikan ronde yang sedang berjalan.
+func (c *core) stop() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.stopped = true
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+}
+
+// sync memastikan mesin status sedang menyepakati blok setelah kepala rantai
+// lokal, dan memulai nomor blok baru jika rantai sudah maju.
+func (c *core) sync(chain consensus.ChainHeaderReader) error {
+	head := chain.CurrentHeader()
+	if head == nil {
+		return errUnknownBlock
+	}
+	next := head.Number.Uint64() + 1
+	if c.valSet != nil && next <= c.sequence {
+		return nil
+	}
+	valSet, err := c.engine.validators(head)
+	if err != nil {
+		return err
+	}
+	c.chain = chain
+	c.sequence = next
+	c.valSet = valSet
+	c.lastProposer, _ = c.engine.Author(head)
+	c.locked, c.committed, c.sentRC = nil, false, 0
+	c.roundChanges = make(map[uint64]map[common.Address]struct{})
+	if c.pending != nil && c.pending.NumberU64() != next {
+		c.pending, c.results = nil, nil
+	}
+	c.startRound(0)
+
+	// Putar ulang pesan yang datang lebih awal untuk nomor blok ini
+	backlog := c.backlog
+	c.backlog = nil
+	for _, msg := range backlog {
+		switch {
+		case msg.Sequence == c.sequence:
+			if err := c.handleMsg(msg); err != nil {
+				log.Debug("Failed to handle backlogged istanbul message", "code", msg.Code, "err", err)
+			}
+		case msg.Sequence > c.sequence:
+			c.backlog = append(c.backlog, msg)
+		}
+	}
+	return nil
+}
+
+// startRound memulai ronde baru untuk nomor blok saat ini.
+func (c *core) startRound(round uint64) {
+	c.round = round
+	c.proposal = nil
+	c.prepares = make(map[common.Address]common.Hash)
+	c.commits = make(map[common.Address]*message)
+	c.sentCommit = false
+	for r := range c.roundChanges {
+		if r <= round {
+			delete(c.roundChanges, r)
+		}
+	}
+	// Pasang batas waktu ronde, berlipat dua setiap ronde berganti
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	if !c.stopped {
+		step := round
+		if step > maxTimeoutStep {
+			step = maxTimeoutStep
+		}
+		timeout := time.Duration(c.engine.config.RequestTimeout) * time.Millisecond << step
+		sequence := c.sequence
+		c.timer = time.AfterFunc(timeout, func() { c.handleTimeout(sequence, round) })
+	}
+	log.Debug("Starting istanbul round", "number", c.sequence, "round", round, "proposer", c.valSet.proposer(c.lastProposer, round))
+
+	// Pengusul ronde baru mengusulkan ulang blok yang terkunci, atau usulan lokalnya
+	if c.isProposer() {
+		if c.locked != nil {
+			c.propose(c.locked)
+		} else if c.pending != nil {
+			c.propose(c.pending)
+		}
+	}
+}
+
+// handleTimeout meminta pergantian ronde jika ronde belum selesai tepat waktu.
+func (c *core) handleTimeout(sequence, round uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.stopped || c.committed || c.sequence != sequence || c.round != round {
+		return
+	}
+	c.sendRoundChange(round + 1)
+}
+
+// recoverHalt dipanggil pendeteksi rantai berhenti. Batas waktu ronde hanya
+// berjalan sekali per ronde, jadi ROUND-CHANGE yang hilang, misalnya selama partisi
+// jaringan, tidak pernah dikirim ulang dan validator bisa menunggu selamanya
+// walaupun jaringan sudah pulih. Node karena itu mengirim ulang ROUND-CHANGE
+// tertinggi yang pernah dimintanya, atau meminta ronde berikutnya jika belum pernah.
+// Setiap validator jujur melakukan hal yang sama pada setiap jendela berhenti,
+// sehingga suara untuk ronde yang sama terkumpul lagi dan quorum pindah ronde
+// bersama-sama.
+func (c *core) recoverHalt(head *types.Header) {
+	c.engine.lock.RLock()
+	chain := c.engine.chain
+	c.engine.lock.RUnlock()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.stopped {
+		return
+	}
+	if err := c.sync(chain); err != nil {
+		log.Warn("Failed to recover halted istanbul chain", "number", head.Number, "err", err)
+		return
+	}
+	// Blok yang sudah final hanya menunggu dimasukkan ke rantai
+	if c.committed {
+		return
+	}
+	round := c.round + 1
+	if c.sentRC > round {
+		round = c.sentRC
+	}
+	log.Warn("Requesting istanbul round change to recover halted chain", "number", c.sequence, "round", round)
+	c.sentRC = round - 1
+	c.sendRoundChange(round)
+}
+
+// isProposer mengembalikan apakah node lokal adalah pengusul ronde saat ini.
+func (c *core) isProposer() bool {
+	c.engine.lock.RLock()
+	signer := c.engine.signer
+	c.engine.lock.RUnlock()
+
+	return c.valSet.proposer(c.lastProposer, c.round) == signer
+}
+
+// propose mengirim PRE-PREPARE untuk blok yang diberikan.
+func (c *core) propose(block *types.Block) {
+	payload, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		log.Error("Failed to encode istanbul proposal", "err", err)
+		return
+	}
+	c.broadcast(&message{
+		Code:    msgPreprepare,
+		Digest:  proposalHash(block.Header()),
+		Payload: payload,
+	})
+}
+
+// sendRoundChange mengirim ROUND-CHANGE untuk ronde yang diberikan.
+func (c *core) sendRoundChange(round uint64) {
+	if c.sentRC >= round {
+		return
+	}
+	c.sentRC = round
+	c.broadcast(&message{Code: msgRoundChange, Round: round})
+}
+
+// broadcast menandatangani pesan, mengirimkannya ke validator lain, lalu
+// memprosesnya secara lokal seolah-olah diterima dari jaringan.
+func (c *core) broadcast(msg *message) {
+	c.engine.lock.RLock()
+	signer, backend := c.engine.signer, c.engine.backend
+	c.engine.lock.RUnlock()
+
+	// Node yang bukan validator hanya mengamati ronde
+	if !c.valSet.contains(signer) {
+		return
+	}
+	msg.Sequence = c.sequence
+	if msg.Code != msgRoundChange {
+		msg.Round = c.round
+	}
+	sig, err := c.engine.sign(mimetypeIstanbul, msg.signingBytes())
+	if err != nil {
+		log.Error("Failed to sign istanbul message", "code", msg.Code, "err", err)
+		return
+	}
+	msg.Signature, msg.sender = sig, signer
+
+	if backend != nil {
+		payload, err := rlp.EncodeToBytes(msg)
+		if err != nil {
+			log.Error("Failed to encode istanbul message", "code", msg.Code, "err", err)
+			return
+		}
+		if err := backend.Broadcast(payload); err != nil {
+			log.Debug("Failed to broadcast istanbul message", "code", msg.Code, "err", err)
+		}
+	}
+	if err := c.handleMsg(msg); err != nil {
+		log.Debug("Failed to handle own istanbul message", "code", msg.Code, "err", err)
+	}
+}
+
+// handleMsg memproses satu pesan konsensus. Pemanggil harus memegang lock.
+func (c *core) handleMsg(msg *message) error {
+	if !c.valSet.contains(msg.sender) {
+		return errUnauthorized
+	}
+	// Tahan pesan untuk nomor blok berikutnya, buang pesan lama
+	if msg.Sequence > c.sequence {
+		if len(c.backlog) < maxBacklog {
+			c.backlog = append(c.backlog, msg)
+		}
+		return nil
+	}
+	if msg.Sequence < c.sequence || c.committed {
+		return nil
+	}
+	if msg.Code == msgRoundChange {
+		return c.handleRoundChange(msg)
+	}
+	if msg.Round != c.round {
+		return nil
+	}
+	switch msg.Code {
+	case msgPreprepare:
+		return c.handlePreprepare(msg)
+	case msgPrepare:
+		c.prepares[msg.sender] = msg.Digest
+		c.checkPrepared()
+		return nil
+	case msgCommit:
+		return c.handleCommit(msg)
+	default:
+		return errInvalidProposal
+	}
+}
+
+// handlePreprepare memverifikasi usulan dari pengusul ronde dan membalasnya
+// dengan PREPARE.
+func (c *core) handlePreprepare(msg *message) error {
+	if msg.sender != c.valSet.proposer(c.lastProposer, c.round) {
+		return errNotFromProposer
+	}
+	if c.proposal != nil {
+		return nil
+	}
+	block := new(types.Block)
+	if err := rlp.DecodeBytes(msg.Payload, block); err != nil {
+		return err
+	}
+	header := block.Header()
+	if block.NumberU64() != c.sequence || proposalHash(header) != msg.Digest {
+		return errInvalidProposal
+	}
+	if types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)) != header.TxHash {
+		return errInvalidProposal
+	}
+	if c.locked != nil && proposalHash(c.locked.Header()) != msg.Digest {
+		return errLockedProposal
+	}
+	if err := c.engine.verifyHeader(c.chain, header, nil, false); err != nil {
+		return err
+	}
+	c.proposal = block
+	c.broadcast(&message{Code: msgPrepare, Digest: msg.Digest})
+
+	// PREPARE dan COMMIT bisa saja tiba sebelum PRE-PREPARE
+	c.checkPrepared()
+	c.checkCommitted()
+	return nil
+}
+
+// handleCommit memverifikasi commit seal dari validator dan mencatatnya.
+func (c *core) handleCommit(msg *message) error {
+	pubkey, err := crypto.SigToPub(crypto.Keccak256(committedSealData(msg.Digest)), msg.Payload)
+	if err != nil || crypto.PubkeyToAddress(*pubkey) != msg.sender {
+		return errInvalidCommittedSeals
+	}
+	c.commits[msg.sender] = msg
+	c.checkCommitted()
+	return nil
+}
+
+// checkPrepared mengirim COMMIT begitu quorum validator menyatakan PREPARE untuk
+// usulan ronde ini, sekaligus mengunci node pada usulan tersebut.
+func (c *core) checkPrepared() {
+	if c.proposal == nil || c.sentCommit {
+		return
+	}
+	digest := proposalHash(c.proposal.Header())
+
+	count := 0
+	for _, d := range c.prepares {
+		if d == digest {
+			count++
+		}
+	}
+	if count < c.valSet.quorum() {
+		return
+	}
+	c.locked = c.proposal
+	c.sentCommit = true
+
+	seal, err := c.engine.sign(mimetypeIstanbul, committedSealData(digest))
+	if err != nil {
+		log.Error("Failed to sign committed seal", "err", err)
+		return
+	}
+	c.broadcast(&message{Code: msgCommit, Digest: digest, Payload: seal})
+}
+
+// checkCommitted menyelesaikan nomor blok ini begitu quorum commit seal terkumpul.
+func (c *core) checkCommitted() {
+	if c.proposal == nil || c.committed {
+		return
+	}
+	digest := proposalHash(c.proposal.Header())
+
+	// Susun commit seal sesuai urutan validator agar hasilnya deterministik
+	var seals [][]byte
+	for _, v := range c.valSet.validators {
+		if msg, ok := c.commits[v]; ok && msg.Digest == digest {
+			seals = append(seals, msg.Payload)
+		}
+	}
+	if len(seals) < c.valSet.quorum() {
+		return
+	}
+	header := c.proposal.Header()
+	extra, err := ExtractIstanbulExtra(header)
+	if err != nil {
+		return
+	}
+	extra.CommittedSeal = seals
+	if err := encodeExtra(header, extra); err != nil {
+		return
+	}
+	block := c.proposal.WithSeal(header)
+	c.committed = true
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	// Usulan lokal dikirim ke miner, usulan validator lain ke backend
+	var results chan<- *types.Block
+	if c.pending != nil && proposalHash(c.pending.Header()) == digest {
+		results = c.results
+	}
+	c.pending, c.results = nil, nil
+
+	log.Info("Committed istanbul block", "number", block.Number(), "round", c.round, "hash", block.Hash(), "seals", len(seals))
+	go c.engine.commit(block, results)
+}
+
+// handleRoundChange mencatat permintaan pergantian ronde. Node ikut meminta jika
+// f+1 validator sudah meminta ronde yang lebih tinggi, dan pindah ronde begitu
+// quorum validator setuju.
+func (c *core) handleRoundChange(msg *message) error {
+	if msg.Round <= c.round {
+		return nil
+	}
+	votes, ok := c.roundChanges[msg.Round]
+	if !ok {
+		votes = make(map[common.Address]struct{})
+		c.roundChanges[msg.Round] = votes
+	}
+	votes[msg.sender] = struct{}{}
+
+	if len(votes) >= c.valSet.f()+1 {
+		c.sendRoundChange(msg.Round)
+	}
+	if len(votes) >= c.valSet.quorum() && msg.Round > c.round {
+		c.startRound(msg.Round)
+	}
+	return nil
+}
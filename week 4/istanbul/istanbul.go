@@ -0,0 +1,712 @@
+// Paket istanbul mengimplementasikan mesin konsensus Istanbul BFT (IBFT) dengan
+// ronde PRE-PREPARE/PREPARE/COMMIT dan finalitas instan.
+package istanbul
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const (
+	inmemorySignatures = 4096 // Jumlah signature blok terbaru yang disimpan di memori
+
+	mimetypeIstanbul = "application/x-istanbul-header" // Tipe data yang ditandatangani oleh validator
+)
+
+// Konstanta protokol Istanbul BFT.
+var (
+	defaultRequestTimeout = uint64(10000) // Batas waktu default ronde pertama dalam milidetik
+	defaultHaltThreshold  = uint64(10)    // Jumlah default periode blok tanpa blok baru sebelum rantai dianggap berhenti
+
+	extraVanity = 32 // Jumlah byte awalan extra-data yang dicadangkan untuk vanity
+
+	// IstanbulDigest adalah mix digest tetap yang menandai blok istanbul.
+	IstanbulDigest = common.HexToHash("0x63746963616c2062797a616e74696e65206661756c7420746f6c6572616e6365")
+
+	uncleHash   = types.CalcUncleHash(nil) // Selalu Keccak256(RLP([])) karena paman tidak berarti di luar PoW.
+	defaultDiff = big.NewInt(1)            // Kesulitan semua blok istanbul
+	emptyNonce  = types.BlockNonce{}       // Nonce blok istanbul selalu nol
+)
+
+// Berbagai pesan error untuk menandai blok atau pesan tidak valid. Error ini sengaja
+// dibuat privat agar bagian lain dari kode tidak bergantung pada error spesifik
+// mesin ini.
+var (
+	// errUnknownBlock dikembalikan ketika kumpulan validator diminta untuk blok
+	// yang bukan bagian dari blockchain lokal.
+	errUnknownBlock = errors.New("unknown block")
+
+	// errInvalidExtraDataFormat dikembalikan jika extra-data bukan format istanbul.
+	errInvalidExtraDataFormat = errors.New("invalid extra data format")
+
+	// errInvalidNonce dikembalikan jika nonce blok bukan nol.
+	errInvalidNonce = errors.New("invalid nonce")
+
+	// errMismatchingValidators dikembalikan jika daftar validator di header berbeda
+	// dari daftar validator induknya.
+	errMismatchingValidators = errors.New("mismatching validator list")
+
+	// errInvalidCoinbase dikembalikan jika coinbase blok bukan pengusulnya.
+	errInvalidCoinbase = errors.New("coinbase does not match proposer")
+
+	// errUnauthorized dikembalikan jika header atau pesan ditandatangani oleh pihak
+	// yang bukan validator.
+	errUnauthorized = errors.New("unauthorized validator")
+
+	// errInvalidSignature dikembalikan jika signature tidak bisa dipulihkan.
+	errInvalidSignature = errors.New("invalid signature")
+
+	// errInvalidCommittedSeals dikembalikan jika commit seal tidak valid atau ganda.
+	errInvalidCommittedSeals = errors.New("invalid committed seals")
+
+	// errEmptyCommittedSeals dikembalikan jika jumlah commit seal kurang dari quorum.
+	errEmptyCommittedSeals = errors.New("insufficient committed seals")
+
+	// errNotStarted dikembalikan jika pesan diterima sebelum mesin dijalankan.
+	errNotStarted = errors.New("istanbul engine not started")
+)
+
+// Config adalah parameter konsensus dari mesin Istanbul BFT.
+type Config struct {
+	BlockPeriod    uint64 `json:"blockperiod"`    // Jumlah detik minimum di antara blok
+	RequestTimeout uint64 `json:"requesttimeout"` // Batas waktu ronde pertama dalam milidetik, berlipat dua setiap ronde
+	HaltThreshold  uint64 `json:"haltthreshold"`  // Jumlah periode blok tanpa blok baru sebelum rantai dianggap berhenti dan dipulihkan
+}
+
+// SignerFn melakukan hash dan menandatangani data menggunakan akun pendukung.
+type SignerFn func(signer accounts.Account, mimeType string, message []byte) ([]byte, error)
+
+// Backend adalah lapisan jaringan yang dibutuhkan mesin istanbul untuk bertukar
+// pesan konsensus dan menyerahkan blok yang sudah final.
+type Backend interface {
+	// Broadcast mengirim pesan konsensus ke semua validator lain.
+	Broadcast(payload []byte) error
+
+	// Commit menyerahkan blok final yang tidak diusulkan oleh node lokal agar
+	// dimasukkan ke dalam rantai.
+	Commit(block *types.Block) error
+}
+
+// IstanbulExtra adalah bagian extra-data header istanbul setelah vanity.
+type IstanbulExtra struct {
+	Validators    []common.Address // Kumpulan validator yang berlaku untuk blok berikutnya
+	Seal          []byte           // Signature pengusul atas header
+	CommittedSeal [][]byte         // Commit seal dari paling sedikit quorum validator
+}
+
+// ExtractIstanbulExtra mengurai bagian istanbul dari extra-data header.
+func ExtractIstanbulExtra(header *types.Header) (*IstanbulExtra, error) {
+	if len(header.Extra) < extraVanity {
+		return nil, errInvalidExtraDataFormat
+	}
+	extra := new(IstanbulExtra)
+	if err := rlp.DecodeBytes(header.Extra[extraVanity:], extra); err != nil {
+		return nil, errInvalidExtraDataFormat
+	}
+	return extra, nil
+}
+
+// encodeExtra menulis ulang extra-data header dengan bagian istanbul yang diberikan.
+func encodeExtra(header *types.Header, extra *IstanbulExtra) error {
+	payload, err := rlp.EncodeToBytes(extra)
+	if err != nil {
+		return err
+	}
+	vanity := make([]byte, extraVanity)
+	copy(vanity, header.Extra)
+	header.Extra = append(vanity, payload...)
+	return nil
+}
+
+// filteredHeader mengembalikan salinan header tanpa commit seal, dan juga tanpa
+// signature pengusul jika keepSeal bernilai false.
+func filteredHeader(header *types.Header, keepSeal bool) *types.Header {
+	cpy := types.CopyHeader(header)
+	extra, err := ExtractIstanbulExtra(cpy)
+	if err != nil {
+		return cpy
+	}
+	if !keepSeal {
+		extra.Seal = []byte{}
+	}
+	extra.CommittedSeal = [][]byte{}
+	encodeExtra(cpy, extra)
+	return cpy
+}
+
+// SealHash mengembalikan hash yang ditandatangani oleh pengusul, yaitu hash header
+// tanpa signature pengusul dan tanpa commit seal.
+func SealHash(header *types.Header) common.Hash {
+	return filteredHeader(header, false).Hash()
+}
+
+// proposalHash mengembalikan hash usulan yang disepakati di ronde konsensus, yaitu
+// hash header beserta signature pengusul tetapi tanpa commit seal.
+func proposalHash(header *types.Header) common.Hash {
+	return filteredHeader(header, true).Hash()
+}
+
+// IstanbulRLP mengembalikan byte rlp yang perlu ditandatangani oleh pengusul.
+func IstanbulRLP(header *types.Header) []byte {
+	b := new(bytes.Buffer)
+	encodeSigHeader(b, header)
+	return b.Bytes()
+}
+
+// encodeSigHeader menulis encoding RLP dari header tanpa signature pengusul dan
+// tanpa commit seal.
+func encodeSigHeader(w io.Writer, header *types.Header) {
+	if err := rlp.Encode(w, filteredHeader(header, false)); err != nil {
+		panic("can't encode: " + err.Error())
+	}
+}
+
+// ecrecover mengekstrak alamat pengusul dari header yang sudah ditandatangani.
+func ecrecover(header *types.Header, sigcache *lru.ARCCache) (common.Address, error) {
+	// Jika signature sudah ada di cache, kembalikan langsung
+	hash := header.Hash()
+	if address, known := sigcache.Get(hash); known {
+		return address.(common.Address), nil
+	}
+	extra, err := ExtractIstanbulExtra(header)
+	if err != nil {
+		return common.Address{}, err
+	}
+	pubkey, err := crypto.SigToPub(SealHash(header).Bytes(), extra.Seal)
+	if err != nil {
+		return common.Address{}, errInvalidSignature
+	}
+	signer := crypto.PubkeyToAddress(*pubkey)
+
+	sigcache.Add(hash, signer)
+	return signer, nil
+}
+
+// Istanbul adalah mesin konsensus Istanbul BFT. Kumpulan validator dicatat di
+// extra-data setiap header, dan blok dianggap final begitu quorum validator
+// menyertakan commit seal-nya.
+//
+// Kumpulan validator ditetapkan di genesis dan diwarisi apa adanya oleh setiap
+// blok; mesin ini belum mendukung pemungutan suara untuk menambah atau mengeluarkan
+// validator. Mengganti validator membutuhkan genesis baru atau hard fork.
+type Istanbul struct {
+	config     *Config       // Parameter konfigurasi mesin konsensus
+	signatures *lru.ARCCache // Signature dari blok terbaru agar verifikasi lebih cepat
+
+	signer common.Address // Alamat Ethereum dari kunci penandatangan
+	signFn SignerFn       // Fungsi signer untuk mengotorisasi hash dan pesan
+
+	chain   consensus.ChainHeaderReader // Rantai lokal untuk memverifikasi usulan
+	backend Backend                     // Lapisan jaringan untuk bertukar pesan
+	core    *core                       // Mesin status ronde konsensus
+	monitor *consensus.HaltMonitor      // Pendeteksi rantai berhenti yang memicu pemulihan ronde
+
+	lock sync.RWMutex // Melindungi field signer, chain, backend dan monitor
+}
+
+// New membuat mesin konsensus Istanbul BFT.
+func New(config *Config) *Istanbul {
+	// Isi parameter konsensus yang kosong dengan nilai default
+	conf := *config
+	if conf.RequestTimeout == 0 {
+		conf.RequestTimeout = defaultRequestTimeout
+	}
+	if conf.HaltThreshold == 0 {
+		conf.HaltThreshold = defaultHaltThreshold
+	}
+	signatures, _ := lru.NewARC(inmemorySignatures)
+
+	engine := &Istanbul{
+		config:     &conf,
+		signatures: signatures,
+	}
+	engine.core = newCore(engine)
+	return engine
+}
+
+// Authorize menyuntikkan kunci privat ke dalam mesin konsensus untuk mengusulkan
+// blok dan ikut memberikan suara.
+func (sb *Istanbul) Authorize(signer common.Address, signFn SignerFn) {
+	sb.lock.Lock()
+	defer sb.lock.Unlock()
+
+	sb.signer = signer
+	sb.signFn = signFn
+}
+
+// Start menghubungkan mesin konsensus dengan rantai lokal dan lapisan jaringan
+// sehingga node bisa ikut serta dalam ronde konsensus, lalu menjalankan pendeteksi
+// rantai berhenti. Rantai dianggap berhenti jika tidak ada blok baru selama
+// HaltThreshold kali periode blok yang diharapkan, yaitu BlockPeriod atau batas
+// waktu ronde pertama jika lebih lama.
+func (sb *Istanbul) Start(chain consensus.ChainHeaderReader, backend Backend) {
+	period := time.Duration(sb.config.BlockPeriod) * time.Second
+	if timeout := time.Duration(sb.config.RequestTimeout) * time.Millisecond; timeout > period {
+		period = timeout
+	}
+	monitor := consensus.NewHaltMonitor(chain, period, sb.config.HaltThreshold, sb.core.recoverHalt)
+
+	sb.lock.Lock()
+	previous := sb.monitor
+	sb.chain = chain
+	sb.backend = backend
+	sb.monitor = monitor
+	sb.lock.Unlock()
+
+	if previous != nil {
+		previous.Stop()
+	}
+	monitor.Start()
+}
+
+// HandleMsg memproses pesan konsensus yang diterima dari validator lain.
+func (sb *Istanbul) HandleMsg(payload []byte) error {
+	sb.lock.RLock()
+	started := sb.chain != nil && sb.backend != nil
+	sb.lock.RUnlock()
+
+	if !started {
+		return errNotStarted
+	}
+	msg, err := decodeMessage(payload)
+	if err != nil {
+		return err
+	}
+	return sb.core.handle(msg)
+}
+
+// sign menandatangani data dengan kunci validator lokal.
+func (sb *Istanbul) sign(mimeType string, data []byte) ([]byte, error) {
+	sb.lock.RLock()
+	signer, signFn := sb.signer, sb.signFn
+	sb.lock.RUnlock()
+
+	return signFn(accounts.Account{Address: signer}, mimeType, data)
+}
+
+// Author mengimplementasikan consensus.Engine, mengembalikan alamat pengusul yang
+// dipulihkan dari signature di bagian extra-data header.
+func (sb *Istanbul) Author(header *types.Header) (common.Address, error) {
+	return ecrecover(header, sb.signatures)
+}
+
+// VerifyHeader memeriksa apakah header sesuai dengan aturan konsensus. Parameter
+// seal diabaikan: commit seal selalu diperiksa, karena finalitas instan yang
+// dilaporkan FinalizedHeader bergantung pada setiap blok di rantai membawa quorum
+// commit seal yang valid.
+func (sb *Istanbul) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, seal bool) error {
+	return sb.verifyHeader(chain, header, nil, true)
+}
+
+// VerifyHeaders mirip dengan VerifyHeader, tetapi memverifikasi sekumpulan header
+// secara berurutan di latar belakang. Metode mengembalikan saluran keluar untuk
+// membatalkan operasi dan saluran hasil (urutannya sama dengan inputan slice).
+func (sb *Istanbul) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	abort := make(chan struct{})
+	results := make(chan error, len(headers))
+
+	go func() {
+		for i, header := range headers {
+			err := sb.verifyHeader(chain, header, headers[:i], true)
+
+			select {
+			case <-abort:
+				return
+			case results <- err:
+			}
+		}
+	}()
+	return abort, results
+}
+
+// verifyHeader memeriksa apakah header sesuai dengan aturan konsensus. Pemanggil
+// boleh memberikan sekumpulan induk (urutan naik) agar tidak perlu mencarinya dari
+// database. Commit seal hanya diperiksa jika committed bernilai true, karena
+// usulan yang sedang disepakati belum memilikinya.
+func (sb *Istanbul) verifyHeader(chain consensus.ChainHeaderReader, header *types.Header, parents []*types.Header, committed bool) error {
+	if header.Number == nil {
+		return errUnknownBlock
+	}
+	// Jangan buang waktu memeriksa blok dari masa depan
+	if header.Time > uint64(time.Now().Unix()) {
+		return consensus.ErrFutureBlock
+	}
+	// Pastikan extra-data berformat istanbul
+	if _, err := ExtractIstanbulExtra(header); err != nil {
+		return err
+	}
+	// Pastikan mix digest dan nonce sesuai dengan blok istanbul
+	if header.MixDigest != IstanbulDigest {
+		return consensus.ErrInvalidMixDigest
+	}
+	if header.Nonce != emptyNonce {
+		return errInvalidNonce
+	}
+	// Pastikan blok tidak berisi paman yang tidak berarti di BFT
+	if header.UncleHash != uncleHash {
+		return consensus.ErrInvalidUncleHash
+	}
+	// Pastikan kesulitan blok selalu 1
+	if header.Difficulty == nil || header.Difficulty.Cmp(defaultDiff) != 0 {
+		return consensus.ErrInvalidDifficulty
+	}
+	// Verifikasi bahwa batas gas <= 2^63-1
+	if header.GasLimit > params.MaxGasLimit {
+		return fmt.Errorf("invalid gasLimit: have %v, max %v", header.GasLimit, params.MaxGasLimit)
+	}
+	// Semua pemeriksaan dasar lolos, verifikasi field yang bergantung pada induk
+	return sb.verifyCascadingFields(chain, header, parents, committed)
+}
+
+// verifyCascadingFields memverifikasi semua field header yang bergantung pada
+// sekumpulan header sebelumnya.
+func (sb *Istanbul) verifyCascadingFields(chain consensus.ChainHeaderReader, header *types.Header, parents []*types.Header, committed bool) error {
+	// Blok genesis selalu valid
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil
+	}
+	var parent *types.Header
+	if len(parents) > 0 {
+		parent = parents[len(parents)-1]
+	} else {
+		parent = chain.GetHeader(header.ParentHash, number-1)
+	}
+	if parent == nil || parent.Number.Uint64() != number-1 || parent.Hash() != header.ParentHash {
+		return consensus.ErrUnknownAncestor
+	}
+	if parent.Time+sb.config.BlockPeriod > header.Time {
+		return consensus.ErrInvalidTimestamp
+	}
+	// Verifikasi bahwa gas yang dipakai <= batas gas
+	if header.GasUsed > header.GasLimit {
+		return fmt.Errorf("invalid gasUsed: have %d, gasLimit %d", header.GasUsed, header.GasLimit)
+	}
+	if !chain.Config().IsLondon(header.Number) {
+		if header.BaseFee != nil {
+			return fmt.Errorf("invalid baseFee before fork: have %d, want <nil>", header.BaseFee)
+		}
+		if err := misc.VerifyGaslimit(parent.GasLimit, header.GasLimit); err != nil {
+			return err
+		}
+	} else if err := misc.VerifyEip1559Header(chain.Config(), parent, header); err != nil {
+		return err
+	}
+	// Kumpulan validator diwarisi dari induk dan tidak boleh berubah
+	valSet, err := sb.validators(parent)
+	if err != nil {
+		return err
+	}
+	extra, _ := ExtractIstanbulExtra(header)
+	if !valSet.equal(extra.Validators) {
+		return errMismatchingValidators
+	}
+	if err := sb.verifySigner(valSet, header); err != nil {
+		return err
+	}
+	if !committed {
+		return nil
+	}
+	return sb.verifyCommittedSeals(valSet, header, extra)
+}
+
+// validators mengembalikan kumpulan validator yang tercatat di header.
+func (sb *Istanbul) validators(header *types.Header) (*validatorSet, error) {
+	extra, err := ExtractIstanbulExtra(header)
+	if err != nil {
+		return nil, err
+	}
+	return newValidatorSet(extra.Validators), nil
+}
+
+// verifySigner memeriksa apakah header ditandatangani oleh validator yang juga
+// tercatat sebagai coinbase blok.
+func (sb *Istanbul) verifySigner(valSet *validatorSet, header *types.Header) error {
+	signer, err := ecrecover(header, sb.signatures)
+	if err != nil {
+		return err
+	}
+	if !valSet.contains(signer) {
+		return errUnauthorized
+	}
+	if header.Coinbase != signer {
+		return errInvalidCoinbase
+	}
+	return nil
+}
+
+// verifyCommittedSeals memeriksa apakah header memiliki commit seal yang valid dari
+// paling sedikit quorum validator yang berbeda.
+func (sb *Istanbul) verifyCommittedSeals(valSet *validatorSet, header *types.Header, extra *IstanbulExtra) error {
+	if len(extra.CommittedSeal) == 0 {
+		return errEmptyCommittedSeals
+	}
+	// Setiap validator hanya boleh menyumbang satu seal, jadi tolak header dengan seal
+	// lebih banyak dari validator sebelum memulihkan signature apa pun
+	if len(extra.CommittedSeal) > valSet.size() {
+		return errInvalidCommittedSeals
+	}
+	signers, err := recoverCommittedSeals(valSet, crypto.Keccak256(committedSealData(proposalHash(header))), extra.CommittedSeal)
+	if err != nil {
+		return err
+	}
+	seen := make(map[common.Address]struct{})
+	for _, signer := range signers {
+		if _, ok := seen[signer]; ok {
+			return errInvalidCommittedSeals
+		}
+		seen[signer] = struct{}{}
+	}
+	if len(seen) < valSet.quorum() {
+		return errEmptyCommittedSeals
+	}
+	return nil
+}
+
+// recoverCommittedSeals memulihkan penandatangan dari semua commit seal secara
+// paralel. Pemulihan ECDSA mendominasi waktu verifikasi header, jadi seal dibagi ke
+// sebanyak GOMAXPROCS pekerja agar verifikasi tetap cepat untuk validator yang banyak.
+// Semua pekerja berhenti begitu satu seal tidak valid atau bukan dari validator.
+func recoverCommittedSeals(valSet *validatorSet, data []byte, seals [][]byte) ([]common.Address, error) {
+	workers := runtime.GOMAXPROCS(0)
+	if len(seals) < workers {
+		workers = len(seals)
+	}
+	var (
+		signers = make([]common.Address, len(seals))
+		failed  int32
+		pend    sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		pend.Add(1)
+		go func(w int) {
+			defer pend.Done()
+			for i := w; i < len(seals); i += workers {
+				if atomic.LoadInt32(&failed) != 0 {
+					return
+				}
+				pubkey, err := crypto.SigToPub(data, seals[i])
+				if err != nil {
+					atomic.StoreInt32(&failed, 1)
+					return
+				}
+				signers[i] = crypto.PubkeyToAddress(*pubkey)
+				if !valSet.contains(signers[i]) {
+					atomic.StoreInt32(&failed, 1)
+					return
+				}
+			}
+		}(w)
+	}
+	pend.Wait()
+
+	if failed != 0 {
+		return nil, errInvalidCommittedSeals
+	}
+	return signers, nil
+}
+
+// VerifyUncles mengimplementasikan consensus.Engine, selalu mengembalikan error untuk
+// paman karena mekanisme konsensus ini tidak mengizinkan paman.
+func (sb *Istanbul) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
+	if len(block.Uncles()) > 0 {
+		return consensus.ErrTooManyUncles
+	}
+	return nil
+}
+
+// Prepare mengimplementasikan consensus.Engine, menyiapkan semua field konsensus
+// dari header sebelum transaksi dijalankan di atasnya.
+func (sb *Istanbul) Prepare(chain consensus.ChainHeaderReader, header *types.Header) error {
+	number := header.Number.Uint64()
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	valSet, err := sb.validators(parent)
+	if err != nil {
+		return err
+	}
+	sb.lock.RLock()
+	header.Coinbase = sb.signer
+	sb.lock.RUnlock()
+
+	header.Nonce = emptyNonce
+	header.MixDigest = IstanbulDigest
+	header.Difficulty = new(big.Int).Set(defaultDiff)
+
+	// Validator diwarisi dari induk, segel diisi saat Seal
+	if err := encodeExtra(header, &IstanbulExtra{
+		Validators:    valSet.validators,
+		Seal:          []byte{},
+		CommittedSeal: [][]byte{},
+	}); err != nil {
+		return err
+	}
+	// Pastikan stempel waktu memiliki jeda yang benar
+	header.Time = parent.Time + sb.config.BlockPeriod
+	if header.Time < uint64(time.Now().Unix()) {
+		header.Time = uint64(time.Now().Unix())
+	}
+	return nil
+}
+
+// Finalize mengimplementasikan consensus.Engine. Istanbul tidak memberikan block
+// reward, jadi hanya root state akhir yang ditetapkan.
+func (sb *Istanbul) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header) {
+	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
+	header.UncleHash = types.CalcUncleHash(nil)
+}
+
+// FinalizeAndAssemble mengimplementasikan consensus.Engine, menjalankan Finalize
+// lalu merakit blok terakhir.
+func (sb *Istanbul) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
+	sb.Finalize(chain, header, state, txs, uncles)
+	return types.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil)), nil
+}
+
+// Seal mengimplementasikan consensus.Engine. Blok ditandatangani sebagai usulan
+// lalu diserahkan ke ronde konsensus; hasilnya baru dikirim ke results setelah
+// quorum validator memberikan commit seal.
+func (sb *Istanbul) Seal(chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
+	header := block.Header()
+
+	// Penyegelan blok genesis tidak didukung
+	number := header.Number.Uint64()
+	if number == 0 {
+		return errUnknownBlock
+	}
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	valSet, err := sb.validators(parent)
+	if err != nil {
+		return err
+	}
+	sb.lock.RLock()
+	signer := sb.signer
+	sb.lock.RUnlock()
+
+	// Berhenti jika kita bukan validator
+	if !valSet.contains(signer) {
+		return errUnauthorized
+	}
+	// Tandatangani header sebagai usulan
+	sighash, err := sb.sign(mimetypeIstanbul, IstanbulRLP(header))
+	if err != nil {
+		return err
+	}
+	extra, err := ExtractIstanbulExtra(header)
+	if err != nil {
+		return err
+	}
+	extra.Seal = sighash
+	if err := encodeExtra(header, extra); err != nil {
+		return err
+	}
+	proposal := block.WithSeal(header)
+
+	// Tunggu sampai waktu blok tiba, lalu serahkan ke ronde konsensus
+	delay := time.Until(time.Unix(int64(header.Time), 0))
+	log.Trace("Waiting for slot to propose", "number", number, "delay", common.PrettyDuration(delay))
+	go func() {
+		select {
+		case <-stop:
+			return
+		case <-time.After(delay):
+		}
+		sb.core.request(chain, proposal, results, stop)
+	}()
+	return nil
+}
+
+// commit menyerahkan blok yang sudah mencapai quorum commit seal, baik ke saluran
+// hasil Seal lokal maupun ke backend.
+func (sb *Istanbul) commit(block *types.Block, results chan<- *types.Block) {
+	if results != nil {
+		select {
+		case results <- block:
+		default:
+			log.Warn("Sealing result is not read by miner", "sealhash", SealHash(block.Header()))
+		}
+		return
+	}
+	sb.lock.RLock()
+	backend := sb.backend
+	sb.lock.RUnlock()
+
+	if backend != nil {
+		if err := backend.Commit(block); err != nil {
+			log.Warn("Failed to commit istanbul block", "number", block.Number(), "hash", block.Hash(), "err", err)
+		}
+	}
+}
+
+// CalcDifficulty adalah algoritma penyesuaian kesulitan, selalu mengembalikan 1.
+func (sb *Istanbul) CalcDifficulty(chain consensus.ChainHeaderReader, time uint64, parent *types.Header) *big.Int {
+	return new(big.Int).Set(defaultDiff)
+}
+
+// SealHash mengembalikan hash dari sebuah blok sebelum disegel.
+func (sb *Istanbul) SealHash(header *types.Header) common.Hash {
+	return SealHash(header)
+}
+
+// Close mengimplementasikan consensus.Engine, menghentikan ronde konsensus yang
+// sedang berjalan dan pendeteksi rantai berhenti.
+func (sb *Istanbul) Close() error {
+	sb.lock.RLock()
+	monitor := sb.monitor
+	sb.lock.RUnlock()
+
+	if monitor != nil {
+		monitor.Stop()
+	}
+	sb.core.stop()
+	return nil
+}
+
+// FinalizedHeader mengimplementasikan consensus.Engine. IBFT memiliki finalitas
+// instan karena VerifyHeader dan VerifyHeaders selalu memeriksa commit seal dari
+// quorum validator, jadi header saat ini selalu final. Jaminan ini hanya berlaku
+// selama lebih dari dua pertiga kumpulan validator genesis jujur, karena kumpulan
+// tersebut tidak pernah berubah.
+func (sb *Istanbul) FinalizedHeader(chain consensus.ChainHeaderReader) *types.Header {
+	return chain.CurrentHeader()
+}
+
+// SafeHeader mengimplementasikan consensus.Engine, sama dengan FinalizedHeader.
+func (sb *Istanbul) SafeHeader(chain consensus.ChainHeaderReader) *types.Header {
+	return chain.CurrentHeader()
+}
+
+// APIs mengimplementasikan consensus.Engine, mengembalikan API RPC untuk
+// memeriksa kumpulan validator.
+func (sb *Istanbul) APIs(chain consensus.ChainHeaderReader) []rpc.API {
+	return []rpc.API{{
+		Namespace: "istanbul",
+		Service:   &API{chain: chain, istanbul: sb},
+	}}
+}
@@ -0,0 +1,408 @@
+package istanbul
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/consensustest"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// tester menyiapkan rantai di memori dan kunci validator untuk pengujian mesin
+// Istanbul BFT.
+type tester struct {
+	*consensustest.Accounts // Validator sesuai urutan di genesis
+
+	t     *testing.T
+	chain *consensustest.HeaderChain
+}
+
+// newTester membuat rantai dengan n validator di genesis.
+func newTester(t *testing.T, n int) *tester {
+	t.Helper()
+
+	tt := &tester{Accounts: consensustest.NewAccounts(n), t: t}
+	genesis := consensustest.Genesis()
+	genesis.MixDigest = IstanbulDigest
+	if err := encodeExtra(genesis, &IstanbulExtra{Validators: tt.Addrs, Seal: []byte{}, CommittedSeal: [][]byte{}}); err != nil {
+		t.Fatalf("failed to encode genesis extra-data: %v", err)
+	}
+	tt.chain = consensustest.NewHeaderChain(params.AllCliqueProtocolChanges, genesis)
+	return tt
+}
+
+// commit menandatangani header sebagai usulan dari proposer, lalu menambahkan commit
+// seal dari setiap sealer, seolah-olah ronde konsensus sudah selesai.
+func (tt *tester) commit(header *types.Header, proposer common.Address, sealers []common.Address) {
+	extra, err := ExtractIstanbulExtra(header)
+	if err != nil {
+		tt.t.Fatalf("failed to extract extra-data: %v", err)
+	}
+	extra.Seal, extra.CommittedSeal = []byte{}, [][]byte{}
+	encodeExtra(header, extra)
+
+	if extra.Seal, err = tt.Sign(proposer, SealHash(header)); err != nil {
+		tt.t.Fatalf("failed to sign proposal: %v", err)
+	}
+	encodeExtra(header, extra)
+
+	data := crypto.Keccak256Hash(committedSealData(proposalHash(header)))
+	for _, sealer := range sealers {
+		seal, err := tt.Sign(sealer, data)
+		if err != nil {
+			tt.t.Fatalf("failed to sign committed seal: %v", err)
+		}
+		extra.CommittedSeal = append(extra.CommittedSeal, seal)
+	}
+	encodeExtra(header, extra)
+}
+
+// extend menambahkan n blok ke rantai, masing-masing diusulkan oleh pengusul ronde
+// pertama dan diberi commit seal oleh semua validator.
+func (tt *tester) extend(engine *Istanbul, n int) []*types.Header {
+	tt.t.Helper()
+
+	pick := func(header, parent *types.Header) common.Address {
+		valSet, _ := engine.validators(parent)
+		last, _ := engine.Author(parent)
+		proposer := valSet.proposer(last, 0)
+		engine.Authorize(proposer, tt.SignFn(proposer))
+		return proposer
+	}
+	seal := func(header, parent *types.Header, proposer common.Address) {
+		header.Time = parent.Time + engine.config.BlockPeriod
+		tt.commit(header, proposer, tt.Addrs)
+	}
+	return consensustest.Extend(tt.t, tt.chain, engine, n, pick, seal)
+}
+
+// Menguji bahwa quorum selalu ceil(2n/3), sehingga dua quorum mana pun beririsan di
+// lebih dari f validator.
+func TestQuorum(t *testing.T) {
+	tests := []struct {
+		n, f, quorum int
+	}{
+		{1, 0, 1}, {2, 0, 2}, {3, 0, 2}, {4, 1, 3}, {5, 1, 4},
+		{6, 1, 4}, {7, 2, 5}, {8, 2, 6}, {9, 2, 6}, {10, 3, 7},
+	}
+	for _, tt := range tests {
+		set := newValidatorSet(make([]common.Address, tt.n))
+		if f := set.f(); f != tt.f {
+			t.Errorf("n=%d: f mismatch: have %d, want %d", tt.n, f, tt.f)
+		}
+		if quorum := set.quorum(); quorum != tt.quorum {
+			t.Errorf("n=%d: quorum mismatch: have %d, want %d", tt.n, quorum, tt.quorum)
+		}
+		if overlap := 2*set.quorum() - tt.n; overlap <= tt.f {
+			t.Errorf("n=%d: quorums overlap in %d validators, want more than %d", tt.n, overlap, tt.f)
+		}
+	}
+}
+
+// Menguji bahwa rantai dengan commit seal dari semua validator lolos verifikasi satu
+// per satu dan dalam batch.
+func TestVerifyChain(t *testing.T) {
+	tt := newTester(t, 4)
+	headers := tt.extend(New(&Config{BlockPeriod: 1}), 8)
+
+	consensustest.VerifyChain(t, tt.chain, headers, New(&Config{BlockPeriod: 1}), New(&Config{BlockPeriod: 1}))
+}
+
+// Menguji bahwa header yang melanggar aturan Istanbul ditolak dengan error yang
+// sesuai, terutama commit seal yang tidak mencapai quorum.
+func TestVerifyHeaderReject(t *testing.T) {
+	tt := newTester(t, 5)
+	headers := tt.extend(New(&Config{BlockPeriod: 1}), 3)
+
+	block := headers[1]
+	proposer, _ := New(&Config{}).Author(block)
+	strangers := consensustest.NewAccounts(1)
+	outsider := strangers.Addrs[0]
+
+	// resign menyegel ulang header setelah diubah, dengan commit seal dari semua validator
+	resign := func(h *types.Header) { tt.commit(h, proposer, tt.Addrs) }
+	// foreign menyegel header dengan kunci outsider yang sementara dianggap dikenal
+	foreign := func(h *types.Header, proposer common.Address, sealers []common.Address) {
+		tt.Keys[outsider] = strangers.Keys[outsider]
+		defer delete(tt.Keys, outsider)
+		tt.commit(h, proposer, sealers)
+	}
+	quorum := newValidatorSet(tt.Addrs).quorum()
+
+	tests := append(consensustest.HeaderRejects(headers[0], resign), []consensustest.Reject{
+		{Name: "extra format", Mutate: func(h *types.Header) { h.Extra = h.Extra[:extraVanity+1] }, Want: errInvalidExtraDataFormat},
+		{Name: "nonce", Mutate: func(h *types.Header) { h.Nonce = types.BlockNonce{1}; resign(h) }, Want: errInvalidNonce},
+		{Name: "validators", Mutate: func(h *types.Header) {
+			extra, _ := ExtractIstanbulExtra(h)
+			extra.Validators = extra.Validators[1:]
+			encodeExtra(h, extra)
+			resign(h)
+		}, Want: errMismatchingValidators},
+		{Name: "coinbase", Mutate: func(h *types.Header) { h.Coinbase = outsider; resign(h) }, Want: errInvalidCoinbase},
+		{Name: "unauthorized", Mutate: func(h *types.Header) {
+			h.Coinbase = outsider
+			foreign(h, outsider, tt.Addrs)
+		}, Want: errUnauthorized},
+		{Name: "no committed seals", Mutate: func(h *types.Header) { tt.commit(h, proposer, nil) }, Want: errEmptyCommittedSeals},
+		{Name: "below quorum", Mutate: func(h *types.Header) { tt.commit(h, proposer, tt.Addrs[:quorum-1]) }, Want: errEmptyCommittedSeals},
+		{Name: "duplicate seal", Mutate: func(h *types.Header) {
+			tt.commit(h, proposer, append(tt.Addrs[:quorum-1:quorum-1], tt.Addrs[0]))
+		}, Want: errInvalidCommittedSeals},
+		{Name: "foreign seal", Mutate: func(h *types.Header) {
+			foreign(h, proposer, append(tt.Addrs[:quorum:quorum], outsider))
+		}, Want: errInvalidCommittedSeals},
+		{Name: "too many seals", Mutate: func(h *types.Header) {
+			tt.commit(h, proposer, append(tt.Addrs[:len(tt.Addrs):len(tt.Addrs)], tt.Addrs[0]))
+		}, Want: errInvalidCommittedSeals},
+		{Name: "corrupt seal", Mutate: func(h *types.Header) {
+			extra, _ := ExtractIstanbulExtra(h)
+			extra.CommittedSeal[0] = make([]byte, crypto.SignatureLength)
+			encodeExtra(h, extra)
+		}, Want: errInvalidCommittedSeals},
+		{Name: "quorum seals", Mutate: func(h *types.Header) { tt.commit(h, proposer, tt.Addrs[:quorum]) }, Want: nil},
+	}...)
+	consensustest.VerifyRejects(t, tt.chain, block, func() consensus.Engine { return New(&Config{BlockPeriod: 1}) }, tests)
+}
+
+// Menguji bahwa pemulihan commit seal mengembalikan penandatangan sesuai urutan, dan
+// gagal jika ada seal dari pihak yang bukan validator di posisi mana pun.
+func TestRecoverCommittedSeals(t *testing.T) {
+	tt := newTester(t, 7)
+	valSet := newValidatorSet(tt.Addrs)
+	data := crypto.Keccak256(committedSealData(common.Hash{1}))
+
+	var seals [][]byte
+	for _, addr := range tt.Addrs {
+		seal, _ := crypto.Sign(data, tt.Keys[addr])
+		seals = append(seals, seal)
+	}
+	signers, err := recoverCommittedSeals(valSet, data, seals)
+	if err != nil {
+		t.Fatalf("failed to recover seals: %v", err)
+	}
+	for i, signer := range signers {
+		if signer != tt.Addrs[i] {
+			t.Errorf("seal %d: signer mismatch: have %x, want %x", i, signer, tt.Addrs[i])
+		}
+	}
+	stranger, _ := crypto.GenerateKey()
+	foreign, _ := crypto.Sign(data, stranger)
+	for i := range seals {
+		tampered := append([][]byte{}, seals...)
+		tampered[i] = foreign
+		if _, err := recoverCommittedSeals(valSet, data, tampered); err != errInvalidCommittedSeals {
+			t.Errorf("foreign seal at %d: error mismatch: have %v, want %v", i, err, errInvalidCommittedSeals)
+		}
+	}
+}
+
+// network menghubungkan beberapa mesin istanbul di memori. Pesan dikirim ke semua
+// node lain, dan blok final dari node mana pun dimasukkan ke rantai bersama.
+type network struct {
+	chain       *consensustest.HeaderChain
+	nodes       []*Istanbul
+	partitioned bool // Apakah semua pesan dibuang, seolah-olah setiap node terisolasi
+	lock        sync.Mutex
+}
+
+// backend adalah Backend untuk satu node di network.
+type backend struct {
+	net *network
+	id  int
+}
+
+// Broadcast mengimplementasikan Backend.
+func (b *backend) Broadcast(payload []byte) error {
+	b.net.lock.Lock()
+	defer b.net.lock.Unlock()
+
+	if b.net.partitioned {
+		return nil
+	}
+	for i, node := range b.net.nodes {
+		if i != b.id {
+			go node.HandleMsg(payload)
+		}
+	}
+	return nil
+}
+
+// Commit mengimplementasikan Backend.
+func (b *backend) Commit(block *types.Block) error {
+	return b.net.chain.Insert(block.Header())
+}
+
+// Menguji bahwa Seal di semua validator menghasilkan satu blok dengan commit seal
+// yang cukup, dan bahwa pihak luar tidak bisa menyegel.
+func TestSeal(t *testing.T) {
+	tt := newTester(t, 4)
+	net := &network{chain: tt.chain}
+	for i, addr := range tt.Addrs {
+		engine := New(&Config{RequestTimeout: 2000})
+		engine.Authorize(addr, tt.SignFn(addr))
+		engine.Start(tt.chain, &backend{net: net, id: i})
+		defer engine.Close()
+		net.nodes = append(net.nodes, engine)
+	}
+	parent := tt.chain.CurrentHeader()
+	results := make(chan *types.Block, len(net.nodes))
+	stop := make(chan struct{})
+	defer close(stop)
+
+	var header *types.Header
+	for _, engine := range net.nodes {
+		header = &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     big.NewInt(1),
+			GasLimit:   parent.GasLimit,
+			BaseFee:    misc.CalcBaseFee(tt.chain.Config(), parent),
+			TxHash:     types.EmptyRootHash,
+			UncleHash:  uncleHash,
+		}
+		if err := engine.Prepare(tt.chain, header); err != nil {
+			t.Fatalf("failed to prepare header: %v", err)
+		}
+		if err := engine.Seal(tt.chain, types.NewBlockWithHeader(header), results, stop); err != nil {
+			t.Fatalf("failed to seal block: %v", err)
+		}
+	}
+	// Blok final tiba lewat Seal milik pengusul, atau lewat Backend.Commit jika node
+	// lain lebih dulu mengumpulkan quorum dan rantai sudah maju sebelum pengusul
+	var sealed *types.Header
+	for timeout := time.After(10 * time.Second); sealed == nil; {
+		select {
+		case block := <-results:
+			sealed = block.Header()
+		case <-time.After(10 * time.Millisecond):
+			if head := tt.chain.CurrentHeader(); head.Number.Uint64() == 1 {
+				sealed = head
+			}
+		case <-timeout:
+			t.Fatalf("sealing result timeout")
+		}
+	}
+	if err := New(&Config{}).VerifyHeader(tt.chain, sealed, true); err != nil {
+		t.Fatalf("sealed header failed verification: %v", err)
+	}
+	extra, _ := ExtractIstanbulExtra(sealed)
+	if quorum := newValidatorSet(tt.Addrs).quorum(); len(extra.CommittedSeal) < quorum {
+		t.Fatalf("committed seal count mismatch: have %d, want at least %d", len(extra.CommittedSeal), quorum)
+	}
+	// Pihak luar tidak boleh menyegel
+	stranger, _ := crypto.GenerateKey()
+	outsider := New(&Config{})
+	outsider.Authorize(crypto.PubkeyToAddress(stranger.PublicKey), tt.SignFn(tt.Addrs[0]))
+	if err := outsider.Seal(tt.chain, types.NewBlockWithHeader(header), results, stop); err != errUnauthorized {
+		t.Fatalf("error mismatch: have %v, want %v", err, errUnauthorized)
+	}
+}
+
+// Menguji bahwa rantai pulih dari partisi jaringan yang menelan semua ROUND-CHANGE:
+// batas waktu ronde tidak mengirim ulang permintaan, jadi hanya pendeteksi rantai
+// berhenti yang membuat validator kembali sepakat setelah jaringan pulih.
+func TestHaltRecovery(t *testing.T) {
+	tt := newTester(t, 4)
+	net := &network{chain: tt.chain, partitioned: true}
+	for i, addr := range tt.Addrs {
+		engine := New(&Config{RequestTimeout: 50, HaltThreshold: 4})
+		engine.Authorize(addr, tt.SignFn(addr))
+		engine.Start(tt.chain, &backend{net: net, id: i})
+		defer engine.Close()
+		net.nodes = append(net.nodes, engine)
+	}
+	parent := tt.chain.CurrentHeader()
+	results := make(chan *types.Block, len(net.nodes))
+	stop := make(chan struct{})
+	defer close(stop)
+
+	for _, engine := range net.nodes {
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     big.NewInt(1),
+			GasLimit:   parent.GasLimit,
+			BaseFee:    misc.CalcBaseFee(tt.chain.Config(), parent),
+			TxHash:     types.EmptyRootHash,
+			UncleHash:  uncleHash,
+		}
+		if err := engine.Prepare(tt.chain, header); err != nil {
+			t.Fatalf("failed to prepare header: %v", err)
+		}
+		if err := engine.Seal(tt.chain, types.NewBlockWithHeader(header), results, stop); err != nil {
+			t.Fatalf("failed to seal block: %v", err)
+		}
+	}
+	// Tunggu sampai batas waktu ronde pertama lewat dan rantai dianggap berhenti
+	// selama partisi, lalu pulihkan jaringan
+	api := &API{chain: tt.chain, istanbul: net.nodes[0]}
+	for timeout := time.After(5 * time.Second); ; {
+		status, err := api.GetHaltStatus()
+		if err != nil {
+			t.Fatalf("failed to retrieve halt status: %v", err)
+		}
+		if status.Halted && status.Recoveries > 0 {
+			break
+		}
+		select {
+		case <-time.After(10 * time.Millisecond):
+		case <-timeout:
+			t.Fatalf("halt detection timeout")
+		}
+	}
+	net.lock.Lock()
+	net.partitioned = false
+	net.lock.Unlock()
+
+	for timeout := time.After(10 * time.Second); tt.chain.CurrentHeader().Number.Uint64() == 0; {
+		select {
+		case block := <-results:
+			if err := tt.chain.Insert(block.Header()); err != nil {
+				t.Fatalf("failed to insert sealed block: %v", err)
+			}
+		case <-time.After(10 * time.Millisecond):
+		case <-timeout:
+			t.Fatalf("chain did not recover after partition")
+		}
+	}
+	if err := New(&Config{}).VerifyHeader(tt.chain, tt.chain.CurrentHeader(), true); err != nil {
+		t.Fatalf("recovered header failed verification: %v", err)
+	}
+	for timeout := time.After(5 * time.Second); ; {
+		if status, _ := api.GetHaltStatus(); !status.Halted {
+			break
+		}
+		select {
+		case <-time.After(10 * time.Millisecond):
+		case <-timeout:
+			t.Fatalf("halt status not cleared after recovery")
+		}
+	}
+	if _, err := (&API{chain: tt.chain, istanbul: New(&Config{})}).GetHaltStatus(); err != errNotStarted {
+		t.Errorf("error mismatch: have %v, want %v", err, errNotStarted)
+	}
+}
+
+// Menguji bahwa API mengembalikan kumpulan validator genesis di setiap blok.
+func TestAPIValidators(t *testing.T) {
+	tt := newTester(t, 3)
+	headers := tt.extend(New(&Config{BlockPeriod: 1}), 2)
+	api := &API{chain: tt.chain, istanbul: New(&Config{})}
+
+	validators, err := api.GetValidators(nil)
+	if err != nil || len(validators) != len(tt.Addrs) {
+		t.Fatalf("validators mismatch: have %d (%v), want %d", len(validators), err, len(tt.Addrs))
+	}
+	atHash, err := api.GetValidatorsAtHash(headers[0].Hash())
+	if err != nil || len(atHash) != len(tt.Addrs) {
+		t.Fatalf("validators at hash mismatch: have %d (%v), want %d", len(atHash), err, len(tt.Addrs))
+	}
+	if _, err := api.GetValidatorsAtHash(common.Hash{1}); err != errUnknownBlock {
+		t.Fatalf("error mismatch: have %v, want %v", err, errUnknownBlock)
+	}
+}
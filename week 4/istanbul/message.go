@@ -0,0 +1,56 @@
+package istanbul
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Kode pesan konsensus istanbul.
+const (
+	msgPreprepare  uint64 = iota // Pengusul mengirim blok yang diusulkan untuk ronde ini
+	msgPrepare                   // Validator menyatakan telah menerima usulan yang valid
+	msgCommit                    // Validator mengikat diri pada usulan beserta commit seal-nya
+	msgRoundChange               // Validator meminta pindah ke ronde berikutnya
+)
+
+// message adalah satu pesan konsensus yang dipertukarkan antar validator.
+type message struct {
+	Code      uint64      // Jenis pesan
+	Sequence  uint64      // Nomor blok yang sedang disepakati
+	Round     uint64      // Ronde di dalam nomor blok tersebut
+	Digest    common.Hash // Hash usulan blok (kosong untuk ROUND-CHANGE)
+	Payload   []byte      // Blok untuk PRE-PREPARE atau commit seal untuk COMMIT
+	Signature []byte      // Signature pengirim atas semua field di atas
+
+	sender common.Address // Pengirim yang dipulihkan dari signature
+}
+
+// signingBytes mengembalikan byte RLP yang ditandatangani oleh pengirim pesan.
+func (m *message) signingBytes() []byte {
+	enc, err := rlp.EncodeToBytes([]interface{}{m.Code, m.Sequence, m.Round, m.Digest, m.Payload})
+	if err != nil {
+		panic("can't encode: " + err.Error())
+	}
+	return enc
+}
+
+// decodeMessage mengurai pesan dari jaringan dan memulihkan alamat pengirimnya.
+func decodeMessage(payload []byte) (*message, error) {
+	msg := new(message)
+	if err := rlp.DecodeBytes(payload, msg); err != nil {
+		return nil, err
+	}
+	pubkey, err := crypto.SigToPub(crypto.Keccak256(msg.signingBytes()), msg.Signature)
+	if err != nil {
+		return nil, errInvalidSignature
+	}
+	msg.sender = crypto.PubkeyToAddress(*pubkey)
+	return msg, nil
+}
+
+// committedSealData mengembalikan data yang ditandatangani validator sebagai commit
+// seal untuk usulan dengan hash tertentu.
+func committedSealData(digest common.Hash) []byte {
+	return append(digest.Bytes(), byte(msgCommit))
+}
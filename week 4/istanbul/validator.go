@@ -0,0 +1,76 @@
+package istanbul
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// validatorSet adalah kumpulan validator istanbul sesuai urutan di extra-data
+// header. Urutan ini dipakai untuk rotasi pengusul.
+type validatorSet struct {
+	validators []common.Address
+}
+
+// newValidatorSet membuat kumpulan validator dari daftar alamat yang diberikan.
+func newValidatorSet(validators []common.Address) *validatorSet {
+	set := &validatorSet{validators: make([]common.Address, len(validators))}
+	copy(set.validators, validators)
+	return set
+}
+
+// size mengembalikan jumlah validator di dalam kumpulan.
+func (s *validatorSet) size() int {
+	return len(s.validators)
+}
+
+// index mengembalikan posisi validator di dalam kumpulan, atau -1 jika alamat
+// tersebut bukan validator.
+func (s *validatorSet) index(address common.Address) int {
+	for i, v := range s.validators {
+		if v == address {
+			return i
+		}
+	}
+	return -1
+}
+
+// contains mengembalikan apakah alamat yang diberikan adalah validator.
+func (s *validatorSet) contains(address common.Address) bool {
+	return s.index(address) >= 0
+}
+
+// f mengembalikan jumlah maksimum validator bizantium yang masih bisa ditoleransi.
+func (s *validatorSet) f() int {
+	return (s.size() - 1) / 3
+}
+
+// quorum mengembalikan jumlah suara yang dibutuhkan untuk mencapai konsensus, yaitu
+// ceil(2n/3). Untuk n = 3f+1 nilainya sama dengan 2f+1, tetapi untuk ukuran lain
+// 2f+1 terlalu kecil sehingga dua quorum bisa tidak beririsan di validator jujur.
+func (s *validatorSet) quorum() int {
+	return (2*s.size() + 2) / 3
+}
+
+// proposer mengembalikan pengusul untuk ronde tertentu. Pengusul dirotasi secara
+// round-robin mulai dari validator setelah pengusul blok sebelumnya, dan bergeser
+// satu posisi setiap kali ronde berganti.
+func (s *validatorSet) proposer(last common.Address, round uint64) common.Address {
+	if s.size() == 0 {
+		return common.Address{}
+	}
+	offset := uint64(s.index(last) + 1)
+	return s.validators[(offset+round)%uint64(s.size())]
+}
+
+// equal mengembalikan apakah daftar validator yang diberikan sama persis dengan
+// kumpulan ini, termasuk urutannya.
+func (s *validatorSet) equal(validators []common.Address) bool {
+	if len(validators) != len(s.validators) {
+		return false
+	}
+	for i, v := range validators {
+		if s.validators[i] != v {
+			return false
+		}
+	}
+	return true
+}
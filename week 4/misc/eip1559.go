@@ -0,0 +1,73 @@
+package misc
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// VerifyEip1559Header memverifikasi beberapa atribut header yang diubah oleh EIP-1559:
+// batas gas elastis dan base fee yang diturunkan dari header induk.
+func VerifyEip1559Header(config *params.ChainConfig, parent, header *types.Header) error {
+	// Pada blok London pertama, batas gas induk dikalikan dengan elastisitas
+	parentGasLimit := parent.GasLimit
+	if !config.IsLondon(parent.Number) {
+		parentGasLimit = parent.GasLimit * params.ElasticityMultiplier
+	}
+	if err := VerifyGaslimit(parentGasLimit, header.GasLimit); err != nil {
+		return err
+	}
+	// Pastikan header tidak rusak
+	if header.BaseFee == nil {
+		return fmt.Errorf("header is missing baseFee")
+	}
+	// Base fee harus sama dengan yang dihitung dari header induk
+	expectedBaseFee := CalcBaseFee(config, parent)
+	if header.BaseFee.Cmp(expectedBaseFee) != 0 {
+		return fmt.Errorf("invalid baseFee: have %s, want %s, parentBaseFee %s, parentGasUsed %d",
+			header.BaseFee, expectedBaseFee, parent.BaseFee, parent.GasUsed)
+	}
+	return nil
+}
+
+// CalcBaseFee menghitung base fee dari header anak berdasarkan header induknya.
+func CalcBaseFee(config *params.ChainConfig, parent *types.Header) *big.Int {
+	// Blok EIP-1559 pertama memakai InitialBaseFee
+	if !config.IsLondon(parent.Number) {
+		return new(big.Int).SetUint64(params.InitialBaseFee)
+	}
+	parentGasTarget := parent.GasLimit / params.ElasticityMultiplier
+
+	// Jika gas yang dipakai induk sama dengan target, base fee tidak berubah
+	if parent.GasUsed == parentGasTarget {
+		return new(big.Int).Set(parent.BaseFee)
+	}
+	var (
+		num   = new(big.Int)
+		denom = new(big.Int)
+	)
+	if parent.GasUsed > parentGasTarget {
+		// Induk memakai lebih banyak gas dari target, base fee naik:
+		// max(1, parentBaseFee * gasUsedDelta / parentGasTarget / baseFeeChangeDenominator)
+		num.SetUint64(parent.GasUsed - parentGasTarget)
+		num.Mul(num, parent.BaseFee)
+		num.Div(num, denom.SetUint64(parentGasTarget))
+		num.Div(num, denom.SetUint64(params.BaseFeeChangeDenominator))
+		baseFeeDelta := math.BigMax(num, common.Big1)
+
+		return num.Add(parent.BaseFee, baseFeeDelta)
+	}
+	// Induk memakai lebih sedikit gas dari target, base fee turun:
+	// max(0, parentBaseFee * gasUsedDelta / parentGasTarget / baseFeeChangeDenominator)
+	num.SetUint64(parentGasTarget - parent.GasUsed)
+	num.Mul(num, parent.BaseFee)
+	num.Div(num, denom.SetUint64(parentGasTarget))
+	num.Div(num, denom.SetUint64(params.BaseFeeChangeDenominator))
+	baseFee := num.Sub(parent.BaseFee, num)
+
+	return math.BigMax(baseFee, common.Big0)
+}
@@ -0,0 +1,27 @@
+// Paket misc berisi aturan konsensus kecil yang dipakai bersama oleh beberapa mesin konsensus.
+package misc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// VerifyGaslimit memverifikasi bahwa batas gas header tetap berada dalam batas yang diizinkan
+// dibandingkan dengan batas gas induknya.
+func VerifyGaslimit(parentGasLimit, headerGasLimit uint64) error {
+	// Selisih batas gas tidak boleh melebihi parentGasLimit / GasLimitBoundDivisor
+	diff := int64(parentGasLimit) - int64(headerGasLimit)
+	if diff < 0 {
+		diff *= -1
+	}
+	limit := parentGasLimit / params.GasLimitBoundDivisor
+	if uint64(diff) >= limit {
+		return fmt.Errorf("invalid gas limit: have %d, want %d +-= %d", headerGasLimit, parentGasLimit, limit-1)
+	}
+	if headerGasLimit < params.MinGasLimit {
+		return errors.New("invalid gas limit below 5000")
+	}
+	return nil
+}
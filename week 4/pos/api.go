@@ -0,0 +1,210 @@
+package pos
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxDuties adalah jumlah maksimum giliran yang dikembalikan satu panggilan RPC.
+const maxDuties = 1024
+
+// Duty adalah satu giliran pengusul yang akan datang.
+type Duty struct {
+	Number uint64 `json:"number"` // Nomor blok yang menjadi giliran validator
+	Time   uint64 `json:"time"`   // Stempel waktu paling awal untuk blok tersebut
+}
+
+// API adalah API RPC untuk memeriksa kumpulan validator dan jadwal pengusul dari
+// skema proof-of-stake.
+type API struct {
+	chain consensus.ChainHeaderReader
+	pos   *PoS
+}
+
+// header mengambil header untuk nomor blok yang diminta (atau header saat ini
+// jika tidak ada yang diminta).
+func (api *API) header(number *rpc.BlockNumber) *types.Header {
+	if number == nil || *number == rpc.LatestBlockNumber {
+		return api.chain.CurrentHeader()
+	}
+	return api.chain.GetHeaderByNumber(uint64(number.Int64()))
+}
+
+// GetSnapshot mengambil kumpulan validator yang berlaku untuk anak dari blok tertentu.
+func (api *API) GetSnapshot(number *rpc.BlockNumber) (*Snapshot, error) {
+	header := api.header(number)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return api.pos.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+}
+
+// GetValidators mengambil daftar validator aktif beserta stake-nya pada blok tertentu.
+func (api *API) GetValidators(number *rpc.BlockNumber) ([]Validator, error) {
+	snap, err := api.GetSnapshot(number)
+	if err != nil {
+		return nil, err
+	}
+	return snap.Validators, nil
+}
+
+// GetProposer mengambil validator yang terpilih untuk mengusulkan blok berikutnya
+// setelah blok tertentu.
+func (api *API) GetProposer(number *rpc.BlockNumber) (common.Address, error) {
+	header := api.header(number)
+	if header == nil {
+		return common.Address{}, errUnknownBlock
+	}
+	snap, err := api.pos.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return snap.proposer(header.Number.Uint64() + 1), nil
+}
+
+// GetDuties mengembalikan giliran validator sebagai pengusul terpilih mulai dari
+// blok setelah head sampai checkpoint berikutnya, agar operator bisa menjadwalkan
+// pemeliharaan di luar gilirannya. Giliran sesudah checkpoint tidak bisa dihitung
+// sebelumnya karena pemilihan pengusul memakai hash checkpoint tersebut sebagai
+// seed. Stempel waktunya mengasumsikan setiap blok sebelumnya disegel pengusul
+// terpilih tepat waktu. Paling banyak 1024 giliran dikembalikan.
+func (api *API) GetDuties(validator common.Address) ([]Duty, error) {
+	header := api.chain.CurrentHeader()
+	snap, err := api.pos.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if snap.stake(validator) == nil {
+		return nil, errUnauthorizedValidator
+	}
+	var (
+		head  = header.Number.Uint64()
+		epoch = api.pos.config.Epoch
+		end   = (head/epoch + 1) * epoch // Checkpoint berikutnya masih diverifikasi dengan kumpulan ini
+
+		duties []Duty
+	)
+	for number := head + 1; number <= end && len(duties) < maxDuties; number++ {
+		if snap.proposer(number) == validator {
+			duties = append(duties, Duty{Number: number, Time: header.Time + (number-head)*api.pos.config.Period})
+		}
+	}
+	return duties, nil
+}
+
+// GetPerformance mengambil catatan kinerja validator pada blok tertentu: jumlah
+// giliran yang terlewat sejak checkpoint terakhir dan status penjaranya.
+func (api *API) GetPerformance(validator common.Address, number *rpc.BlockNumber) (*Performance, error) {
+	header := api.header(number)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	statedb, err := state.New(header.Root, api.pos.statedb, nil)
+	if err != nil {
+		return nil, err
+	}
+	return readPerformance(statedb, api.pos.config.Registry, validator), nil
+}
+
+// GetDeposits mengambil jumlah deposit yang sudah dimasukkan ke registry dan yang
+// tercatat di kontrak deposit pada blok tertentu. Selisihnya adalah deposit yang
+// menunggu batas epoch berikutnya.
+func (api *API) GetDeposits(number *rpc.BlockNumber) (*DepositStatus, error) {
+	if api.pos.config.DepositContract == (common.Address{}) {
+		return nil, errNoDepositContract
+	}
+	header := api.header(number)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	statedb, err := state.New(header.Root, api.pos.statedb, nil)
+	if err != nil {
+		return nil, err
+	}
+	return readDepositStatus(statedb, api.pos.config.Registry, api.pos.config.DepositContract), nil
+}
+
+// GetWithdrawalQueue mengambil penarikan yang menunggu di antrean pada blok tertentu,
+// sesuai urutan pengkreditannya. Paling banyak 1024 penarikan dikembalikan.
+func (api *API) GetWithdrawalQueue(number *rpc.BlockNumber) ([]*Withdrawal, error) {
+	header := api.header(number)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	statedb, err := state.New(header.Root, api.pos.statedb, nil)
+	if err != nil {
+		return nil, err
+	}
+	head, tail := readWithdrawalQueue(statedb, api.pos.config.Registry)
+
+	var withdrawals []*Withdrawal
+	for index := head; index < tail && len(withdrawals) < maxWithdrawals; index++ {
+		withdrawal := readWithdrawal(statedb, api.pos.config.Registry, index)
+		withdrawal.Position = index - head
+		withdrawals = append(withdrawals, withdrawal)
+	}
+	return withdrawals, nil
+}
+
+// GetWithdrawal mengambil penarikan pertama milik validator yang menunggu di antrean
+// pada blok tertentu, beserta posisinya di antrean.
+func (api *API) GetWithdrawal(validator common.Address, number *rpc.BlockNumber) (*Withdrawal, error) {
+	header := api.header(number)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	statedb, err := state.New(header.Root, api.pos.statedb, nil)
+	if err != nil {
+		return nil, err
+	}
+	head, tail := readWithdrawalQueue(statedb, api.pos.config.Registry)
+	for index := head; index < tail; index++ {
+		if withdrawal := readWithdrawal(statedb, api.pos.config.Registry, index); withdrawal.Validator == validator {
+			withdrawal.Position = index - head
+			return withdrawal, nil
+		}
+	}
+	return nil, errNoWithdrawal
+}
+
+// GetSyncCommittee mengambil sync committee yang menandatangani anak dari blok tertentu.
+func (api *API) GetSyncCommittee(number *rpc.BlockNumber) (*SyncCommittee, error) {
+	snap, err := api.GetSnapshot(number)
+	if err != nil {
+		return nil, err
+	}
+	return snap.syncCommittee(api.pos.config.SyncCommitteeSize), nil
+}
+
+// SignSyncCommittee menandatangani blok dengan hash tertentu sebagai anggota sync
+// committee memakai kunci validator lokal. Signature yang dihasilkan perlu diteruskan
+// ke node lain lewat SubmitSyncSignature.
+func (api *API) SignSyncCommittee(hash common.Hash) (*SyncSignature, error) {
+	header := api.chain.GetHeaderByHash(hash)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return api.pos.SignSyncCommittee(api.chain, header)
+}
+
+// SubmitSyncSignature menerima signature sync committee dari node lain.
+func (api *API) SubmitSyncSignature(sig SyncSignature) error {
+	header := api.chain.GetHeaderByHash(sig.Hash)
+	if header == nil {
+		return errUnknownBlock
+	}
+	return api.pos.SubmitSyncSignature(api.chain, header, &sig)
+}
+
+// GetLightClientUpdate mengambil update light client untuk blok tertentu dari
+// signature sync committee yang sudah terkumpul.
+func (api *API) GetLightClientUpdate(number *rpc.BlockNumber) (*LightClientUpdate, error) {
+	header := api.header(number)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return api.pos.LightClientUpdate(api.chain, header)
+}
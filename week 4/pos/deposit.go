@@ -0,0 +1,128 @@
+package pos
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// maxEpochDeposits adalah jumlah maksimum deposit yang dimasukkan ke registry di
+// setiap batas epoch. Sisanya diproses di epoch berikutnya, karena panjang daftar
+// deposit berasal dari storage dan tidak boleh membuat Finalize berjalan tanpa batas.
+const maxEpochDeposits = 1024
+
+// Tata letak storage kontrak deposit. Tata letak ini sama dengan kontrak Solidity
+// berikut; dana deposit tetap terkunci di kontrak, dan mesin konsensus hanya membaca
+// daftar deposit untuk menambah stake di registry:
+//
+//	contract DepositContract {
+//	    struct Deposit { address validator; uint256 amount; }
+//	    Deposit[] deposits;                       // slot 0
+//
+//	    function deposit(address validator) external payable {
+//	        deposits.push(Deposit(validator, msg.value));
+//	    }
+//	}
+var depositListSlot = common.Hash{} // Slot panjang array deposit
+
+// Deposit adalah satu deposit validator yang tercatat di kontrak deposit.
+type Deposit struct {
+	Index     uint64         `json:"index"`     // Posisi deposit di kontrak
+	Validator common.Address `json:"validator"` // Validator yang menerima tambahan stake
+	Amount    *big.Int       `json:"amount"`    // Jumlah stake yang ditambahkan
+}
+
+// DepositStatus adalah posisi pemrosesan deposit pada sebuah blok.
+type DepositStatus struct {
+	Processed uint64 `json:"processed"` // Jumlah deposit yang sudah dimasukkan ke registry
+	Total     uint64 `json:"total"`     // Jumlah deposit yang tercatat di kontrak deposit
+}
+
+// readDeposit membaca deposit dengan posisi tertentu dari kontrak deposit di state.
+func readDeposit(statedb *state.StateDB, contract common.Address, index uint64) Deposit {
+	return Deposit{
+		Index:     index,
+		Validator: common.BytesToAddress(statedb.GetState(contract, arraySlot(depositListSlot, index, 2, 0)).Bytes()),
+		Amount:    statedb.GetState(contract, arraySlot(depositListSlot, index, 2, 1)).Big(),
+	}
+}
+
+// readDepositStatus membaca jumlah deposit yang sudah diproses dari registry dan
+// jumlah deposit yang tercatat di kontrak deposit.
+func readDepositStatus(statedb *state.StateDB, registry, contract common.Address) *DepositStatus {
+	return &DepositStatus{
+		Processed: statedb.GetState(registry, depositsSlot).Big().Uint64(),
+		Total:     statedb.GetState(contract, depositListSlot).Big().Uint64(),
+	}
+}
+
+// processDeposits memasukkan deposit baru dari kontrak deposit ke registry pada blok
+// terakhir setiap epoch, sehingga checkpoint berikutnya langsung memuat stake
+// hasil deposit tersebut. Jumlah deposit yang sudah diproses disimpan di storage
+// registry, jadi ikut berpindah bersama state saat reorg dan tidak pernah diproses
+// dua kali.
+//
+// Kontrak deposit harus berada di rantai ini. Deposit di rantai lain perlu direlai
+// ke kontrak di rantai ini terlebih dahulu: Finalize harus menghasilkan state yang
+// sama di semua node, sedangkan pandangan setiap node atas rantai lain lewat RPC
+// bisa berbeda atau tidak tersedia.
+func (p *PoS) processDeposits(header *types.Header, statedb *state.StateDB) {
+	contract := p.config.DepositContract
+	if contract == (common.Address{}) || (header.Number.Uint64()+1)%p.config.Epoch != 0 {
+		return
+	}
+	registry := p.config.Registry
+	status := readDepositStatus(statedb, registry, contract)
+	if status.Total <= status.Processed {
+		return
+	}
+	end := status.Total
+	if end-status.Processed > maxEpochDeposits {
+		end = status.Processed + maxEpochDeposits
+	}
+	for index := status.Processed; index < end; index++ {
+		deposit := readDeposit(statedb, contract, index)
+		if deposit.Amount.Sign() == 0 {
+			continue
+		}
+		slot := mappingSlot(deposit.Validator, stakesSlot)
+		stake := new(big.Int).Add(statedb.GetState(registry, slot).Big(), deposit.Amount)
+		statedb.SetState(registry, slot, common.BigToHash(stake))
+
+		if !addValidator(statedb, registry, deposit.Validator, p.config.MaxValidators) {
+			log.Warn("Validator registry full, deposit credited without registration", "validator", deposit.Validator, "index", index)
+		}
+	}
+	statedb.SetState(registry, depositsSlot, common.BigToHash(new(big.Int).SetUint64(end)))
+	log.Info("Processed validator deposits", "number", header.Number, "from", status.Processed, "to", end)
+}
+
+// addValidator menambahkan alamat ke array validator di registry jika belum ada di
+// antara limit entri pertama, yaitu entri yang dibaca readValidators. Metode ini
+// mengembalikan false jika array sudah berisi limit entri atau lebih.
+func addValidator(statedb *state.StateDB, registry, address common.Address, limit int) bool {
+	count := statedb.GetState(registry, validatorsSlot).Big()
+	if !count.IsUint64() || count.Uint64() >= uint64(limit) {
+		return validatorIndex(statedb, registry, address, uint64(limit)) >= 0
+	}
+	if validatorIndex(statedb, registry, address, count.Uint64()) >= 0 {
+		return true
+	}
+	statedb.SetState(registry, arraySlot(validatorsSlot, count.Uint64(), 1, 0), common.BytesToHash(address[:]))
+	statedb.SetState(registry, validatorsSlot, common.BigToHash(count.Add(count, common.Big1)))
+	return true
+}
+
+// validatorIndex mengembalikan posisi alamat di antara count entri pertama array
+// validator di registry, atau -1 jika tidak ditemukan.
+func validatorIndex(statedb *state.StateDB, registry, address common.Address, count uint64) int {
+	for i := uint64(0); i < count; i++ {
+		if common.BytesToAddress(statedb.GetState(registry, arraySlot(validatorsSlot, i, 1, 0)).Bytes()) == address {
+			return int(i)
+		}
+	}
+	return -1
+}
@@ -0,0 +1,123 @@
+package pos
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// pushDeposit menambahkan deposit ke storage kontrak deposit, seperti fungsi
+// deposit() di kontrak.
+func pushDeposit(statedb *state.StateDB, contract, validator common.Address, amount int64) {
+	count := statedb.GetState(contract, depositListSlot).Big().Uint64()
+	statedb.SetCode(contract, []byte{0x00})
+	statedb.SetState(contract, arraySlot(depositListSlot, count, 2, 0), common.BytesToHash(validator[:]))
+	statedb.SetState(contract, arraySlot(depositListSlot, count, 2, 1), common.BigToHash(big.NewInt(amount)))
+	statedb.SetState(contract, depositListSlot, common.BigToHash(new(big.Int).SetUint64(count+1)))
+}
+
+// Menguji bahwa deposit hanya dimasukkan ke registry pada blok terakhir epoch,
+// validator baru didaftarkan sekali, dan deposit yang sudah diproses tidak
+// diproses ulang di epoch berikutnya.
+func TestDeposits(t *testing.T) {
+	contract := common.Address{0xde}
+	tt := newTester(t, &Config{Period: 1, Epoch: 4, DepositContract: contract}, 100, 100)
+	engine := tt.engine()
+	headers := tt.extend(engine, 8, nil)
+
+	sdb := state.NewDatabase(tt.db)
+	statedb, _ := state.New(tt.root, sdb, nil)
+	finalize := func(from, to uint64) {
+		for number := from; number <= to; number++ {
+			engine.Finalize(tt.chain, types.CopyHeader(headers[number-1]), statedb, nil, nil)
+		}
+	}
+	stake := func(validator common.Address) int64 {
+		return statedb.GetState(tt.config.Registry, mappingSlot(validator, stakesSlot)).Big().Int64()
+	}
+	newcomer := common.Address{0xaa}
+	pushDeposit(statedb, contract, newcomer, 50)
+	pushDeposit(statedb, contract, tt.Addrs[0], 20)
+	pushDeposit(statedb, contract, newcomer, 0)
+
+	finalize(1, 2)
+	if have := readDepositStatus(statedb, tt.config.Registry, contract); have.Processed != 0 || have.Total != 3 {
+		t.Fatalf("deposits processed before epoch end: have %+v", have)
+	}
+	finalize(3, 3)
+	if have := readDepositStatus(statedb, tt.config.Registry, contract); have.Processed != 3 {
+		t.Fatalf("processed deposits mismatch: have %d, want %d", have.Processed, 3)
+	}
+	if have := stake(newcomer); have != 50 {
+		t.Errorf("newcomer stake mismatch: have %d, want %d", have, 50)
+	}
+	if have := stake(tt.Addrs[0]); have != 120 {
+		t.Errorf("top-up stake mismatch: have %d, want %d", have, 120)
+	}
+	if have := statedb.GetState(tt.config.Registry, validatorsSlot).Big().Uint64(); have != 3 {
+		t.Errorf("registry length mismatch: have %d, want %d", have, 3)
+	}
+	if validators := readValidators(statedb, tt.config.Registry, nil, 16); len(validators) != 3 {
+		t.Errorf("validator count mismatch: have %d, want %d", len(validators), 3)
+	}
+	// Deposit berikutnya menunggu akhir epoch kedua, dan deposit lama tidak diulang
+	pushDeposit(statedb, contract, newcomer, 25)
+	finalize(4, 6)
+	if have := stake(newcomer); have != 50 {
+		t.Errorf("deposit credited before epoch end: have %d, want %d", have, 50)
+	}
+	finalize(7, 7)
+	if have := stake(newcomer); have != 75 {
+		t.Errorf("second deposit stake mismatch: have %d, want %d", have, 75)
+	}
+	if have := stake(tt.Addrs[0]); have != 120 {
+		t.Errorf("deposit processed twice: have %d, want %d", have, 120)
+	}
+	// Status deposit terlihat lewat RPC setelah state disimpan
+	root, err := statedb.Commit(true)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	if err := sdb.TrieDB().Commit(root, false, nil); err != nil {
+		t.Fatalf("failed to flush state: %v", err)
+	}
+	tt.root = root
+	tt.extend(engine, 1, nil)
+
+	api := &API{chain: tt.chain, pos: engine}
+	status, err := api.GetDeposits(nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve deposits: %v", err)
+	}
+	if status.Processed != 4 || status.Total != 4 {
+		t.Errorf("deposit status mismatch: have %+v, want 4/4", status)
+	}
+	disabled := &API{chain: tt.chain, pos: New(&Config{Period: 1, Epoch: 4}, tt.db)}
+	if _, err := disabled.GetDeposits(nil); err != errNoDepositContract {
+		t.Errorf("error mismatch: have %v, want %v", err, errNoDepositContract)
+	}
+}
+
+// Menguji bahwa deposit untuk validator baru tetap menambah stake ketika registry
+// sudah penuh, tetapi validator tersebut tidak ditambahkan ke array.
+func TestDepositRegistryFull(t *testing.T) {
+	contract := common.Address{0xde}
+	tt := newTester(t, &Config{Period: 1, Epoch: 2, MaxValidators: 2, DepositContract: contract}, 100, 100)
+	engine := tt.engine()
+	headers := tt.extend(engine, 1, nil)
+
+	statedb, _ := state.New(tt.root, state.NewDatabase(tt.db), nil)
+	newcomer := common.Address{0xaa}
+	pushDeposit(statedb, contract, newcomer, 50)
+	engine.Finalize(tt.chain, types.CopyHeader(headers[0]), statedb, nil, nil)
+
+	if have := statedb.GetState(tt.config.Registry, mappingSlot(newcomer, stakesSlot)).Big().Int64(); have != 50 {
+		t.Errorf("stake mismatch: have %d, want %d", have, 50)
+	}
+	if have := statedb.GetState(tt.config.Registry, validatorsSlot).Big().Uint64(); have != 2 {
+		t.Errorf("registry length mismatch: have %d, want %d", have, 2)
+	}
+}
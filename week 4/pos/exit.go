@@ -0,0 +1,137 @@
+package pos
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// defaultWithdrawalsPerBlock adalah jumlah default penarikan yang dikreditkan di
+// setiap blok jika WithdrawalsPerBlock tidak diisi.
+const defaultWithdrawalsPerBlock = 16
+
+// maxWithdrawals adalah jumlah maksimum penarikan yang dikembalikan satu panggilan RPC.
+const maxWithdrawals = 1024
+
+// ExitData adalah calldata transaksi exit sukarela, yaitu selector dari fungsi exit()
+// di kontrak registry. Validator yang mengirim transaksi dengan calldata ini ke
+// registry kehilangan seluruh stake-nya di registry, keluar dari kumpulan validator
+// pada checkpoint berikutnya, dan stake tersebut masuk ke antrean penarikan. Seperti
+// unjail, hanya pengirim, tujuan dan calldata transaksi yang dilihat.
+var ExitData = crypto.Keccak256([]byte("exit()"))[:4]
+
+// Withdrawal adalah satu penarikan stake di antrean registry.
+type Withdrawal struct {
+	Validator common.Address `json:"validator"` // Validator yang keluar dan menerima saldo
+	Amount    *big.Int       `json:"amount"`    // Jumlah stake yang dikreditkan ke saldo
+	Release   uint64         `json:"release"`   // Blok paling awal penarikan boleh dikreditkan
+	Position  uint64         `json:"position"`  // Jumlah penarikan di depannya dalam antrean
+}
+
+// readWithdrawal membaca penarikan dengan posisi tertentu di array antrean registry.
+func readWithdrawal(statedb *state.StateDB, registry common.Address, index uint64) *Withdrawal {
+	return &Withdrawal{
+		Validator: common.BytesToAddress(statedb.GetState(registry, arraySlot(withdrawalsSlot, index, 3, 0)).Bytes()),
+		Amount:    statedb.GetState(registry, arraySlot(withdrawalsSlot, index, 3, 1)).Big(),
+		Release:   statedb.GetState(registry, arraySlot(withdrawalsSlot, index, 3, 2)).Big().Uint64(),
+	}
+}
+
+// readWithdrawalQueue membaca posisi awal dan akhir antrean penarikan dari registry.
+func readWithdrawalQueue(statedb *state.StateDB, registry common.Address) (head, tail uint64) {
+	return statedb.GetState(registry, headSlot).Big().Uint64(), statedb.GetState(registry, withdrawalsSlot).Big().Uint64()
+}
+
+// processExits memproses transaksi exit di blok yang sedang difinalisasi. Stake
+// pengirim dihapus dari registry dan alamatnya dikeluarkan dari array validator,
+// sehingga checkpoint berikutnya tidak lagi memuatnya. Sampai checkpoint itu,
+// validator masih menyegel blok dengan kumpulan lama, jadi penarikannya baru
+// dikreditkan satu epoch penuh setelah ia keluar. Status penjara tidak dihapus agar
+// exit dan deposit ulang tidak bisa dipakai untuk keluar dari penjara.
+func (p *PoS) processExits(chain consensus.ChainHeaderReader, header *types.Header, statedb *state.StateDB, txs []*types.Transaction) {
+	var (
+		registry = p.config.Registry
+		number   = header.Number.Uint64()
+		release  = (number/p.config.Epoch + 2) * p.config.Epoch
+		signer   = types.MakeSigner(chain.Config(), header.Number)
+	)
+	for _, tx := range txs {
+		if tx.To() == nil || *tx.To() != registry || !bytes.Equal(tx.Data(), ExitData) {
+			continue
+		}
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			continue
+		}
+		slot := mappingSlot(from, stakesSlot)
+		stake := statedb.GetState(registry, slot).Big()
+		if stake.Sign() == 0 {
+			continue
+		}
+		statedb.SetState(registry, slot, common.Hash{})
+		removeValidator(statedb, registry, from, p.config.MaxValidators)
+
+		_, tail := readWithdrawalQueue(statedb, registry)
+		statedb.SetState(registry, arraySlot(withdrawalsSlot, tail, 3, 0), common.BytesToHash(from[:]))
+		statedb.SetState(registry, arraySlot(withdrawalsSlot, tail, 3, 1), common.BigToHash(stake))
+		statedb.SetState(registry, arraySlot(withdrawalsSlot, tail, 3, 2), common.BigToHash(new(big.Int).SetUint64(release)))
+		statedb.SetState(registry, withdrawalsSlot, common.BigToHash(new(big.Int).SetUint64(tail+1)))
+		log.Info("Queued validator exit", "validator", from, "number", number, "stake", stake, "release", release)
+	}
+}
+
+// processWithdrawals mengkreditkan penarikan dari depan antrean ke saldo validator.
+// Paling banyak WithdrawalsPerBlock penarikan dikreditkan di setiap blok, dan
+// antrean berhenti di penarikan pertama yang belum boleh dikreditkan. Penarikan
+// yang masuk belakangan selalu memiliki blok rilis yang sama atau lebih akhir, jadi
+// urutan antrean tetap terjaga.
+func (p *PoS) processWithdrawals(header *types.Header, statedb *state.StateDB) {
+	registry := p.config.Registry
+	head, tail := readWithdrawalQueue(statedb, registry)
+	if head >= tail {
+		return
+	}
+	number := header.Number.Uint64()
+	start := head
+	for ; head < tail && head-start < uint64(p.config.WithdrawalsPerBlock); head++ {
+		withdrawal := readWithdrawal(statedb, registry, head)
+		if withdrawal.Release > number {
+			break
+		}
+		statedb.AddBalance(withdrawal.Validator, withdrawal.Amount)
+		for field := uint64(0); field < 3; field++ {
+			statedb.SetState(registry, arraySlot(withdrawalsSlot, head, 3, field), common.Hash{})
+		}
+		log.Debug("Credited validator withdrawal", "validator", withdrawal.Validator, "amount", withdrawal.Amount, "number", number)
+	}
+	if head != start {
+		statedb.SetState(registry, headSlot, common.BigToHash(new(big.Int).SetUint64(head)))
+	}
+}
+
+// removeValidator menghapus alamat dari antara limit entri pertama array validator
+// di registry dengan menukarnya dengan entri terakhir, lalu memendekkan array.
+func removeValidator(statedb *state.StateDB, registry, address common.Address, limit int) {
+	count := statedb.GetState(registry, validatorsSlot).Big()
+	if !count.IsUint64() || count.Sign() == 0 {
+		return
+	}
+	scan := count.Uint64()
+	if scan > uint64(limit) {
+		scan = uint64(limit)
+	}
+	index := validatorIndex(statedb, registry, address, scan)
+	if index < 0 {
+		return
+	}
+	last := arraySlot(validatorsSlot, count.Uint64()-1, 1, 0)
+	statedb.SetState(registry, arraySlot(validatorsSlot, uint64(index), 1, 0), statedb.GetState(registry, last))
+	statedb.SetState(registry, last, common.Hash{})
+	statedb.SetState(registry, validatorsSlot, common.BigToHash(count.Sub(count, common.Big1)))
+}
@@ -0,0 +1,138 @@
+package pos
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Menguji bahwa exit menghapus stake dan validator dari registry, penarikannya
+// baru dikreditkan satu epoch setelah validator keluar dari kumpulan, antrean
+// dibatasi WithdrawalsPerBlock, dan posisi antrean terlihat lewat RPC.
+func TestExits(t *testing.T) {
+	tt := newTester(t, &Config{Period: 1, Epoch: 4, WithdrawalsPerBlock: 1}, 100, 200, 300)
+	engine := tt.engine()
+	headers := tt.extend(engine, 10, nil)
+
+	sdb := state.NewDatabase(tt.db)
+	statedb, _ := state.New(tt.root, sdb, nil)
+	finalize := func(from, to uint64, txs ...*types.Transaction) {
+		for number := from; number <= to; number++ {
+			engine.Finalize(tt.chain, types.CopyHeader(headers[number-1]), statedb, txs, nil)
+		}
+	}
+	exit := func(validator common.Address, nonce uint64) *types.Transaction {
+		signer := types.MakeSigner(tt.chain.Config(), big.NewInt(2))
+		tx, err := types.SignTx(types.NewTransaction(nonce, tt.config.Registry, nil, 50000, big.NewInt(1), ExitData), signer, tt.Keys[validator])
+		if err != nil {
+			t.Fatalf("failed to sign exit transaction: %v", err)
+		}
+		return tx
+	}
+	balance := func(validator common.Address) int64 {
+		return statedb.GetBalance(validator).Int64()
+	}
+	// Dua validator keluar di blok yang sama, exit ulang tanpa stake diabaikan
+	finalize(1, 1)
+	finalize(2, 2, exit(tt.Addrs[0], 0), exit(tt.Addrs[1], 0))
+	finalize(3, 3, exit(tt.Addrs[0], 1))
+
+	validators := readValidators(statedb, tt.config.Registry, nil, 16)
+	if len(validators) != 1 || validators[0].Address != tt.Addrs[2] {
+		t.Fatalf("registry still lists exited validators: %v", validators)
+	}
+	if have := statedb.GetState(tt.config.Registry, validatorsSlot).Big().Uint64(); have != 1 {
+		t.Errorf("registry length mismatch: have %d, want %d", have, 1)
+	}
+	if head, tail := readWithdrawalQueue(statedb, tt.config.Registry); head != 0 || tail != 2 {
+		t.Fatalf("queue mismatch: have %d/%d, want %d/%d", head, tail, 0, 2)
+	}
+	finalize(4, 7)
+	if have := balance(tt.Addrs[0]); have != 0 {
+		t.Errorf("withdrawal credited before release: have %d, want %d", have, 0)
+	}
+	// Batas satu penarikan per blok menunda validator kedua satu blok
+	finalize(8, 8)
+	if have := balance(tt.Addrs[0]); have != 100 {
+		t.Errorf("first withdrawal mismatch: have %d, want %d", have, 100)
+	}
+	if have := balance(tt.Addrs[1]); have != 0 {
+		t.Errorf("withdrawal rate limit exceeded: have %d, want %d", have, 0)
+	}
+	root, err := statedb.Commit(true)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	if err := sdb.TrieDB().Commit(root, false, nil); err != nil {
+		t.Fatalf("failed to flush state: %v", err)
+	}
+	tt.root = root
+	tt.extend(engine, 1, nil)
+
+	api := &API{chain: tt.chain, pos: engine}
+	queue, err := api.GetWithdrawalQueue(nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve withdrawal queue: %v", err)
+	}
+	if len(queue) != 1 || queue[0].Validator != tt.Addrs[1] || queue[0].Position != 0 || queue[0].Release != 8 {
+		t.Errorf("withdrawal queue mismatch: have %+v", queue)
+	}
+	if withdrawal, err := api.GetWithdrawal(tt.Addrs[1], nil); err != nil || withdrawal.Amount.Int64() != 200 {
+		t.Errorf("pending withdrawal mismatch: have %+v, %v", withdrawal, err)
+	}
+	if _, err := api.GetWithdrawal(tt.Addrs[0], nil); err != errNoWithdrawal {
+		t.Errorf("error mismatch: have %v, want %v", err, errNoWithdrawal)
+	}
+	statedb, _ = state.New(root, sdb, nil)
+	finalize(9, 9)
+	if have := balance(tt.Addrs[1]); have != 200 {
+		t.Errorf("second withdrawal mismatch: have %d, want %d", have, 200)
+	}
+	if head, tail := readWithdrawalQueue(statedb, tt.config.Registry); head != 2 || tail != 2 {
+		t.Errorf("queue mismatch: have %d/%d, want %d/%d", head, tail, 2, 2)
+	}
+}
+
+// Menguji bahwa antrean penarikan mengikuti urutan transaksi exit dan array
+// validator tetap rapat setelah entri di tengah maupun di akhir dihapus.
+func TestExitQueueOrder(t *testing.T) {
+	tt := newTester(t, &Config{Period: 1, Epoch: 4}, 100, 200, 300)
+	engine := tt.engine()
+	headers := tt.extend(engine, 1, nil)
+
+	statedb, _ := state.New(tt.root, state.NewDatabase(tt.db), nil)
+	signer := types.MakeSigner(tt.chain.Config(), big.NewInt(1))
+	var txs []*types.Transaction
+	for _, validator := range []common.Address{tt.Addrs[2], tt.Addrs[0]} {
+		tx, _ := types.SignTx(types.NewTransaction(0, tt.config.Registry, nil, 50000, big.NewInt(1), ExitData), signer, tt.Keys[validator])
+		txs = append(txs, tx)
+	}
+	engine.Finalize(tt.chain, types.CopyHeader(headers[0]), statedb, txs, nil)
+
+	if have := statedb.GetState(tt.config.Registry, validatorsSlot).Big().Uint64(); have != 1 {
+		t.Errorf("registry length mismatch: have %d, want %d", have, 1)
+	}
+	if have := common.BytesToAddress(statedb.GetState(tt.config.Registry, arraySlot(validatorsSlot, 0, 1, 0)).Bytes()); have != tt.Addrs[1] {
+		t.Errorf("remaining validator mismatch: have %x, want %x", have, tt.Addrs[1])
+	}
+	head, tail := readWithdrawalQueue(statedb, tt.config.Registry)
+	tests := []struct {
+		validator common.Address
+		amount    int64
+	}{
+		{tt.Addrs[2], 300},
+		{tt.Addrs[0], 100},
+	}
+	if tail-head != uint64(len(tests)) {
+		t.Fatalf("queue length mismatch: have %d, want %d", tail-head, len(tests))
+	}
+	for i, test := range tests {
+		withdrawal := readWithdrawal(statedb, tt.config.Registry, head+uint64(i))
+		if withdrawal.Validator != test.validator || withdrawal.Amount.Int64() != test.amount || withdrawal.Release != 8 {
+			t.Errorf("withdrawal %d mismatch: have %+v, want %x/%d", i, withdrawal, test.validator, test.amount)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package pos
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// defaultJailEpochs adalah jumlah epoch default validator dipenjara jika JailEpochs
+// tidak diisi.
+const defaultJailEpochs = 1
+
+// UnjailData adalah calldata transaksi unjail, yaitu selector dari fungsi unjail()
+// di kontrak registry. Validator yang masa penjaranya sudah lewat mengirim transaksi
+// dengan calldata ini ke registry agar masuk kembali ke kumpulan validator pada
+// checkpoint berikutnya. Mesin konsensus hanya melihat pengirim, tujuan dan calldata
+// transaksi, jadi hasil eksekusinya di kontrak tidak berpengaruh.
+var UnjailData = crypto.Keccak256([]byte("unjail()"))[:4]
+
+// Performance adalah catatan kinerja validator di registry pada sebuah blok.
+type Performance struct {
+	Missed      uint64 `json:"missed"`      // Jumlah giliran yang terlewat sejak checkpoint terakhir
+	Jailed      bool   `json:"jailed"`      // Apakah validator sedang dipenjara
+	JailedUntil uint64 `json:"jailedUntil"` // Blok paling awal validator boleh mengirim transaksi unjail
+}
+
+// readPerformance membaca catatan kinerja validator dari akun registry di state.
+func readPerformance(statedb *state.StateDB, registry, validator common.Address) *Performance {
+	until := statedb.GetState(registry, mappingSlot(validator, jailedSlot)).Big().Uint64()
+	return &Performance{
+		Missed:      statedb.GetState(registry, mappingSlot(validator, missedSlot)).Big().Uint64(),
+		Jailed:      until != 0,
+		JailedUntil: until,
+	}
+}
+
+// accountPerformance memperbarui catatan kinerja validator di registry untuk blok
+// yang sedang difinalisasi. Blok yang disegel validator cadangan berarti pengusul
+// terpilih melewatkan gilirannya. Validator yang melewatkan JailThreshold giliran
+// dalam satu epoch dipenjara: ia tidak masuk kumpulan validator selama JailEpochs
+// checkpoint berikutnya dan setelah itu harus mengirim transaksi unjail. Proposal
+// adalah satu-satunya tugas validator di mesin ini, jadi hanya proposal yang dinilai.
+func (p *PoS) accountPerformance(chain consensus.ChainHeaderReader, header *types.Header, statedb *state.StateDB, txs []*types.Transaction) {
+	if p.config.JailThreshold == 0 {
+		return
+	}
+	number := header.Number.Uint64()
+	snap, err := p.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		log.Error("Failed to retrieve validator snapshot", "number", number, "err", err)
+		return
+	}
+	registry := p.config.Registry
+
+	// Hitungan giliran terlewat dimulai ulang di setiap checkpoint
+	if number%p.config.Epoch == 0 {
+		for _, v := range snap.Validators {
+			statedb.SetState(registry, mappingSlot(v.Address, missedSlot), common.Hash{})
+		}
+	}
+	// Bebaskan validator yang masa penjaranya sudah lewat dan meminta unjail
+	signer := types.MakeSigner(chain.Config(), header.Number)
+	for _, tx := range txs {
+		if tx.To() == nil || *tx.To() != registry || !bytes.Equal(tx.Data(), UnjailData) {
+			continue
+		}
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			continue
+		}
+		jailed := mappingSlot(from, jailedSlot)
+		if until := statedb.GetState(registry, jailed).Big(); until.Sign() > 0 && until.Uint64() <= number {
+			statedb.SetState(registry, jailed, common.Hash{})
+		}
+	}
+	// Catat giliran yang terlewat, dan penjarakan validator yang melewati batas
+	if header.Difficulty.Cmp(diffNoTurn) != 0 {
+		return
+	}
+	proposer := snap.proposer(number)
+	if statedb.GetState(registry, mappingSlot(proposer, jailedSlot)) != (common.Hash{}) {
+		return
+	}
+	missed := mappingSlot(proposer, missedSlot)
+	count := statedb.GetState(registry, missed).Big().Uint64() + 1
+	if count < p.config.JailThreshold {
+		statedb.SetState(registry, missed, common.BigToHash(new(big.Int).SetUint64(count)))
+		return
+	}
+	until := (number/p.config.Epoch + p.config.JailEpochs) * p.config.Epoch
+	statedb.SetState(registry, missed, common.Hash{})
+	statedb.SetState(registry, mappingSlot(proposer, jailedSlot), common.BigToHash(new(big.Int).SetUint64(until)))
+	log.Info("Jailed underperforming validator", "validator", proposer, "number", number, "until", until)
+}
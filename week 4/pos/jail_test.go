@@ -0,0 +1,109 @@
+package pos
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Menguji bahwa giliran yang terlewat dihitung per epoch, validator yang melewati
+// batas dipenjara dan dikeluarkan dari registry, dan transaksi unjail hanya berlaku
+// setelah masa penjara lewat.
+func TestJailing(t *testing.T) {
+	tt := newTester(t, &Config{Period: 1, Epoch: 32, JailThreshold: 3}, 100, 100)
+	engine := tt.engine()
+	headers := tt.extend(engine, 40, nil)
+
+	snap, err := engine.snapshot(tt.chain, 0, headers[0].ParentHash, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve snapshot: %v", err)
+	}
+	target, other := snap.proposer(1), tt.Other(snap.proposer(1))
+	statedb, _ := state.New(tt.root, state.NewDatabase(tt.db), nil)
+
+	// finalize menjalankan Finalize untuk blok dengan nomor tertentu, yang dicatat
+	// sebagai giliran terlewat jika missed bernilai true.
+	finalize := func(number uint64, missed bool, txs ...*types.Transaction) {
+		header := types.CopyHeader(headers[number-1])
+		if missed {
+			header.Difficulty = new(big.Int).Set(diffNoTurn)
+		}
+		engine.Finalize(tt.chain, header, statedb, txs, nil)
+	}
+	unjail := func(nonce, number uint64) *types.Transaction {
+		signer := types.MakeSigner(tt.chain.Config(), new(big.Int).SetUint64(number))
+		tx, err := types.SignTx(types.NewTransaction(nonce, tt.config.Registry, nil, 50000, big.NewInt(1), UnjailData), signer, tt.Keys[target])
+		if err != nil {
+			t.Fatalf("failed to sign unjail transaction: %v", err)
+		}
+		return tx
+	}
+	// Lewatkan empat giliran pertama target dan satu giliran validator lain. Giliran
+	// keempat terjadi saat target sudah dipenjara sehingga tidak dihitung lagi. Unjail
+	// di blok terakhir epoch masih terlalu awal.
+	var (
+		misses int
+		jailed bool
+		missed bool
+	)
+	for number := uint64(1); number < 32; number++ {
+		var txs []*types.Transaction
+		if number == 31 {
+			txs = append(txs, unjail(0, number))
+		}
+		switch proposer := snap.proposer(number); {
+		case proposer == target && misses < 4:
+			finalize(number, true, txs...)
+			misses++
+			jailed = jailed || misses == 3
+		case proposer == other && !missed:
+			finalize(number, true, txs...)
+			missed = true
+		default:
+			finalize(number, false, txs...)
+		}
+	}
+	if !jailed || !missed {
+		t.Fatalf("not enough proposer turns in the epoch")
+	}
+	if have := readPerformance(statedb, tt.config.Registry, target); !have.Jailed || have.JailedUntil != 32 || have.Missed != 0 {
+		t.Fatalf("target performance mismatch: have %+v, want jailed until 32", have)
+	}
+	if have := readPerformance(statedb, tt.config.Registry, other); have.Jailed || have.Missed != 1 {
+		t.Fatalf("other performance mismatch: have %+v, want 1 miss", have)
+	}
+	validators := readValidators(statedb, tt.config.Registry, nil, 16)
+	if len(validators) != 1 || validators[0].Address != other {
+		t.Fatalf("registry still lists jailed validator: %v", validators)
+	}
+	// Checkpoint memulai ulang hitungan, dan unjail setelah masa penjara diterima
+	finalize(32, false, unjail(1, 32))
+	if have := readPerformance(statedb, tt.config.Registry, other); have.Missed != 0 {
+		t.Errorf("checkpoint did not reset misses: have %d", have.Missed)
+	}
+	if have := readPerformance(statedb, tt.config.Registry, target); have.Jailed {
+		t.Errorf("unjail rejected: have %+v", have)
+	}
+	if validators := readValidators(statedb, tt.config.Registry, nil, 16); len(validators) != 2 {
+		t.Errorf("validator count after unjail mismatch: have %d, want 2", len(validators))
+	}
+}
+
+// Menguji bahwa tanpa JailThreshold, Finalize tidak mengubah registry sehingga root
+// state rantai yang sudah ada tidak berubah.
+func TestJailingDisabled(t *testing.T) {
+	tt := newTester(t, &Config{Period: 1, Epoch: 4}, 100, 100)
+	engine := tt.engine()
+	headers := tt.extend(engine, 2, nil)
+
+	statedb, _ := state.New(tt.root, state.NewDatabase(tt.db), nil)
+	header := types.CopyHeader(headers[1])
+	header.Difficulty = new(big.Int).Set(diffNoTurn)
+	engine.Finalize(tt.chain, header, statedb, nil, nil)
+
+	if header.Root != tt.root {
+		t.Errorf("state root mismatch: have %x, want %x", header.Root, tt.root)
+	}
+}
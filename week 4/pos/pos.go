@@ -0,0 +1,699 @@
+// Paket pos mengimplementasikan mesin konsensus proof-of-stake sederhana di mana
+// pengusul blok dipilih secara pseudo-acak dengan bobot sesuai stake di state.
+package pos
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+	lru "github.com/hashicorp/golang-lru"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	inmemorySnapshots  = 128  // Jumlah snapshot validator terbaru yang disimpan di memori
+	inmemorySignatures = 4096 // Jumlah signature blok terbaru yang disimpan di memori
+
+	mimetypePoS = "application/x-pos-header" // Tipe data yang ditandatangani oleh validator
+)
+
+// Konstanta protokol proof-of-stake.
+var (
+	epochLength   = uint64(30000) // Jumlah blok default sebelum kumpulan validator diperbarui
+	maxValidators = 128           // Jumlah default maksimum validator aktif
+
+	extraVanity    = 32                                       // Jumlah byte awalan extra-data yang dicadangkan untuk vanity
+	extraSeal      = crypto.SignatureLength                   // Jumlah byte akhiran extra-data yang dicadangkan untuk segel
+	validatorBytes = common.AddressLength + common.HashLength // Panjang satu entri validator (alamat + stake)
+
+	uncleHash = types.CalcUncleHash(nil) // Selalu Keccak256(RLP([])) karena paman tidak berarti di luar PoW.
+
+	diffInTurn = big.NewInt(2) // Kesulitan blok yang disegel oleh pengusul terpilih
+	diffNoTurn = big.NewInt(1) // Kesulitan blok yang disegel oleh validator cadangan
+)
+
+// Berbagai pesan error untuk menandai blok tidak valid. Error ini sengaja dibuat
+// privat agar bagian lain dari kode tidak bergantung pada error spesifik mesin ini.
+var (
+	// errUnknownBlock dikembalikan ketika kumpulan validator diminta untuk blok
+	// yang bukan bagian dari blockchain lokal.
+	errUnknownBlock = errors.New("unknown block")
+
+	// errMissingVanity dikembalikan jika extra-data lebih pendek dari 32 byte.
+	errMissingVanity = errors.New("extra-data 32 byte vanity prefix missing")
+
+	// errMissingSignature dikembalikan jika extra-data tidak berisi signature 65 byte.
+	errMissingSignature = errors.New("extra-data 65 byte signature suffix missing")
+
+	// errExtraValidators dikembalikan jika blok non-checkpoint berisi daftar validator.
+	errExtraValidators = errors.New("non-checkpoint block contains extra validator list")
+
+	// errInvalidCheckpointValidators dikembalikan jika daftar validator pada checkpoint
+	// tidak valid (panjangnya tidak habis dibagi 52 byte, kosong, melebihi jumlah
+	// maksimum validator, tidak terurut, atau berisi stake nol).
+	errInvalidCheckpointValidators = errors.New("invalid validator list on checkpoint block")
+
+	// errMismatchingCheckpointValidators dikembalikan saat pemrosesan blok jika daftar
+	// validator pada checkpoint berbeda dari yang tercatat di state.
+	errMismatchingCheckpointValidators = errors.New("mismatching validator list on checkpoint block")
+
+	// errWrongDifficulty dikembalikan jika kesulitan blok tidak sesuai dengan
+	// apakah penandatangan adalah pengusul terpilih.
+	errWrongDifficulty = errors.New("wrong difficulty")
+
+	// errInvalidCoinbase dikembalikan jika coinbase blok bukan penandatangannya.
+	errInvalidCoinbase = errors.New("coinbase does not match signer")
+
+	// errUnauthorizedValidator dikembalikan jika header ditandatangani oleh pihak
+	// yang bukan validator aktif.
+	errUnauthorizedValidator = errors.New("unauthorized validator")
+
+	// errNoDepositContract dikembalikan jika status deposit diminta tetapi kontrak
+	// deposit tidak dikonfigurasi.
+	errNoDepositContract = errors.New("deposit contract not configured")
+
+	// errNoWithdrawal dikembalikan jika posisi antrean diminta untuk validator yang
+	// tidak memiliki penarikan yang menunggu.
+	errNoWithdrawal = errors.New("no pending withdrawal")
+)
+
+// Config adalah parameter konsensus dari mesin proof-of-stake.
+type Config struct {
+	Period              uint64         `json:"period"`              // Jumlah detik minimum di antara blok
+	Epoch               uint64         `json:"epoch"`               // Jumlah blok sebelum kumpulan validator dibaca ulang dari state
+	Registry            common.Address `json:"registry"`            // Akun yang storage-nya menyimpan daftar validator dan stake
+	MinStake            *big.Int       `json:"minStake"`            // Stake minimum agar validator masuk kumpulan aktif (opsional)
+	MaxValidators       int            `json:"maxValidators"`       // Jumlah maksimum entri registry yang dibaca di setiap checkpoint
+	BlockReward         *big.Int       `json:"blockReward"`         // Reward untuk pengusul setiap blok (opsional)
+	JailThreshold       uint64         `json:"jailThreshold"`       // Jumlah giliran terlewat dalam satu epoch sebelum validator dipenjara (0 = nonaktif)
+	JailEpochs          uint64         `json:"jailEpochs"`          // Jumlah checkpoint validator dikeluarkan sebelum boleh unjail
+	SyncCommitteeSize   int            `json:"syncCommitteeSize"`   // Jumlah maksimum anggota sync committee untuk light client
+	DepositContract     common.Address `json:"depositContract"`     // Kontrak deposit validator di rantai ini (opsional)
+	WithdrawalsPerBlock int            `json:"withdrawalsPerBlock"` // Jumlah maksimum penarikan yang dikreditkan di setiap blok
+}
+
+// SignerFn melakukan hash dan menandatangani data menggunakan akun pendukung.
+type SignerFn func(signer accounts.Account, mimeType string, message []byte) ([]byte, error)
+
+// ecrecover mengekstrak alamat akun Ethereum dari header yang sudah ditandatangani.
+func ecrecover(header *types.Header, sigcache *lru.ARCCache) (common.Address, error) {
+	// Jika signature sudah ada di cache, kembalikan langsung
+	hash := header.Hash()
+	if address, known := sigcache.Get(hash); known {
+		return address.(common.Address), nil
+	}
+	// Ambil signature dari extra-data header
+	if len(header.Extra) < extraSeal {
+		return common.Address{}, errMissingSignature
+	}
+	signature := header.Extra[len(header.Extra)-extraSeal:]
+
+	// Pulihkan public key dan alamat Ethereum
+	pubkey, err := crypto.Ecrecover(SealHash(header).Bytes(), signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	var signer common.Address
+	copy(signer[:], crypto.Keccak256(pubkey[1:])[12:])
+
+	sigcache.Add(hash, signer)
+	return signer, nil
+}
+
+// PoS adalah mesin konsensus proof-of-stake. Kumpulan validator beserta stake-nya
+// dibaca dari akun registry di state dan dicatat di extra-data setiap blok checkpoint,
+// sehingga header di antara checkpoint bisa diverifikasi tanpa mengakses state.
+type PoS struct {
+	consensus.NoFinality // Mesin ini tidak memiliki finalitas
+
+	config  *Config        // Parameter konfigurasi mesin konsensus
+	statedb state.Database // Database state untuk membaca registry stake
+
+	recents    *lru.ARCCache // Snapshot validator untuk blok terbaru agar reorg lebih cepat
+	signatures *lru.ARCCache // Signature dari blok terbaru agar verifikasi lebih cepat
+
+	syncSignatures *lru.ARCCache // Signature sync committee untuk blok terbaru, per penanda tangan
+	syncLock       sync.Mutex    // Melindungi isi syncSignatures
+
+	signer common.Address // Alamat Ethereum dari kunci penandatangan
+	signFn SignerFn       // Fungsi signer untuk mengotorisasi hash
+	lock   sync.RWMutex   // Melindungi field signer
+}
+
+// New membuat mesin konsensus proof-of-stake yang membaca stake dari state
+// di dalam database yang diberikan.
+func New(config *Config, db ethdb.Database) *PoS {
+	// Isi parameter konsensus yang kosong dengan nilai default
+	conf := *config
+	if conf.Epoch == 0 {
+		conf.Epoch = epochLength
+	}
+	if conf.MaxValidators <= 0 {
+		conf.MaxValidators = maxValidators
+	}
+	if conf.JailThreshold > 0 && conf.JailEpochs == 0 {
+		conf.JailEpochs = defaultJailEpochs
+	}
+	if conf.WithdrawalsPerBlock <= 0 {
+		conf.WithdrawalsPerBlock = defaultWithdrawalsPerBlock
+	}
+	if conf.SyncCommitteeSize <= 0 {
+		conf.SyncCommitteeSize = syncCommitteeSize
+	}
+	recents, _ := lru.NewARC(inmemorySnapshots)
+	signatures, _ := lru.NewARC(inmemorySignatures)
+	syncSignatures, _ := lru.NewARC(inmemorySyncBlocks)
+
+	return &PoS{
+		config:         &conf,
+		statedb:        state.NewDatabase(db),
+		recents:        recents,
+		signatures:     signatures,
+		syncSignatures: syncSignatures,
+	}
+}
+
+// Author mengimplementasikan consensus.Engine, mengembalikan alamat validator yang
+// dipulihkan dari signature di bagian extra-data header.
+func (p *PoS) Author(header *types.Header) (common.Address, error) {
+	return ecrecover(header, p.signatures)
+}
+
+// VerifyHeader memeriksa apakah header sesuai dengan aturan konsensus.
+func (p *PoS) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, seal bool) error {
+	return p.verifyHeader(chain, header, nil)
+}
+
+// VerifyHeaders mirip dengan VerifyHeader, tetapi memverifikasi sekumpulan header
+// secara berurutan di latar belakang. Metode mengembalikan saluran keluar untuk
+// membatalkan operasi dan saluran hasil (urutannya sama dengan inputan slice).
+func (p *PoS) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	abort := make(chan struct{})
+	results := make(chan error, len(headers))
+
+	go func() {
+		for i, header := range headers {
+			err := p.verifyHeader(chain, header, headers[:i])
+
+			select {
+			case <-abort:
+				return
+			case results <- err:
+			}
+		}
+	}()
+	return abort, results
+}
+
+// verifyHeader memeriksa apakah header sesuai dengan aturan konsensus. Pemanggil
+// boleh memberikan sekumpulan induk (urutan naik) agar tidak perlu mencarinya dari
+// database.
+func (p *PoS) verifyHeader(chain consensus.ChainHeaderReader, header *types.Header, parents []*types.Header) error {
+	if header.Number == nil {
+		return errUnknownBlock
+	}
+	number := header.Number.Uint64()
+
+	// Jangan buang waktu memeriksa blok dari masa depan
+	if header.Time > uint64(time.Now().Unix()) {
+		return consensus.ErrFutureBlock
+	}
+	// Pastikan extra-data berisi vanity dan signature
+	if len(header.Extra) < extraVanity {
+		return errMissingVanity
+	}
+	if len(header.Extra) < extraVanity+extraSeal {
+		return errMissingSignature
+	}
+	// Pastikan extra-data berisi daftar validator pada checkpoint, dan kosong selain itu
+	checkpoint := number%p.config.Epoch == 0
+	validatorsLen := len(header.Extra) - extraVanity - extraSeal
+	if !checkpoint && validatorsLen != 0 {
+		return errExtraValidators
+	}
+	if checkpoint && (validatorsLen == 0 || validatorsLen%validatorBytes != 0 || validatorsLen/validatorBytes > p.config.MaxValidators) {
+		return errInvalidCheckpointValidators
+	}
+	// Pastikan mix digest nol karena tidak dipakai
+	if header.MixDigest != (common.Hash{}) {
+		return consensus.ErrInvalidMixDigest
+	}
+	// Pastikan blok tidak berisi paman yang tidak berarti di PoS
+	if header.UncleHash != uncleHash {
+		return consensus.ErrInvalidUncleHash
+	}
+	// Pastikan kesulitan blok masuk akal (belum tentu benar pada titik ini)
+	if number > 0 {
+		if header.Difficulty == nil || (header.Difficulty.Cmp(diffInTurn) != 0 && header.Difficulty.Cmp(diffNoTurn) != 0) {
+			return consensus.ErrInvalidDifficulty
+		}
+	}
+	// Verifikasi bahwa batas gas <= 2^63-1
+	if header.GasLimit > params.MaxGasLimit {
+		return fmt.Errorf("invalid gasLimit: have %v, max %v", header.GasLimit, params.MaxGasLimit)
+	}
+	// Semua pemeriksaan dasar lolos, verifikasi field yang bergantung pada induk
+	return p.verifyCascadingFields(chain, header, parents)
+}
+
+// verifyCascadingFields memverifikasi semua field header yang bergantung pada
+// sekumpulan header sebelumnya.
+func (p *PoS) verifyCascadingFields(chain consensus.ChainHeaderReader, header *types.Header, parents []*types.Header) error {
+	// Blok genesis selalu valid
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil
+	}
+	var parent *types.Header
+	if len(parents) > 0 {
+		parent = parents[len(parents)-1]
+	} else {
+		parent = chain.GetHeader(header.ParentHash, number-1)
+	}
+	if parent == nil || parent.Number.Uint64() != number-1 || parent.Hash() != header.ParentHash {
+		return consensus.ErrUnknownAncestor
+	}
+	if parent.Time+p.config.Period > header.Time {
+		return consensus.ErrInvalidTimestamp
+	}
+	// Verifikasi bahwa gas yang dipakai <= batas gas
+	if header.GasUsed > header.GasLimit {
+		return fmt.Errorf("invalid gasUsed: have %d, gasLimit %d", header.GasUsed, header.GasLimit)
+	}
+	if !chain.Config().IsLondon(header.Number) {
+		if header.BaseFee != nil {
+			return fmt.Errorf("invalid baseFee before fork: have %d, want <nil>", header.BaseFee)
+		}
+		if err := misc.VerifyGaslimit(parent.GasLimit, header.GasLimit); err != nil {
+			return err
+		}
+	} else if err := misc.VerifyEip1559Header(chain.Config(), parent, header); err != nil {
+		return err
+	}
+	// Ambil kumpulan validator yang berlaku untuk header ini
+	snap, err := p.snapshot(chain, number-1, header.ParentHash, parents)
+	if err != nil {
+		return err
+	}
+	// Daftar validator checkpoint hanya diperiksa bentuknya di sini agar header bisa
+	// disinkronkan tanpa state. Isinya dicocokkan dengan registry di VerifyUncles,
+	// saat blok diproses dan state induknya tersedia.
+	if number%p.config.Epoch == 0 {
+		if err := verifyCheckpointValidators(header.Extra[extraVanity : len(header.Extra)-extraSeal]); err != nil {
+			return err
+		}
+	}
+	return p.verifySeal(snap, header, parent)
+}
+
+// verifyCheckpointValidators memeriksa bentuk daftar validator pada checkpoint tanpa
+// mengakses state: alamat harus terurut naik tanpa duplikat dan setiap stake positif,
+// sama seperti daftar yang dihasilkan checkpointValidators.
+func verifyCheckpointValidators(data []byte) error {
+	validators := decodeValidators(data)
+	for i, v := range validators {
+		if v.Stake.Sign() == 0 {
+			return errInvalidCheckpointValidators
+		}
+		if i > 0 && bytes.Compare(validators[i-1].Address[:], v.Address[:]) >= 0 {
+			return errInvalidCheckpointValidators
+		}
+	}
+	return nil
+}
+
+// verifyCheckpointState mencocokkan daftar validator pada blok checkpoint dengan
+// registry di state induknya. Pemeriksaan ini bagian dari pemrosesan blok, bukan
+// verifikasi header, karena state induk baru tersedia setelah induk dieksekusi.
+func (p *PoS) verifyCheckpointState(chain consensus.ChainHeaderReader, header *types.Header) error {
+	number := header.Number.Uint64()
+	if number == 0 || number%p.config.Epoch != 0 {
+		return nil
+	}
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	snap, err := p.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	statedb, err := state.New(parent.Root, p.statedb, nil)
+	if err != nil {
+		return consensus.ErrPrunedAncestor
+	}
+	expected := encodeValidators(p.checkpointValidators(statedb, snap))
+	if !bytes.Equal(header.Extra[extraVanity:len(header.Extra)-extraSeal], expected) {
+		return errMismatchingCheckpointValidators
+	}
+	return nil
+}
+
+// snapshot mengambil kumpulan validator yang berlaku untuk anak dari blok dengan
+// nomor dan hash tertentu, yaitu kumpulan dari checkpoint terakhir di rantainya.
+func (p *PoS) snapshot(chain consensus.ChainHeaderReader, number uint64, hash common.Hash, parents []*types.Header) (*Snapshot, error) {
+	var (
+		hashes []common.Hash
+		snap   *Snapshot
+	)
+	for snap == nil {
+		// Jika snapshot di memori ditemukan, gunakan itu
+		if s, ok := p.recents.Get(hash); ok {
+			snap = s.(*Snapshot)
+			break
+		}
+		// Ambil header, dari induk eksplisit jika ada atau dari database
+		var header *types.Header
+		if len(parents) > 0 {
+			header = parents[len(parents)-1]
+			if header.Hash() != hash || header.Number.Uint64() != number {
+				return nil, consensus.ErrUnknownAncestor
+			}
+			parents = parents[:len(parents)-1]
+		} else {
+			header = chain.GetHeader(hash, number)
+			if header == nil {
+				return nil, consensus.ErrUnknownAncestor
+			}
+		}
+		// Checkpoint menetapkan kumpulan validator baru
+		if number%p.config.Epoch == 0 {
+			if len(header.Extra) < extraVanity+extraSeal {
+				return nil, errMissingSignature
+			}
+			validators := decodeValidators(header.Extra[extraVanity : len(header.Extra)-extraSeal])
+			snap = newSnapshot(number, hash, validators)
+			break
+		}
+		hashes = append(hashes, hash)
+		number, hash = number-1, header.ParentHash
+	}
+	// Simpan snapshot untuk semua blok yang dilewati agar pencarian berikutnya cepat
+	p.recents.Add(snap.Hash, snap)
+	for _, hash := range hashes {
+		p.recents.Add(hash, snap)
+	}
+	return snap, nil
+}
+
+// checkpointValidators mengembalikan kumpulan validator yang harus dicatat di blok
+// checkpoint berikutnya. Jika registry kosong atau tidak ada validator yang memenuhi
+// stake minimum, kumpulan lama dipertahankan agar rantai tidak berhenti.
+func (p *PoS) checkpointValidators(statedb *state.StateDB, snap *Snapshot) []Validator {
+	if validators := readValidators(statedb, p.config.Registry, p.config.MinStake, p.config.MaxValidators); len(validators) > 0 {
+		return validators
+	}
+	return snap.Validators
+}
+
+// verifySeal memeriksa apakah signature di dalam header berasal dari validator aktif
+// dan apakah kesulitan serta waktunya sesuai dengan jadwal pengusul.
+func (p *PoS) verifySeal(snap *Snapshot, header, parent *types.Header) error {
+	number := header.Number.Uint64()
+	if number == 0 {
+		return errUnknownBlock
+	}
+	signer, err := ecrecover(header, p.signatures)
+	if err != nil {
+		return err
+	}
+	if snap.stake(signer) == nil {
+		return errUnauthorizedValidator
+	}
+	if header.Coinbase != signer {
+		return errInvalidCoinbase
+	}
+	// Pengusul terpilih menyegel dengan kesulitan tinggi, validator lain hanya boleh
+	// menggantikannya setelah satu periode tambahan berlalu
+	if snap.inturn(number, signer) {
+		if header.Difficulty.Cmp(diffInTurn) != 0 {
+			return errWrongDifficulty
+		}
+		return nil
+	}
+	if header.Difficulty.Cmp(diffNoTurn) != 0 {
+		return errWrongDifficulty
+	}
+	if parent.Time+2*p.backupDelay() > header.Time {
+		return consensus.ErrInvalidTimestamp
+	}
+	return nil
+}
+
+// backupDelay mengembalikan periode yang harus ditunggu validator cadangan sebelum
+// menggantikan pengusul terpilih. Chain dengan periode 0 tetap memakai satu detik.
+func (p *PoS) backupDelay() uint64 {
+	if p.config.Period == 0 {
+		return 1
+	}
+	return p.config.Period
+}
+
+// VerifyUncles mengimplementasikan consensus.Engine, selalu mengembalikan error untuk
+// paman karena mekanisme konsensus ini tidak mengizinkan paman. Metode ini dipanggil
+// saat body blok divalidasi sebelum blok dieksekusi, jadi daftar validator checkpoint
+// juga dicocokkan dengan registry di sini: Finalize tidak bisa menolak blok.
+func (p *PoS) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
+	if len(block.Uncles()) > 0 {
+		return consensus.ErrTooManyUncles
+	}
+	return p.verifyCheckpointState(chain, block.Header())
+}
+
+// Prepare mengimplementasikan consensus.Engine, menyiapkan semua field konsensus
+// dari header sebelum transaksi dijalankan di atasnya.
+func (p *PoS) Prepare(chain consensus.ChainHeaderReader, header *types.Header) error {
+	number := header.Number.Uint64()
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	snap, err := p.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	p.lock.RLock()
+	signer := p.signer
+	p.lock.RUnlock()
+
+	// Validator menerima reward sebagai coinbase, dan kesulitan menandai giliran
+	header.Coinbase = signer
+	header.Nonce = types.BlockNonce{}
+	header.MixDigest = common.Hash{}
+
+	inturn := snap.inturn(number, signer)
+	if inturn {
+		header.Difficulty = new(big.Int).Set(diffInTurn)
+	} else {
+		header.Difficulty = new(big.Int).Set(diffNoTurn)
+	}
+	// Pastikan extra data memiliki semua komponennya
+	if len(header.Extra) < extraVanity {
+		header.Extra = append(header.Extra, bytes.Repeat([]byte{0x00}, extraVanity-len(header.Extra))...)
+	}
+	header.Extra = header.Extra[:extraVanity]
+
+	if number%p.config.Epoch == 0 {
+		statedb, err := state.New(parent.Root, p.statedb, nil)
+		if err != nil {
+			return err
+		}
+		header.Extra = append(header.Extra, encodeValidators(p.checkpointValidators(statedb, snap))...)
+	}
+	header.Extra = append(header.Extra, make([]byte, extraSeal)...)
+
+	// Pastikan stempel waktu memiliki jeda yang benar
+	header.Time = parent.Time + p.config.Period
+	if !inturn {
+		header.Time = parent.Time + 2*p.backupDelay()
+	}
+	if header.Time < uint64(time.Now().Unix()) {
+		header.Time = uint64(time.Now().Unix())
+	}
+	return nil
+}
+
+// Finalize mengimplementasikan consensus.Engine, memberikan block reward (jika
+// dikonfigurasi) kepada pengusul, memasukkan deposit baru ke registry di akhir
+// epoch (jika kontrak deposit dikonfigurasi), memperbarui catatan kinerja validator
+// (jika penjara diaktifkan), memproses exit dan mengkreditkan antrean penarikan, lalu
+// menetapkan root state akhir.
+func (p *PoS) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header) {
+	if p.config.BlockReward != nil && p.config.BlockReward.Sign() > 0 {
+		state.AddBalance(header.Coinbase, p.config.BlockReward)
+	}
+	p.processDeposits(header, state)
+	p.accountPerformance(chain, header, state, txs)
+	p.processExits(chain, header, state, txs)
+	p.processWithdrawals(header, state)
+	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
+	header.UncleHash = types.CalcUncleHash(nil)
+}
+
+// FinalizeAndAssemble mengimplementasikan consensus.Engine, menjalankan Finalize
+// lalu merakit blok terakhir.
+func (p *PoS) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
+	p.Finalize(chain, header, state, txs, uncles)
+	return types.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil)), nil
+}
+
+// Authorize menyuntikkan kunci privat ke dalam mesin konsensus untuk mencetak
+// blok baru.
+func (p *PoS) Authorize(signer common.Address, signFn SignerFn) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.signer = signer
+	p.signFn = signFn
+}
+
+// Seal mengimplementasikan consensus.Engine, mencoba membuat blok tersegel
+// menggunakan kredensial validator lokal.
+func (p *PoS) Seal(chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
+	header := block.Header()
+
+	// Penyegelan blok genesis tidak didukung
+	number := header.Number.Uint64()
+	if number == 0 {
+		return errUnknownBlock
+	}
+	// Untuk chain dengan periode 0, tolak menyegel blok kosong
+	if p.config.Period == 0 && len(block.Transactions()) == 0 {
+		return errors.New("sealing paused while waiting for transactions")
+	}
+	p.lock.RLock()
+	signer, signFn := p.signer, p.signFn
+	p.lock.RUnlock()
+
+	// Berhenti jika kita bukan validator aktif
+	snap, err := p.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	if snap.stake(signer) == nil {
+		return errUnauthorizedValidator
+	}
+	// Tandatangani header
+	sighash, err := signFn(accounts.Account{Address: signer}, mimetypePoS, PoSRLP(header))
+	if err != nil {
+		return err
+	}
+	copy(header.Extra[len(header.Extra)-extraSeal:], sighash)
+
+	// Tunggu sampai waktu blok tiba atau penyegelan dihentikan
+	delay := time.Until(time.Unix(int64(header.Time), 0))
+	log.Trace("Waiting for slot to sign and propagate", "delay", common.PrettyDuration(delay), "inturn", snap.inturn(number, signer))
+	go func() {
+		select {
+		case <-stop:
+			return
+		case <-time.After(delay):
+		}
+		select {
+		case results <- block.WithSeal(header):
+		default:
+			log.Warn("Sealing result is not read by miner", "sealhash", SealHash(header))
+		}
+	}()
+	return nil
+}
+
+// CalcDifficulty adalah algoritma penyesuaian kesulitan. Ini mengembalikan
+// diffInTurn jika validator lokal adalah pengusul terpilih untuk blok berikutnya,
+// dan diffNoTurn jika tidak.
+func (p *PoS) CalcDifficulty(chain consensus.ChainHeaderReader, time uint64, parent *types.Header) *big.Int {
+	snap, err := p.snapshot(chain, parent.Number.Uint64(), parent.Hash(), nil)
+	if err != nil {
+		return nil
+	}
+	p.lock.RLock()
+	signer := p.signer
+	p.lock.RUnlock()
+
+	if snap.inturn(parent.Number.Uint64()+1, signer) {
+		return new(big.Int).Set(diffInTurn)
+	}
+	return new(big.Int).Set(diffNoTurn)
+}
+
+// SealHash mengembalikan hash dari sebuah blok sebelum disegel.
+func (p *PoS) SealHash(header *types.Header) common.Hash {
+	return SealHash(header)
+}
+
+// Close mengimplementasikan consensus.Engine. Tidak melakukan apa-apa karena
+// mesin ini tidak memiliki utas latar belakang.
+func (p *PoS) Close() error {
+	return nil
+}
+
+// APIs mengimplementasikan consensus.Engine, mengembalikan API RPC untuk
+// memeriksa kumpulan validator, jadwal pengusul, deposit dan antrean penarikan,
+// serta untuk sync committee light client.
+func (p *PoS) APIs(chain consensus.ChainHeaderReader) []rpc.API {
+	return []rpc.API{{
+		Namespace: "pos",
+		Service:   &API{chain: chain, pos: p},
+	}}
+}
+
+// SealHash mengembalikan hash dari sebuah blok sebelum disegel.
+func SealHash(header *types.Header) (hash common.Hash) {
+	hasher := sha3.NewLegacyKeccak256()
+	encodeSigHeader(hasher, header)
+	hasher.(crypto.KeccakState).Read(hash[:])
+	return hash
+}
+
+// PoSRLP mengembalikan byte rlp yang perlu ditandatangani oleh validator, yaitu
+// seluruh header kecuali signature 65 byte di akhir extra data.
+func PoSRLP(header *types.Header) []byte {
+	b := new(bytes.Buffer)
+	encodeSigHeader(b, header)
+	return b.Bytes()
+}
+
+// encodeSigHeader menulis encoding RLP dari header tanpa segel signature.
+func encodeSigHeader(w io.Writer, header *types.Header) {
+	enc := []interface{}{
+		header.ParentHash,
+		header.UncleHash,
+		header.Coinbase,
+		header.Root,
+		header.TxHash,
+		header.ReceiptHash,
+		header.Bloom,
+		header.Difficulty,
+		header.Number,
+		header.GasLimit,
+		header.GasUsed,
+		header.Time,
+		header.Extra[:len(header.Extra)-crypto.SignatureLength], // Ya, ini akan panic jika extra terlalu pendek
+		header.MixDigest,
+		header.Nonce,
+	}
+	if header.BaseFee != nil {
+		enc = append(enc, header.BaseFee)
+	}
+	if err := rlp.Encode(w, enc); err != nil {
+		panic("can't encode: " + err.Error())
+	}
+}
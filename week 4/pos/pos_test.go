@@ -0,0 +1,382 @@
+package pos
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/consensustest"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// tester menyiapkan rantai di memori, state registry stake dan kunci validator
+// untuk pengujian mesin proof-of-stake.
+type tester struct {
+	*consensustest.Accounts // Validator di genesis
+
+	t      *testing.T
+	db     ethdb.Database
+	root   common.Hash // Root state dengan isi registry, dipakai oleh semua blok
+	config *Config
+	chain  *consensustest.HeaderChain
+}
+
+// newTester membuat rantai dengan satu validator untuk setiap stake yang diberikan,
+// sesuai urutan alamat. Registry di state dan daftar validator genesis berisi
+// validator yang sama.
+func newTester(t *testing.T, config *Config, stakes ...int64) *tester {
+	t.Helper()
+
+	tt := &tester{
+		Accounts: consensustest.NewAccounts(len(stakes)),
+		t:        t,
+		db:       rawdb.NewMemoryDatabase(),
+		config:   config,
+	}
+	var validators []Validator
+	for i, stake := range stakes {
+		validators = append(validators, Validator{Address: tt.Addrs[i], Stake: big.NewInt(stake)})
+	}
+	tt.root = tt.commitRegistry(validators)
+
+	genesis := consensustest.Genesis()
+	genesis.Root = tt.root
+	genesis.Extra = append(append(make([]byte, extraVanity), encodeValidators(newSnapshot(0, common.Hash{}, validators).Validators)...), make([]byte, extraSeal)...)
+	tt.chain = consensustest.NewHeaderChain(params.AllCliqueProtocolChanges, genesis)
+	return tt
+}
+
+// commitRegistry menulis daftar validator ke storage registry dan mengembalikan
+// root state hasilnya.
+func (tt *tester) commitRegistry(validators []Validator) common.Hash {
+	return consensustest.CommitState(tt.t, tt.db, func(statedb *state.StateDB) {
+		// Registry asli adalah kontrak; tanpa kode, akun yang hanya berisi storage
+		// dianggap kosong dan dihapus begitu disentuh setelah EIP-158
+		statedb.SetCode(tt.config.Registry, []byte{0x00})
+		statedb.SetState(tt.config.Registry, validatorsSlot, common.BigToHash(big.NewInt(int64(len(validators)))))
+		base := new(big.Int).SetBytes(crypto.Keccak256(validatorsSlot[:]))
+		for i, v := range validators {
+			slot := common.BigToHash(new(big.Int).Add(base, big.NewInt(int64(i))))
+			statedb.SetState(tt.config.Registry, slot, common.BytesToHash(v.Address[:]))
+			statedb.SetState(tt.config.Registry, crypto.Keccak256Hash(common.LeftPadBytes(v.Address[:], 32), stakesSlot[:]), common.BigToHash(v.Stake))
+		}
+	})
+}
+
+// engine membuat mesin proof-of-stake baru di atas database pengujian.
+func (tt *tester) engine() *PoS {
+	return New(tt.config, tt.db)
+}
+
+// sign menandatangani header dengan kunci validator yang diberikan.
+func (tt *tester) sign(header *types.Header, signer common.Address) {
+	sig, err := tt.Sign(signer, SealHash(header))
+	if err != nil {
+		tt.t.Fatalf("failed to sign header: %v", err)
+	}
+	copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+}
+
+// extend menambahkan n blok ke rantai. Setiap blok disegel oleh pengusul terpilih,
+// kecuali fungsi pick memilih validator lain untuk nomor blok tersebut. Blok dari
+// validator cadangan diberi waktu setelah jeda cadangan.
+func (tt *tester) extend(engine *PoS, n int, pick func(number uint64, proposer common.Address) common.Address) []*types.Header {
+	tt.t.Helper()
+
+	choose := func(header, parent *types.Header) common.Address {
+		number := header.Number.Uint64()
+		snap, err := engine.snapshot(tt.chain, number-1, header.ParentHash, nil)
+		if err != nil {
+			tt.t.Fatalf("block %d: failed to retrieve snapshot: %v", number, err)
+		}
+		signer := snap.proposer(number)
+		if pick != nil {
+			signer = pick(number, signer)
+		}
+		engine.Authorize(signer, tt.SignFn(signer))
+		return signer
+	}
+	seal := func(header, parent *types.Header, signer common.Address) {
+		header.Time = parent.Time + engine.config.Period
+		if header.Difficulty.Cmp(diffInTurn) != 0 {
+			header.Time = parent.Time + 2*engine.backupDelay()
+		}
+		header.Root = tt.root
+		tt.sign(header, signer)
+	}
+	return consensustest.Extend(tt.t, tt.chain, engine, n, choose, seal)
+}
+
+// Menguji bahwa rantai yang disegel oleh pengusul terpilih maupun validator cadangan,
+// termasuk beberapa checkpoint, lolos verifikasi satu per satu dan dalam batch.
+func TestVerifyChain(t *testing.T) {
+	tt := newTester(t, &Config{Period: 1, Epoch: 4}, 100, 300, 600)
+	headers := tt.extend(tt.engine(), 12, func(number uint64, proposer common.Address) common.Address {
+		if number%5 == 0 {
+			return tt.Other(proposer)
+		}
+		return proposer
+	})
+	// Verifikasi batch memakai database kosong, seperti sinkronisasi header yang
+	// belum mengeksekusi blok mana pun
+	consensustest.VerifyChain(t, tt.chain, headers, tt.engine(), New(tt.config, rawdb.NewMemoryDatabase()))
+}
+
+// Menguji bahwa header yang melanggar aturan proof-of-stake ditolak dengan error
+// yang sesuai, terutama giliran pengusul dan bentuk daftar validator checkpoint.
+func TestVerifyHeaderReject(t *testing.T) {
+	tt := newTester(t, &Config{Period: 1, Epoch: 4}, 100, 300)
+	headers := tt.extend(tt.engine(), 5, nil)
+
+	block, checkpoint := headers[1], headers[3]
+	signer, _ := tt.engine().Author(block)
+	checkpointSigner, _ := tt.engine().Author(checkpoint)
+	strangers := consensustest.NewAccounts(1)
+
+	resign := func(h *types.Header) { tt.sign(h, signer) }
+	tests := append(consensustest.HeaderRejects(headers[0], resign), []consensustest.Reject{
+		{Name: "missing vanity", Mutate: func(h *types.Header) { h.Extra = h.Extra[:extraVanity-1] }, Want: errMissingVanity},
+		{Name: "missing signature", Mutate: func(h *types.Header) { h.Extra = h.Extra[:extraVanity] }, Want: errMissingSignature},
+		{Name: "extra validators", Mutate: func(h *types.Header) {
+			h.Extra = append(append(h.Extra[:extraVanity:extraVanity], make([]byte, validatorBytes)...), make([]byte, extraSeal)...)
+			resign(h)
+		}, Want: errExtraValidators},
+		{Name: "wrong turn difficulty", Mutate: func(h *types.Header) { h.Difficulty = new(big.Int).Set(diffNoTurn); resign(h) }, Want: errWrongDifficulty},
+		{Name: "coinbase", Mutate: func(h *types.Header) { h.Coinbase = tt.Other(signer); resign(h) }, Want: errInvalidCoinbase},
+		{Name: "unauthorized", Mutate: func(h *types.Header) {
+			h.Coinbase = strangers.Addrs[0]
+			sig, _ := strangers.Sign(h.Coinbase, SealHash(h))
+			copy(h.Extra[len(h.Extra)-extraSeal:], sig)
+		}, Want: errUnauthorizedValidator},
+		{Name: "unsorted checkpoint", Base: checkpoint, Mutate: func(h *types.Header) {
+			first := append([]byte{}, h.Extra[extraVanity:extraVanity+validatorBytes]...)
+			copy(h.Extra[extraVanity:], h.Extra[extraVanity+validatorBytes:extraVanity+2*validatorBytes])
+			copy(h.Extra[extraVanity+validatorBytes:], first)
+			tt.sign(h, checkpointSigner)
+		}, Want: errInvalidCheckpointValidators},
+		{Name: "zero stake checkpoint", Base: checkpoint, Mutate: func(h *types.Header) {
+			copy(h.Extra[extraVanity+common.AddressLength:], make([]byte, common.HashLength))
+			tt.sign(h, checkpointSigner)
+		}, Want: errInvalidCheckpointValidators},
+		{Name: "truncated checkpoint", Base: checkpoint, Mutate: func(h *types.Header) {
+			h.Extra = append(h.Extra[:extraVanity+1:extraVanity+1], make([]byte, extraSeal)...)
+		}, Want: errInvalidCheckpointValidators},
+		{Name: "oversized checkpoint", Base: checkpoint, Mutate: func(h *types.Header) {},
+			Engine: New(&Config{Period: 1, Epoch: 4, MaxValidators: 1}, tt.db), Want: errInvalidCheckpointValidators},
+		{Name: "missing checkpoint state", Base: checkpoint, Mutate: func(h *types.Header) {},
+			Engine: New(tt.config, rawdb.NewMemoryDatabase()), Want: nil},
+	}...)
+	consensustest.VerifyRejects(t, tt.chain, block, func() consensus.Engine { return tt.engine() }, tests)
+}
+
+// Menguji bahwa daftar validator checkpoint dicocokkan dengan registry di state
+// induk saat body blok divalidasi, bukan saat verifikasi header.
+func TestVerifyCheckpointState(t *testing.T) {
+	tt := newTester(t, &Config{Period: 1, Epoch: 4}, 100, 300)
+	headers := tt.extend(tt.engine(), 5, nil)
+	checkpoint := headers[3]
+	signer, _ := tt.engine().Author(checkpoint)
+
+	// Stake yang diubah masih berbentuk valid sehingga lolos verifikasi header
+	forged := types.CopyHeader(checkpoint)
+	forged.Extra[extraVanity+validatorBytes-1] ^= 0x01
+	tt.sign(forged, signer)
+	if err := tt.engine().VerifyHeader(tt.chain, forged, true); err != nil {
+		t.Fatalf("forged checkpoint header rejected: %v", err)
+	}
+	tests := []struct {
+		name   string
+		header *types.Header
+		engine *PoS
+		want   error
+	}{
+		{"valid", checkpoint, tt.engine(), nil},
+		{"non-checkpoint", headers[4], New(tt.config, rawdb.NewMemoryDatabase()), nil},
+		{"mismatching", forged, tt.engine(), errMismatchingCheckpointValidators},
+		{"missing state", checkpoint, New(tt.config, rawdb.NewMemoryDatabase()), consensus.ErrPrunedAncestor},
+	}
+	for _, test := range tests {
+		if err := test.engine.VerifyUncles(tt.chain, types.NewBlockWithHeader(test.header)); err != test.want {
+			t.Errorf("%s: error mismatch: have %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+// Menguji bahwa Seal menandatangani blok dengan validator lokal dan menolak
+// penyegelan oleh pihak yang bukan validator.
+func TestSeal(t *testing.T) {
+	tt := newTester(t, &Config{Period: 1, Epoch: 100}, 100, 300)
+	engine := tt.engine()
+
+	parent := tt.chain.CurrentHeader()
+	snap, err := engine.snapshot(tt.chain, 0, parent.Hash(), nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve snapshot: %v", err)
+	}
+	signer := snap.proposer(1)
+	engine.Authorize(signer, tt.SignFn(signer))
+
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     big.NewInt(1),
+		GasLimit:   parent.GasLimit,
+		BaseFee:    misc.CalcBaseFee(tt.chain.Config(), parent),
+		UncleHash:  uncleHash,
+	}
+	if err := engine.Prepare(tt.chain, header); err != nil {
+		t.Fatalf("failed to prepare header: %v", err)
+	}
+	header.Time = parent.Time + 1
+	header.Root = tt.root
+
+	sealed := consensustest.SealBlock(t, tt.chain, engine, types.NewBlockWithHeader(header))
+	if author, err := tt.engine().Author(sealed.Header()); err != nil || author != signer {
+		t.Fatalf("author mismatch: have %x (%v), want %x", author, err, signer)
+	}
+	// Pihak luar tidak boleh menyegel
+	outsider := tt.engine()
+	outsider.Authorize(consensustest.NewAccounts(1).Addrs[0], tt.SignFn(signer))
+	if err := outsider.Seal(tt.chain, types.NewBlockWithHeader(header), make(chan *types.Block, 1), nil); err != errUnauthorizedValidator {
+		t.Fatalf("error mismatch: have %v, want %v", err, errUnauthorizedValidator)
+	}
+}
+
+// Menguji bahwa snapshot mengikuti checkpoint terakhir dan bahwa pengusul dipilih
+// secara deterministik dengan bobot sesuai stake.
+func TestSnapshot(t *testing.T) {
+	tt := newTester(t, &Config{Period: 1, Epoch: 8}, 100, 900)
+	headers := tt.extend(tt.engine(), 10, nil)
+	api := &API{chain: tt.chain, pos: tt.engine()}
+
+	snap, err := api.GetSnapshot(nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve snapshot: %v", err)
+	}
+	if snap.Number != 8 || snap.Hash != headers[7].Hash() {
+		t.Fatalf("snapshot checkpoint mismatch: have %d/%x, want 8/%x", snap.Number, snap.Hash, headers[7].Hash())
+	}
+	if snap.Total.Int64() != 1000 || len(snap.Validators) != 2 {
+		t.Fatalf("snapshot validators mismatch: total %v, count %d", snap.Total, len(snap.Validators))
+	}
+	heavy := tt.Addrs[0]
+	if snap.stake(heavy).Int64() != 900 {
+		heavy = tt.Addrs[1]
+	}
+	counts := make(map[common.Address]int)
+	for number := uint64(0); number < 10000; number++ {
+		proposer := snap.proposer(number)
+		if proposer != snap.proposer(number) {
+			t.Fatalf("block %d: proposer not deterministic", number)
+		}
+		counts[proposer]++
+	}
+	if counts[heavy] < 8500 || counts[heavy] > 9500 {
+		t.Fatalf("proposer weighting mismatch: heavy validator chosen %d/10000 times", counts[heavy])
+	}
+	if proposer, err := api.GetProposer(nil); err != nil || proposer != snap.proposer(11) {
+		t.Fatalf("next proposer mismatch: have %x (%v), want %x", proposer, err, snap.proposer(11))
+	}
+}
+
+// Menguji bahwa pembacaan registry dibatasi walaupun panjang array di storage sangat
+// besar, dan bahwa validator tanpa stake atau di bawah minimum diabaikan.
+func TestReadValidators(t *testing.T) {
+	tt := newTester(t, &Config{Period: 1, Epoch: 4})
+	validators := []Validator{
+		{Address: common.Address{1}, Stake: big.NewInt(10)},
+		{Address: common.Address{2}, Stake: big.NewInt(20)},
+		{Address: common.Address{3}, Stake: big.NewInt(0)},
+		{Address: common.Address{4}, Stake: big.NewInt(40)},
+	}
+	root := tt.commitRegistry(validators)
+	statedb, _ := state.New(root, state.NewDatabase(tt.db), nil)
+
+	if have := readValidators(statedb, tt.config.Registry, nil, 100); len(have) != 3 {
+		t.Errorf("validator count mismatch: have %d, want 3", len(have))
+	}
+	if have := readValidators(statedb, tt.config.Registry, big.NewInt(15), 100); len(have) != 2 {
+		t.Errorf("validator count with minimum stake mismatch: have %d, want 2", len(have))
+	}
+	if have := readValidators(statedb, tt.config.Registry, nil, 2); len(have) != 2 {
+		t.Errorf("validator count with limit mismatch: have %d, want 2", len(have))
+	}
+	// Panjang array yang sangat besar tidak boleh membuat pembacaan berjalan lama
+	statedb.SetState(tt.config.Registry, validatorsSlot, common.BigToHash(new(big.Int).Lsh(common.Big1, 200)))
+	start := time.Now()
+	if have := readValidators(statedb, tt.config.Registry, nil, 16); len(have) != 3 {
+		t.Errorf("validator count with huge length mismatch: have %d, want 3", len(have))
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("bounded read took too long: %v", elapsed)
+	}
+}
+
+// Menguji bahwa checkpoint mempertahankan kumpulan validator lama jika tidak ada
+// validator di registry yang memenuhi stake minimum, sehingga rantai tidak berhenti.
+func TestEmptyRegistryKeepsValidators(t *testing.T) {
+	tt := newTester(t, &Config{Period: 1, Epoch: 4, MinStake: big.NewInt(1000)}, 100, 300)
+	headers := tt.extend(tt.engine(), 6, nil)
+
+	checkpoint := headers[3]
+	validators := decodeValidators(checkpoint.Extra[extraVanity : len(checkpoint.Extra)-extraSeal])
+	if len(validators) != 2 {
+		t.Fatalf("checkpoint validator count mismatch: have %d, want 2", len(validators))
+	}
+	for i, header := range headers {
+		if err := tt.engine().VerifyHeader(tt.chain, header, true); err != nil {
+			t.Errorf("header %d: verification failed: %v", i, err)
+		}
+	}
+}
+
+// Menguji bahwa jadwal giliran validator mencakup setiap blok sampai checkpoint
+// berikutnya tepat satu kali, dan bahwa alamat yang bukan validator ditolak.
+func TestDuties(t *testing.T) {
+	tt := newTester(t, &Config{Period: 1, Epoch: 4}, 100, 300, 600)
+	tt.extend(tt.engine(), 5, nil)
+	api := &API{chain: tt.chain, pos: tt.engine()}
+
+	head := tt.chain.CurrentHeader()
+	snap, err := api.GetSnapshot(nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve snapshot: %v", err)
+	}
+	seen := make(map[uint64]bool)
+	for _, validator := range tt.Addrs {
+		duties, err := api.GetDuties(validator)
+		if err != nil {
+			t.Fatalf("failed to retrieve duties: %v", err)
+		}
+		for _, duty := range duties {
+			if seen[duty.Number] {
+				t.Errorf("block %d: duty assigned twice", duty.Number)
+			}
+			seen[duty.Number] = true
+			if proposer := snap.proposer(duty.Number); proposer != validator {
+				t.Errorf("block %d: proposer mismatch: have %x, want %x", duty.Number, validator, proposer)
+			}
+			if want := head.Time + duty.Number - head.Number.Uint64(); duty.Time != want {
+				t.Errorf("block %d: time mismatch: have %d, want %d", duty.Number, duty.Time, want)
+			}
+		}
+	}
+	for number := uint64(6); number <= 8; number++ {
+		if !seen[number] {
+			t.Errorf("block %d: no duty assigned", number)
+		}
+	}
+	if len(seen) != 3 {
+		t.Errorf("duty count mismatch: have %d, want %d", len(seen), 3)
+	}
+	if _, err := api.GetDuties(common.Address{1}); err != errUnauthorizedValidator {
+		t.Errorf("error mismatch: have %v, want %v", err, errUnauthorizedValidator)
+	}
+}
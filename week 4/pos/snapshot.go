@@ -0,0 +1,194 @@
+package pos
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Tata letak storage akun registry stake. Tata letak ini sama dengan kontrak
+// Solidity berikut, jadi registry bisa dikelola oleh kontrak biasa. Mapping missed
+// dan jailedUntil, penghitung deposits dan antrean penarikan ditulis oleh mesin
+// konsensus, bukan oleh kontrak:
+//
+//	contract StakeRegistry {
+//	    struct Withdrawal { address validator; uint256 amount; uint256 release; }
+//
+//	    address[] validators;                     // slot 0
+//	    mapping(address => uint256) stakes;       // slot 1
+//	    mapping(address => uint256) missed;       // slot 2
+//	    mapping(address => uint256) jailedUntil;  // slot 3
+//	    uint256 deposits;                         // slot 4, deposit yang sudah diproses
+//	    Withdrawal[] withdrawals;                 // slot 5, antrean penarikan
+//	    uint256 withdrawalHead;                   // slot 6, penarikan berikutnya di antrean
+//
+//	    function unjail() external {}             // Ditangani oleh mesin konsensus
+//	    function exit() external {}               // Ditangani oleh mesin konsensus
+//	}
+var (
+	validatorsSlot  = common.Hash{}                   // Slot panjang array validator
+	stakesSlot      = common.BigToHash(big.NewInt(1)) // Slot dasar mapping stake
+	missedSlot      = common.BigToHash(big.NewInt(2)) // Slot dasar mapping giliran terlewat
+	jailedSlot      = common.BigToHash(big.NewInt(3)) // Slot dasar mapping akhir masa penjara
+	depositsSlot    = common.BigToHash(big.NewInt(4)) // Slot jumlah deposit yang sudah diproses
+	withdrawalsSlot = common.BigToHash(big.NewInt(5)) // Slot panjang array antrean penarikan
+	headSlot        = common.BigToHash(big.NewInt(6)) // Slot posisi penarikan berikutnya di antrean
+)
+
+// mappingSlot mengembalikan slot storage untuk kunci alamat di dalam mapping
+// Solidity dengan slot dasar yang diberikan.
+func mappingSlot(address common.Address, base common.Hash) common.Hash {
+	return crypto.Keccak256Hash(common.LeftPadBytes(address[:], 32), base[:])
+}
+
+// arraySlot mengembalikan slot storage field ke-field dari elemen ke-index array
+// dinamis Solidity dengan slot panjang yang diberikan, untuk elemen berukuran size
+// slot.
+func arraySlot(base common.Hash, index, size, field uint64) common.Hash {
+	slot := new(big.Int).SetBytes(crypto.Keccak256(base[:]))
+	slot.Add(slot, new(big.Int).SetUint64(index*size+field))
+	return common.BigToHash(slot)
+}
+
+// Validator adalah satu validator aktif beserta jumlah stake-nya.
+type Validator struct {
+	Address common.Address `json:"address"` // Alamat validator yang menandatangani blok
+	Stake   *big.Int       `json:"stake"`   // Jumlah stake yang menentukan bobot pemilihan
+}
+
+// validatorsAscending mengimplementasikan sort.Interface untuk mengurutkan validator
+// berdasarkan alamat.
+type validatorsAscending []Validator
+
+func (s validatorsAscending) Len() int { return len(s) }
+func (s validatorsAscending) Less(i, j int) bool {
+	return bytes.Compare(s[i].Address[:], s[j].Address[:]) < 0
+}
+func (s validatorsAscending) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+// Snapshot adalah kumpulan validator aktif yang ditetapkan oleh sebuah blok checkpoint.
+type Snapshot struct {
+	Number     uint64      `json:"number"`     // Nomor blok checkpoint yang menetapkan kumpulan ini
+	Hash       common.Hash `json:"hash"`       // Hash blok checkpoint yang menetapkan kumpulan ini
+	Validators []Validator `json:"validators"` // Validator aktif, diurutkan berdasarkan alamat
+	Total      *big.Int    `json:"total"`      // Total stake dari semua validator aktif
+}
+
+// newSnapshot membuat snapshot dari daftar validator yang diberikan.
+func newSnapshot(number uint64, hash common.Hash, validators []Validator) *Snapshot {
+	snap := &Snapshot{
+		Number:     number,
+		Hash:       hash,
+		Validators: make([]Validator, len(validators)),
+		Total:      new(big.Int),
+	}
+	copy(snap.Validators, validators)
+	sort.Sort(validatorsAscending(snap.Validators))
+
+	for _, v := range snap.Validators {
+		snap.Total.Add(snap.Total, v.Stake)
+	}
+	return snap
+}
+
+// stake mengembalikan stake dari validator yang diberikan, atau nil jika alamat
+// tersebut bukan validator aktif.
+func (s *Snapshot) stake(address common.Address) *big.Int {
+	for _, v := range s.Validators {
+		if v.Address == address {
+			return v.Stake
+		}
+	}
+	return nil
+}
+
+// proposer memilih validator yang berhak mengusulkan blok dengan nomor tertentu.
+// Pemilihannya pseudo-acak tetapi deterministik: seed diturunkan dari hash checkpoint
+// dan nomor blok, lalu dipetakan ke validator dengan peluang sebanding stake-nya.
+func (s *Snapshot) proposer(number uint64) common.Address {
+	if len(s.Validators) == 0 || s.Total.Sign() == 0 {
+		return common.Address{}
+	}
+	var enc [8]byte
+	binary.BigEndian.PutUint64(enc[:], number)
+	seed := crypto.Keccak256(s.Hash[:], enc[:])
+
+	target := new(big.Int).SetBytes(seed)
+	target.Mod(target, s.Total)
+	for _, v := range s.Validators {
+		if target.Cmp(v.Stake) < 0 {
+			return v.Address
+		}
+		target.Sub(target, v.Stake)
+	}
+	// Tidak akan terjadi karena target < total, tetapi tetap aman
+	return s.Validators[len(s.Validators)-1].Address
+}
+
+// inturn mengembalikan apakah validator yang diberikan adalah pengusul terpilih
+// untuk nomor blok tertentu.
+func (s *Snapshot) inturn(number uint64, validator common.Address) bool {
+	return s.proposer(number) == validator
+}
+
+// encodeValidators mengubah daftar validator menjadi byte untuk extra-data checkpoint.
+func encodeValidators(validators []Validator) []byte {
+	out := make([]byte, 0, len(validators)*validatorBytes)
+	for _, v := range validators {
+		out = append(out, v.Address[:]...)
+		out = append(out, common.BigToHash(v.Stake).Bytes()...)
+	}
+	return out
+}
+
+// decodeValidators mengurai daftar validator dari extra-data checkpoint.
+func decodeValidators(data []byte) []Validator {
+	validators := make([]Validator, len(data)/validatorBytes)
+	for i := range validators {
+		entry := data[i*validatorBytes : (i+1)*validatorBytes]
+		validators[i] = Validator{
+			Address: common.BytesToAddress(entry[:common.AddressLength]),
+			Stake:   new(big.Int).SetBytes(entry[common.AddressLength:]),
+		}
+	}
+	return validators
+}
+
+// readValidators membaca kumpulan validator beserta stake-nya dari akun registry
+// di dalam state. Validator dengan stake di bawah minimum atau yang sedang dipenjara
+// diabaikan. Hanya limit
+// entri pertama array yang dibaca, karena panjang array berasal dari storage dan
+// tidak boleh membuat verifikasi header berjalan tanpa batas.
+func readValidators(statedb *state.StateDB, registry common.Address, minStake *big.Int, limit int) []Validator {
+	count := statedb.GetState(registry, validatorsSlot).Big()
+	if count.Cmp(big.NewInt(int64(limit))) > 0 {
+		count.SetInt64(int64(limit))
+	}
+	var (
+		validators []Validator
+		seen       = make(map[common.Address]struct{})
+	)
+	for i := uint64(0); i < count.Uint64(); i++ {
+		address := common.BytesToAddress(statedb.GetState(registry, arraySlot(validatorsSlot, i, 1, 0)).Bytes())
+		if _, ok := seen[address]; ok {
+			continue
+		}
+		seen[address] = struct{}{}
+
+		stake := statedb.GetState(registry, mappingSlot(address, stakesSlot)).Big()
+		if stake.Sign() == 0 || (minStake != nil && stake.Cmp(minStake) < 0) {
+			continue
+		}
+		if statedb.GetState(registry, mappingSlot(address, jailedSlot)) != (common.Hash{}) {
+			continue
+		}
+		validators = append(validators, Validator{Address: address, Stake: stake})
+	}
+	sort.Sort(validatorsAscending(validators))
+	return validators
+}
@@ -0,0 +1,243 @@
+package pos
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	syncCommitteeSize   = 32  // Jumlah default anggota sync committee
+	inmemorySyncBlocks  = 256 // Jumlah blok terbaru yang signature sync committee-nya disimpan
+	mimetypeSyncMessage = "application/x-pos-sync-committee"
+)
+
+// syncDomain adalah awalan pesan yang ditandatangani sync committee, agar signature
+// atas hash blok tidak bisa dipakai ulang di luar konteks sync committee.
+var syncDomain = []byte("pos-sync-committee")
+
+// Berbagai pesan error untuk signature sync committee dan update light client.
+var (
+	errNotSyncMember            = errors.New("signer not in sync committee")
+	errInvalidSyncSignature     = errors.New("invalid sync committee signature")
+	errInsufficientParticipants = errors.New("insufficient sync committee participation")
+	errInvalidSyncCheckpoint    = errors.New("invalid sync committee checkpoint")
+)
+
+// SyncCommittee adalah kumpulan validator yang menandatangani header di antara dua
+// checkpoint agar light client bisa mengikuti rantai tanpa memeriksa setiap segel.
+type SyncCommittee struct {
+	Number  uint64           `json:"number"`  // Nomor checkpoint yang menetapkan komite
+	Hash    common.Hash      `json:"hash"`    // Hash checkpoint yang menetapkan komite
+	Members []common.Address `json:"members"` // Anggota komite, diurutkan berdasarkan alamat
+}
+
+// SyncSignature adalah signature satu anggota sync committee atas hash blok.
+type SyncSignature struct {
+	Hash      common.Hash    `json:"hash"`      // Hash blok yang ditandatangani
+	Signer    common.Address `json:"signer"`    // Anggota komite yang menandatangani
+	Signature hexutil.Bytes  `json:"signature"` // Signature atas syncDomain dan hash blok
+}
+
+// LightClientUpdate adalah data minimum bagi light client untuk menerima header:
+// checkpoint yang menetapkan sync committee, header yang ditandatangani, dan
+// signature anggota komite atas header tersebut. Jika header adalah checkpoint
+// berikutnya, extra-data-nya berisi validator untuk komite selanjutnya.
+type LightClientUpdate struct {
+	Checkpoint *types.Header   `json:"checkpoint"`
+	Header     *types.Header   `json:"header"`
+	Signatures []SyncSignature `json:"signatures"`
+}
+
+// syncCommittee memilih anggota sync committee dari snapshot: validator dengan stake
+// terbesar (alamat terkecil jika sama), paling banyak size orang.
+func (s *Snapshot) syncCommittee(size int) *SyncCommittee {
+	validators := make([]Validator, len(s.Validators))
+	copy(validators, s.Validators)
+	sort.SliceStable(validators, func(i, j int) bool {
+		return validators[i].Stake.Cmp(validators[j].Stake) > 0
+	})
+	if len(validators) > size {
+		validators = validators[:size]
+	}
+	sort.Sort(validatorsAscending(validators))
+
+	committee := &SyncCommittee{Number: s.Number, Hash: s.Hash, Members: make([]common.Address, len(validators))}
+	for i, v := range validators {
+		committee.Members[i] = v.Address
+	}
+	return committee
+}
+
+// member mengembalikan apakah alamat yang diberikan adalah anggota komite.
+func (c *SyncCommittee) member(address common.Address) bool {
+	i := sort.Search(len(c.Members), func(i int) bool {
+		return bytes.Compare(c.Members[i][:], address[:]) >= 0
+	})
+	return i < len(c.Members) && c.Members[i] == address
+}
+
+// syncMessage mengembalikan pesan yang ditandatangani sync committee untuk sebuah blok.
+func syncMessage(hash common.Hash) []byte {
+	return append(append([]byte{}, syncDomain...), hash[:]...)
+}
+
+// verify memeriksa apakah signature berasal dari anggota komite dan ditandatangani
+// atas blok dengan hash yang diberikan.
+func (c *SyncCommittee) verify(hash common.Hash, sig *SyncSignature) error {
+	if sig.Hash != hash || len(sig.Signature) != crypto.SignatureLength {
+		return errInvalidSyncSignature
+	}
+	pubkey, err := crypto.Ecrecover(crypto.Keccak256(syncMessage(hash)), sig.Signature)
+	if err != nil {
+		return errInvalidSyncSignature
+	}
+	var signer common.Address
+	copy(signer[:], crypto.Keccak256(pubkey[1:])[12:])
+	if signer != sig.Signer {
+		return errInvalidSyncSignature
+	}
+	if !c.member(signer) {
+		return errNotSyncMember
+	}
+	return nil
+}
+
+// syncCommittee mengembalikan sync committee yang menandatangani header dengan
+// nomor dan induk tertentu, yaitu komite dari kumpulan validator yang memverifikasi
+// header tersebut.
+func (p *PoS) syncCommittee(chain consensus.ChainHeaderReader, header *types.Header) (*SyncCommittee, error) {
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil, errUnknownBlock
+	}
+	snap, err := p.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return nil, err
+	}
+	return snap.syncCommittee(p.config.SyncCommitteeSize), nil
+}
+
+// SignSyncCommittee menandatangani header sebagai anggota sync committee memakai
+// kredensial validator lokal, lalu menyimpan signature-nya untuk update light client.
+func (p *PoS) SignSyncCommittee(chain consensus.ChainHeaderReader, header *types.Header) (*SyncSignature, error) {
+	committee, err := p.syncCommittee(chain, header)
+	if err != nil {
+		return nil, err
+	}
+	p.lock.RLock()
+	signer, signFn := p.signer, p.signFn
+	p.lock.RUnlock()
+
+	if !committee.member(signer) {
+		return nil, errNotSyncMember
+	}
+	hash := header.Hash()
+	signature, err := signFn(accounts.Account{Address: signer}, mimetypeSyncMessage, syncMessage(hash))
+	if err != nil {
+		return nil, err
+	}
+	sig := &SyncSignature{Hash: hash, Signer: signer, Signature: signature}
+	p.addSyncSignature(sig)
+	return sig, nil
+}
+
+// SubmitSyncSignature memverifikasi signature sync committee dari node lain atas
+// header yang diberikan dan menyimpannya untuk update light client.
+func (p *PoS) SubmitSyncSignature(chain consensus.ChainHeaderReader, header *types.Header, sig *SyncSignature) error {
+	committee, err := p.syncCommittee(chain, header)
+	if err != nil {
+		return err
+	}
+	if err := committee.verify(header.Hash(), sig); err != nil {
+		return err
+	}
+	p.addSyncSignature(sig)
+	return nil
+}
+
+// addSyncSignature menyimpan signature yang sudah diverifikasi, satu per penanda
+// tangan untuk setiap blok.
+func (p *PoS) addSyncSignature(sig *SyncSignature) {
+	p.syncLock.Lock()
+	defer p.syncLock.Unlock()
+
+	sigs, ok := p.syncSignatures.Get(sig.Hash)
+	if !ok {
+		sigs = make(map[common.Address]SyncSignature)
+		p.syncSignatures.Add(sig.Hash, sigs)
+	}
+	sigs.(map[common.Address]SyncSignature)[sig.Signer] = *sig
+}
+
+// LightClientUpdate merakit update light client untuk header yang diberikan dari
+// signature sync committee yang sudah terkumpul, diurutkan berdasarkan penanda tangan.
+func (p *PoS) LightClientUpdate(chain consensus.ChainHeaderReader, header *types.Header) (*LightClientUpdate, error) {
+	committee, err := p.syncCommittee(chain, header)
+	if err != nil {
+		return nil, err
+	}
+	checkpoint := chain.GetHeader(committee.Hash, committee.Number)
+	if checkpoint == nil {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	update := &LightClientUpdate{Checkpoint: checkpoint, Header: header, Signatures: []SyncSignature{}}
+
+	p.syncLock.Lock()
+	if sigs, ok := p.syncSignatures.Get(header.Hash()); ok {
+		for _, sig := range sigs.(map[common.Address]SyncSignature) {
+			update.Signatures = append(update.Signatures, sig)
+		}
+	}
+	p.syncLock.Unlock()
+
+	sort.Slice(update.Signatures, func(i, j int) bool {
+		return bytes.Compare(update.Signatures[i].Signer[:], update.Signatures[j].Signer[:]) < 0
+	})
+	return update, nil
+}
+
+// VerifyLightClientUpdate memeriksa update light client tanpa mengakses rantai:
+// checkpoint harus berada tepat di awal epoch header, dan setidaknya dua pertiga
+// anggota sync committee yang ditetapkan checkpoint harus menandatangani header.
+// Light client yang sudah mempercayai checkpoint bisa menerima header tersebut, dan
+// jika header adalah checkpoint berikutnya, memakai komite barunya untuk epoch
+// selanjutnya.
+func (p *PoS) VerifyLightClientUpdate(update *LightClientUpdate) error {
+	checkpoint, header := update.Checkpoint, update.Header
+	if checkpoint == nil || header == nil || checkpoint.Number == nil || header.Number == nil {
+		return errUnknownBlock
+	}
+	number := checkpoint.Number.Uint64()
+	if number%p.config.Epoch != 0 || header.Number.Uint64() <= number || header.Number.Uint64() > number+p.config.Epoch {
+		return errInvalidSyncCheckpoint
+	}
+	validatorsLen := len(checkpoint.Extra) - extraVanity - extraSeal
+	if validatorsLen <= 0 || validatorsLen%validatorBytes != 0 {
+		return errInvalidCheckpointValidators
+	}
+	snap := newSnapshot(number, checkpoint.Hash(), decodeValidators(checkpoint.Extra[extraVanity:len(checkpoint.Extra)-extraSeal]))
+	committee := snap.syncCommittee(p.config.SyncCommitteeSize)
+
+	var (
+		hash   = header.Hash()
+		signed = make(map[common.Address]struct{})
+	)
+	for i := range update.Signatures {
+		if err := committee.verify(hash, &update.Signatures[i]); err != nil {
+			return err
+		}
+		signed[update.Signatures[i].Signer] = struct{}{}
+	}
+	if 3*len(signed) < 2*len(committee.Members) {
+		return errInsufficientParticipants
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package pos
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Menguji bahwa sync committee berisi validator dengan stake terbesar, signature
+// anggota dikumpulkan menjadi update light client, dan update hanya diterima jika
+// ditandatangani setidaknya dua pertiga anggota komite.
+func TestSyncCommittee(t *testing.T) {
+	tt := newTester(t, &Config{Period: 1, Epoch: 4, SyncCommitteeSize: 3}, 100, 200, 300, 400, 500)
+	headers := tt.extend(tt.engine(), 6, nil)
+	engine := tt.engine()
+	api := &API{chain: tt.chain, pos: engine}
+
+	number := rpc.BlockNumber(5)
+	committee, err := api.GetSyncCommittee(&number)
+	if err != nil {
+		t.Fatalf("failed to retrieve sync committee: %v", err)
+	}
+	snap, _ := engine.snapshot(tt.chain, 5, headers[4].Hash(), nil)
+	for _, addr := range tt.Addrs {
+		if want := snap.stake(addr).Int64() >= 300; committee.member(addr) != want {
+			t.Errorf("validator with stake %v: membership mismatch: have %v, want %v", snap.stake(addr), !want, want)
+		}
+	}
+	// Anggota komite menandatangani blok 6, dan pihak luar ditolak
+	header := headers[5]
+	for i, member := range committee.Members[:2] {
+		signer := tt.engine()
+		signer.Authorize(member, tt.SignFn(member))
+		sig, err := signer.SignSyncCommittee(tt.chain, header)
+		if err != nil {
+			t.Fatalf("member %d: failed to sign: %v", i, err)
+		}
+		if err := api.SubmitSyncSignature(*sig); err != nil {
+			t.Fatalf("member %d: signature rejected: %v", i, err)
+		}
+	}
+	var outsider common.Address
+	for _, addr := range tt.Addrs {
+		if !committee.member(addr) {
+			outsider = addr
+		}
+	}
+	stranger := tt.engine()
+	stranger.Authorize(outsider, tt.SignFn(outsider))
+	if _, err := stranger.SignSyncCommittee(tt.chain, header); err != errNotSyncMember {
+		t.Errorf("outsider sign error mismatch: have %v, want %v", err, errNotSyncMember)
+	}
+	forged := SyncSignature{Hash: header.Hash(), Signer: outsider}
+	forged.Signature, _ = tt.Sign(outsider, crypto.Keccak256Hash(syncMessage(header.Hash())))
+	if err := api.SubmitSyncSignature(forged); err != errNotSyncMember {
+		t.Errorf("outsider submit error mismatch: have %v, want %v", err, errNotSyncMember)
+	}
+	// Update berisi dua dari tiga anggota sehingga cukup untuk light client
+	number = 6
+	update, err := api.GetLightClientUpdate(&number)
+	if err != nil {
+		t.Fatalf("failed to retrieve light client update: %v", err)
+	}
+	if update.Checkpoint.Hash() != headers[3].Hash() || len(update.Signatures) != 2 {
+		t.Fatalf("update mismatch: checkpoint %d, %d signatures", update.Checkpoint.Number, len(update.Signatures))
+	}
+	light := New(tt.config, nil)
+	if err := light.VerifyLightClientUpdate(update); err != nil {
+		t.Fatalf("light client rejected update: %v", err)
+	}
+	tests := []struct {
+		name   string
+		mutate func(update *LightClientUpdate)
+		want   error
+	}{
+		{"one signature", func(u *LightClientUpdate) { u.Signatures = u.Signatures[:1] }, errInsufficientParticipants},
+		{"duplicate signature", func(u *LightClientUpdate) { u.Signatures[1] = u.Signatures[0] }, errInsufficientParticipants},
+		{"tampered header", func(u *LightClientUpdate) {
+			u.Header = types.CopyHeader(u.Header)
+			u.Header.GasUsed++
+		}, errInvalidSyncSignature},
+		{"wrong signer", func(u *LightClientUpdate) { u.Signatures[0].Signer = u.Signatures[1].Signer }, errInvalidSyncSignature},
+		{"outsider", func(u *LightClientUpdate) { u.Signatures[0] = forged }, errNotSyncMember},
+		{"stale checkpoint", func(u *LightClientUpdate) { u.Checkpoint = tt.chain.GetHeaderByNumber(0) }, errInvalidSyncCheckpoint},
+		{"not a checkpoint", func(u *LightClientUpdate) { u.Checkpoint = headers[4] }, errInvalidSyncCheckpoint},
+	}
+	for _, test := range tests {
+		mutated := *update
+		mutated.Signatures = append([]SyncSignature{}, update.Signatures...)
+		test.mutate(&mutated)
+		if err := light.VerifyLightClientUpdate(&mutated); err != test.want {
+			t.Errorf("%s: error mismatch: have %v, want %v", test.name, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,523 @@
+// Paket raft mengimplementasikan mesin konsensus berbasis leader Raft untuk
+// jaringan privat, di mana blok dicetak oleh leader tanpa penambangan.
+package raft
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const (
+	inmemorySignatures = 4096 // Jumlah signature blok terbaru yang disimpan di memori
+
+	mimetypeRaft = "application/x-raft-header" // Tipe data yang ditandatangani oleh leader
+)
+
+// Konstanta protokol raft.
+var (
+	defaultBlockTime = 50 * time.Millisecond // Jeda default di antara blok yang dicetak leader
+
+	extraVanity = 32 // Jumlah byte awalan extra-data yang dicadangkan untuk vanity
+
+	uncleHash   = types.CalcUncleHash(nil) // Selalu Keccak256(RLP([])) karena paman tidak berarti di luar PoW.
+	defaultDiff = big.NewInt(1)            // Kesulitan semua blok raft
+)
+
+// Berbagai pesan error untuk menandai blok tidak valid. Error ini sengaja dibuat
+// privat agar bagian lain dari kode tidak bergantung pada error spesifik mesin ini.
+var (
+	// errUnknownBlock dikembalikan ketika diminta memproses blok yang bukan bagian
+	// dari blockchain lokal.
+	errUnknownBlock = errors.New("unknown block")
+
+	// errInvalidExtraDataFormat dikembalikan jika extra-data bukan format raft.
+	errInvalidExtraDataFormat = errors.New("invalid extra data format")
+
+	// errInvalidNonce dikembalikan jika nonce blok bukan nol.
+	errInvalidNonce = errors.New("invalid nonce")
+
+	// errInvalidTerm dikembalikan jika term blok lebih kecil dari term induknya.
+	errInvalidTerm = errors.New("invalid raft term")
+
+	// errUnknownTerm dikembalikan jika cluster lokal tidak bisa memastikan leader
+	// pada term blok.
+	errUnknownTerm = errors.New("unknown raft term")
+
+	// errInvalidCoinbase dikembalikan jika coinbase blok bukan pencetaknya.
+	errInvalidCoinbase = errors.New("coinbase does not match minter")
+
+	// errUnauthorizedMinter dikembalikan jika blok dicetak oleh pihak yang bukan
+	// anggota cluster.
+	errUnauthorizedMinter = errors.New("unauthorized minter")
+
+	// errNotLeader dikembalikan jika blok dicetak oleh anggota cluster yang bukan
+	// leader pada term blok tersebut.
+	errNotLeader = errors.New("minter is not the raft leader")
+)
+
+// Cluster memberi tahu mesin raft siapa leader pada setiap term. Implementasinya
+// membungkus pustaka Raft (pemilihan leader dan replikasi log) yang dipakai node;
+// mesin konsensus hanya membutuhkan hasil pemilihannya.
+type Cluster interface {
+	// Leader mengembalikan leader dan term yang sedang berjalan menurut node lokal.
+	Leader() (common.Address, uint64)
+
+	// LeaderAt mengembalikan leader untuk term tertentu, jika node lokal
+	// mengetahuinya. Blok dengan term yang tidak diketahui ditolak, jadi
+	// implementasi harus menyimpan riwayat leader untuk semua term di rantai,
+	// misalnya dari log Raft yang sudah di-commit.
+	LeaderAt(term uint64) (common.Address, bool)
+}
+
+// singleNode adalah cluster dengan satu anggota yang selalu menjadi leader pada
+// term 1, cukup untuk jaringan pengembangan satu node.
+type singleNode struct {
+	leader common.Address
+}
+
+// NewSingleNode membuat cluster satu node dengan leader yang diberikan.
+func NewSingleNode(leader common.Address) Cluster {
+	return &singleNode{leader: leader}
+}
+
+// Leader mengimplementasikan Cluster.
+func (s *singleNode) Leader() (common.Address, uint64) {
+	return s.leader, 1
+}
+
+// LeaderAt mengimplementasikan Cluster.
+func (s *singleNode) LeaderAt(term uint64) (common.Address, bool) {
+	return s.leader, term == 1
+}
+
+// Config adalah parameter konsensus dari mesin raft.
+type Config struct {
+	BlockTime time.Duration    `json:"blocktime"` // Jeda minimum di antara blok yang dicetak leader
+	Members   []common.Address `json:"members"`   // Anggota cluster yang boleh menjadi leader
+}
+
+// RaftExtra adalah bagian extra-data header raft setelah vanity.
+type RaftExtra struct {
+	Term uint64 // Term Raft saat blok dicetak
+	Seal []byte // Signature leader atas header
+}
+
+// ExtractRaftExtra mengurai bagian raft dari extra-data header.
+func ExtractRaftExtra(header *types.Header) (*RaftExtra, error) {
+	if len(header.Extra) < extraVanity {
+		return nil, errInvalidExtraDataFormat
+	}
+	extra := new(RaftExtra)
+	if err := rlp.DecodeBytes(header.Extra[extraVanity:], extra); err != nil {
+		return nil, errInvalidExtraDataFormat
+	}
+	return extra, nil
+}
+
+// encodeExtra menulis ulang extra-data header dengan bagian raft yang diberikan.
+func encodeExtra(header *types.Header, extra *RaftExtra) error {
+	payload, err := rlp.EncodeToBytes(extra)
+	if err != nil {
+		return err
+	}
+	vanity := make([]byte, extraVanity)
+	copy(vanity, header.Extra)
+	header.Extra = append(vanity, payload...)
+	return nil
+}
+
+// sigHeader mengembalikan salinan header tanpa signature leader.
+func sigHeader(header *types.Header) *types.Header {
+	cpy := types.CopyHeader(header)
+	if extra, err := ExtractRaftExtra(cpy); err == nil {
+		extra.Seal = []byte{}
+		encodeExtra(cpy, extra)
+	}
+	return cpy
+}
+
+// SealHash mengembalikan hash dari sebuah blok sebelum disegel.
+func SealHash(header *types.Header) common.Hash {
+	return sigHeader(header).Hash()
+}
+
+// RaftRLP mengembalikan byte rlp yang perlu ditandatangani oleh leader, yaitu
+// seluruh header dengan signature dikosongkan.
+func RaftRLP(header *types.Header) []byte {
+	enc, err := rlp.EncodeToBytes(sigHeader(header))
+	if err != nil {
+		panic("can't encode: " + err.Error())
+	}
+	return enc
+}
+
+// ecrecover mengekstrak alamat pencetak dari header yang sudah ditandatangani.
+func ecrecover(header *types.Header, sigcache *lru.ARCCache) (common.Address, error) {
+	// Jika signature sudah ada di cache, kembalikan langsung
+	hash := header.Hash()
+	if address, known := sigcache.Get(hash); known {
+		return address.(common.Address), nil
+	}
+	extra, err := ExtractRaftExtra(header)
+	if err != nil {
+		return common.Address{}, err
+	}
+	pubkey, err := crypto.SigToPub(SealHash(header).Bytes(), extra.Seal)
+	if err != nil {
+		return common.Address{}, err
+	}
+	signer := crypto.PubkeyToAddress(*pubkey)
+
+	sigcache.Add(hash, signer)
+	return signer, nil
+}
+
+// SignerFn melakukan hash dan menandatangani data menggunakan akun pendukung.
+type SignerFn func(signer accounts.Account, mimeType string, message []byte) ([]byte, error)
+
+// Raft adalah mesin konsensus untuk jaringan satu organisasi. Leader Raft
+// mencetak blok tanpa penambangan, dan setiap blok memuat term serta signature
+// leader sehingga node lain cukup memeriksa identitas leader.
+//
+// Blok disegel sebelum masuk ke log Raft, jadi mesin ini tidak mengetahui blok mana
+// yang sudah di-commit mayoritas cluster dan tidak melaporkan finalitas.
+type Raft struct {
+	consensus.NoFinality // Commit log Raft dikelola di luar mesin konsensus
+
+	config     *Config                     // Parameter konfigurasi mesin konsensus
+	cluster    Cluster                     // Sumber informasi leader dari pustaka Raft
+	members    map[common.Address]struct{} // Anggota cluster yang boleh menjadi leader
+	signatures *lru.ARCCache               // Signature dari blok terbaru agar verifikasi lebih cepat
+
+	signer common.Address // Alamat Ethereum dari kunci penandatangan
+	signFn SignerFn       // Fungsi signer untuk mengotorisasi hash
+	lock   sync.RWMutex   // Melindungi field signer
+}
+
+// New membuat mesin konsensus raft yang mengambil informasi leader dari cluster
+// yang diberikan.
+func New(config *Config, cluster Cluster) *Raft {
+	// Isi parameter konsensus yang kosong dengan nilai default
+	conf := *config
+	if conf.BlockTime == 0 {
+		conf.BlockTime = defaultBlockTime
+	}
+	members := make(map[common.Address]struct{})
+	for _, member := range conf.Members {
+		members[member] = struct{}{}
+	}
+	signatures, _ := lru.NewARC(inmemorySignatures)
+
+	return &Raft{
+		config:     &conf,
+		cluster:    cluster,
+		members:    members,
+		signatures: signatures,
+	}
+}
+
+// Authorize menyuntikkan kunci privat ke dalam mesin konsensus untuk mencetak
+// blok baru saat node menjadi leader.
+func (r *Raft) Authorize(signer common.Address, signFn SignerFn) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.signer = signer
+	r.signFn = signFn
+}
+
+// Author mengimplementasikan consensus.Engine, mengembalikan alamat leader yang
+// dipulihkan dari signature di bagian extra-data header.
+func (r *Raft) Author(header *types.Header) (common.Address, error) {
+	return ecrecover(header, r.signatures)
+}
+
+// VerifyHeader memeriksa apakah header sesuai dengan aturan konsensus.
+func (r *Raft) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, seal bool) error {
+	return r.verifyHeader(chain, header, nil)
+}
+
+// VerifyHeaders mirip dengan VerifyHeader, tetapi memverifikasi sekumpulan header
+// secara berurutan di latar belakang. Metode mengembalikan saluran keluar untuk
+// membatalkan operasi dan saluran hasil (urutannya sama dengan inputan slice).
+func (r *Raft) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	abort := make(chan struct{})
+	results := make(chan error, len(headers))
+
+	go func() {
+		for i, header := range headers {
+			err := r.verifyHeader(chain, header, headers[:i])
+
+			select {
+			case <-abort:
+				return
+			case results <- err:
+			}
+		}
+	}()
+	return abort, results
+}
+
+// verifyHeader memeriksa apakah header sesuai dengan aturan konsensus. Pemanggil
+// boleh memberikan sekumpulan induk (urutan naik) agar tidak perlu mencarinya dari
+// database.
+func (r *Raft) verifyHeader(chain consensus.ChainHeaderReader, header *types.Header, parents []*types.Header) error {
+	if header.Number == nil {
+		return errUnknownBlock
+	}
+	// Jangan terima blok dari masa depan
+	if header.Time > uint64(time.Now().Unix()) {
+		return consensus.ErrFutureBlock
+	}
+	if _, err := ExtractRaftExtra(header); err != nil {
+		return err
+	}
+	if header.MixDigest != (common.Hash{}) {
+		return consensus.ErrInvalidMixDigest
+	}
+	if header.Nonce != (types.BlockNonce{}) {
+		return errInvalidNonce
+	}
+	if header.UncleHash != uncleHash {
+		return consensus.ErrInvalidUncleHash
+	}
+	if header.Difficulty == nil || header.Difficulty.Cmp(defaultDiff) != 0 {
+		return consensus.ErrInvalidDifficulty
+	}
+	// Verifikasi bahwa batas gas <= 2^63-1
+	if header.GasLimit > params.MaxGasLimit {
+		return fmt.Errorf("invalid gasLimit: have %v, max %v", header.GasLimit, params.MaxGasLimit)
+	}
+	// Semua pemeriksaan dasar lolos, verifikasi field yang bergantung pada induk
+	return r.verifyCascadingFields(chain, header, parents)
+}
+
+// verifyCascadingFields memverifikasi semua field header yang bergantung pada
+// sekumpulan header sebelumnya.
+func (r *Raft) verifyCascadingFields(chain consensus.ChainHeaderReader, header *types.Header, parents []*types.Header) error {
+	// Blok genesis selalu valid
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil
+	}
+	var parent *types.Header
+	if len(parents) > 0 {
+		parent = parents[len(parents)-1]
+	} else {
+		parent = chain.GetHeader(header.ParentHash, number-1)
+	}
+	if parent == nil || parent.Number.Uint64() != number-1 || parent.Hash() != header.ParentHash {
+		return consensus.ErrUnknownAncestor
+	}
+	// Blok dicetak lebih cepat dari satu detik, jadi stempel waktu boleh sama
+	if header.Time < parent.Time {
+		return consensus.ErrInvalidTimestamp
+	}
+	// Verifikasi bahwa gas yang dipakai <= batas gas
+	if header.GasUsed > header.GasLimit {
+		return fmt.Errorf("invalid gasUsed: have %d, gasLimit %d", header.GasUsed, header.GasLimit)
+	}
+	if !chain.Config().IsLondon(header.Number) {
+		if header.BaseFee != nil {
+			return fmt.Errorf("invalid baseFee before fork: have %d, want <nil>", header.BaseFee)
+		}
+		if err := misc.VerifyGaslimit(parent.GasLimit, header.GasLimit); err != nil {
+			return err
+		}
+	} else if err := misc.VerifyEip1559Header(chain.Config(), parent, header); err != nil {
+		return err
+	}
+	return r.verifySeal(header, parent)
+}
+
+// verifySeal memeriksa apakah blok dicetak oleh leader pada term yang tercatat di
+// header. Term tidak boleh mundur, dan leader term tersebut harus diketahui cluster
+// lokal dan sama dengan pencetak blok. Term yang tidak bisa dipastikan cluster
+// ditolak agar anggota mana pun tidak bisa mencetak blok dengan term karangan.
+func (r *Raft) verifySeal(header, parent *types.Header) error {
+	signer, err := ecrecover(header, r.signatures)
+	if err != nil {
+		return err
+	}
+	if _, ok := r.members[signer]; !ok {
+		return errUnauthorizedMinter
+	}
+	if header.Coinbase != signer {
+		return errInvalidCoinbase
+	}
+	extra, _ := ExtractRaftExtra(header)
+	if parent.Number.Sign() > 0 {
+		parentExtra, err := ExtractRaftExtra(parent)
+		if err != nil {
+			return err
+		}
+		if extra.Term < parentExtra.Term {
+			return errInvalidTerm
+		}
+	}
+	leader, ok := r.cluster.LeaderAt(extra.Term)
+	if !ok {
+		return errUnknownTerm
+	}
+	if leader != signer {
+		return errNotLeader
+	}
+	return nil
+}
+
+// VerifyUncles mengimplementasikan consensus.Engine, selalu mengembalikan error untuk
+// paman karena mekanisme konsensus ini tidak mengizinkan paman.
+func (r *Raft) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
+	if len(block.Uncles()) > 0 {
+		return consensus.ErrTooManyUncles
+	}
+	return nil
+}
+
+// Prepare mengimplementasikan consensus.Engine, menyiapkan semua field konsensus
+// dari header sebelum transaksi dijalankan di atasnya.
+func (r *Raft) Prepare(chain consensus.ChainHeaderReader, header *types.Header) error {
+	number := header.Number.Uint64()
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	r.lock.RLock()
+	header.Coinbase = r.signer
+	r.lock.RUnlock()
+
+	_, term := r.cluster.Leader()
+	header.Nonce = types.BlockNonce{}
+	header.MixDigest = common.Hash{}
+	header.Difficulty = new(big.Int).Set(defaultDiff)
+	if err := encodeExtra(header, &RaftExtra{Term: term, Seal: []byte{}}); err != nil {
+		return err
+	}
+	header.Time = uint64(time.Now().Unix())
+	if header.Time < parent.Time {
+		header.Time = parent.Time
+	}
+	return nil
+}
+
+// Finalize mengimplementasikan consensus.Engine. Raft tidak memberikan block
+// reward, jadi hanya root state akhir yang ditetapkan.
+func (r *Raft) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header) {
+	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
+	header.UncleHash = types.CalcUncleHash(nil)
+}
+
+// FinalizeAndAssemble mengimplementasikan consensus.Engine, menjalankan Finalize
+// lalu merakit blok terakhir.
+func (r *Raft) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
+	r.Finalize(chain, header, state, txs, uncles)
+	return types.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil)), nil
+}
+
+// Seal mengimplementasikan consensus.Engine. Hanya leader pada term yang tercatat
+// di header yang boleh mencetak blok.
+func (r *Raft) Seal(chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
+	header := block.Header()
+
+	// Penyegelan blok genesis tidak didukung
+	if header.Number.Uint64() == 0 {
+		return errUnknownBlock
+	}
+	r.lock.RLock()
+	signer, signFn := r.signer, r.signFn
+	r.lock.RUnlock()
+
+	extra, err := ExtractRaftExtra(header)
+	if err != nil {
+		return err
+	}
+	if leader, term := r.cluster.Leader(); leader != signer || term != extra.Term {
+		return errNotLeader
+	}
+	sighash, err := signFn(accounts.Account{Address: signer}, mimetypeRaft, RaftRLP(header))
+	if err != nil {
+		return err
+	}
+	extra.Seal = sighash
+	if err := encodeExtra(header, extra); err != nil {
+		return err
+	}
+	// Beri jeda singkat agar transaksi sempat terkumpul di antara blok
+	go func() {
+		select {
+		case <-stop:
+			return
+		case <-time.After(r.config.BlockTime):
+		}
+		// Leader bisa saja berganti selama menunggu
+		if leader, term := r.cluster.Leader(); leader != signer || term != extra.Term {
+			log.Debug("Lost raft leadership while minting", "number", header.Number, "term", extra.Term)
+			return
+		}
+		select {
+		case results <- block.WithSeal(header):
+		default:
+			log.Warn("Sealing result is not read by miner", "sealhash", SealHash(header))
+		}
+	}()
+	return nil
+}
+
+// CalcDifficulty adalah algoritma penyesuaian kesulitan, selalu mengembalikan 1.
+func (r *Raft) CalcDifficulty(chain consensus.ChainHeaderReader, time uint64, parent *types.Header) *big.Int {
+	return new(big.Int).Set(defaultDiff)
+}
+
+// SealHash mengembalikan hash dari sebuah blok sebelum disegel.
+func (r *Raft) SealHash(header *types.Header) common.Hash {
+	return SealHash(header)
+}
+
+// Close mengimplementasikan consensus.Engine. Tidak melakukan apa-apa karena
+// siklus hidup cluster Raft dikelola oleh pemanggil.
+func (r *Raft) Close() error {
+	return nil
+}
+
+// APIs mengimplementasikan consensus.Engine, mengembalikan API RPC untuk
+// memeriksa leader cluster.
+func (r *Raft) APIs(chain consensus.ChainHeaderReader) []rpc.API {
+	return []rpc.API{{
+		Namespace: "raft",
+		Service:   &API{raft: r},
+	}}
+}
+
+// API adalah API RPC untuk memeriksa status leader dari mesin raft.
+type API struct {
+	raft *Raft
+}
+
+// Leader mengembalikan leader Raft yang sedang berjalan.
+func (api *API) Leader() common.Address {
+	leader, _ := api.raft.cluster.Leader()
+	return leader
+}
+
+// Term mengembalikan term Raft yang sedang berjalan.
+func (api *API) Term() uint64 {
+	_, term := api.raft.cluster.Leader()
+	return term
+}
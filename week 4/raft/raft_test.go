@@ -0,0 +1,236 @@
+package raft
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/consensustest"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// testCluster adalah Cluster yang riwayat leader-nya diatur langsung oleh tes.
+type testCluster struct {
+	lock    sync.Mutex
+	term    uint64
+	leaders map[uint64]common.Address
+}
+
+// elect memulai term baru dengan leader yang diberikan.
+func (c *testCluster) elect(leader common.Address) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.term++
+	c.leaders[c.term] = leader
+}
+
+// Leader mengimplementasikan Cluster.
+func (c *testCluster) Leader() (common.Address, uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.leaders[c.term], c.term
+}
+
+// LeaderAt mengimplementasikan Cluster.
+func (c *testCluster) LeaderAt(term uint64) (common.Address, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	leader, ok := c.leaders[term]
+	return leader, ok
+}
+
+// tester menyiapkan rantai di memori, cluster dan kunci anggota untuk pengujian
+// mesin raft.
+type tester struct {
+	*consensustest.Accounts // Anggota cluster
+
+	t       *testing.T
+	config  *Config
+	cluster *testCluster
+	chain   *consensustest.HeaderChain
+}
+
+// newTester membuat cluster dengan n anggota dan rantai yang hanya berisi genesis.
+// Belum ada term yang berjalan sampai tes memanggil elect.
+func newTester(t *testing.T, n int) *tester {
+	t.Helper()
+
+	tt := &tester{
+		Accounts: consensustest.NewAccounts(n),
+		t:        t,
+		cluster:  &testCluster{leaders: make(map[uint64]common.Address)},
+	}
+	tt.config = &Config{BlockTime: 10 * time.Millisecond, Members: tt.Addrs}
+
+	genesis := consensustest.Genesis()
+	genesis.Difficulty = new(big.Int).Set(defaultDiff)
+	if err := encodeExtra(genesis, &RaftExtra{Seal: []byte{}}); err != nil {
+		t.Fatalf("failed to encode genesis extra: %v", err)
+	}
+	tt.chain = consensustest.NewHeaderChain(params.AllCliqueProtocolChanges, genesis)
+	return tt
+}
+
+// engine membuat mesin raft baru di atas cluster pengujian.
+func (tt *tester) engine() *Raft {
+	return New(tt.config, tt.cluster)
+}
+
+// sign menuliskan term dan signature anggota yang diberikan ke extra-data header.
+func (tt *tester) sign(header *types.Header, term uint64, signer common.Address) {
+	if err := encodeExtra(header, &RaftExtra{Term: term, Seal: []byte{}}); err != nil {
+		tt.t.Fatalf("failed to encode extra: %v", err)
+	}
+	sig, err := tt.Sign(signer, SealHash(header))
+	if err != nil {
+		tt.t.Fatalf("failed to sign header: %v", err)
+	}
+	if err := encodeExtra(header, &RaftExtra{Term: term, Seal: sig}); err != nil {
+		tt.t.Fatalf("failed to encode extra: %v", err)
+	}
+}
+
+// extend menambahkan n blok ke rantai, masing-masing dicetak oleh leader term yang
+// sedang berjalan.
+func (tt *tester) extend(engine *Raft, n int) []*types.Header {
+	tt.t.Helper()
+
+	pick := func(header, parent *types.Header) common.Address {
+		leader, _ := tt.cluster.Leader()
+		engine.Authorize(leader, tt.SignFn(leader))
+		return leader
+	}
+	seal := func(header, parent *types.Header, leader common.Address) {
+		_, term := tt.cluster.Leader()
+		header.Time = parent.Time + 1
+		tt.sign(header, term, leader)
+	}
+	return consensustest.Extend(tt.t, tt.chain, engine, n, pick, seal)
+}
+
+// Menguji bahwa rantai yang melewati pergantian leader lolos verifikasi satu per
+// satu dan dalam batch.
+func TestVerifyChain(t *testing.T) {
+	tt := newTester(t, 3)
+
+	var headers []*types.Header
+	for _, leader := range []common.Address{tt.Addrs[0], tt.Addrs[1], tt.Addrs[1], tt.Addrs[2]} {
+		tt.cluster.elect(leader)
+		headers = append(headers, tt.extend(tt.engine(), 3)...)
+	}
+	consensustest.VerifyChain(t, tt.chain, headers, tt.engine(), tt.engine())
+}
+
+// Menguji bahwa header yang melanggar aturan raft ditolak dengan error yang sesuai,
+// terutama blok dari term yang mundur atau dari anggota yang bukan leader term-nya.
+func TestVerifyHeaderReject(t *testing.T) {
+	tt := newTester(t, 2)
+	first, second := tt.Addrs[0], tt.Addrs[1]
+
+	tt.cluster.elect(first)
+	tt.extend(tt.engine(), 2)
+	tt.cluster.elect(second)
+	headers := tt.extend(tt.engine(), 2)
+
+	block := headers[1] // Blok kedua di term 2 oleh leader kedua
+	strangers := consensustest.NewAccounts(1)
+	stranger := strangers.Addrs[0]
+
+	// resign menandatangani ulang header oleh leader kedua setelah diubah
+	resign := func(h *types.Header) { tt.sign(h, 2, second) }
+
+	tests := append(consensustest.HeaderRejects(headers[0], resign), []consensustest.Reject{
+		{Name: "extra format", Mutate: func(h *types.Header) { h.Extra = h.Extra[:extraVanity-1] }, Want: errInvalidExtraDataFormat},
+		{Name: "nonce", Mutate: func(h *types.Header) { h.Nonce = types.BlockNonce{1}; resign(h) }, Want: errInvalidNonce},
+		{Name: "coinbase", Mutate: func(h *types.Header) { h.Coinbase = first; resign(h) }, Want: errInvalidCoinbase},
+		{Name: "unauthorized", Mutate: func(h *types.Header) {
+			h.Coinbase = stranger
+			tt.Keys[stranger] = strangers.Keys[stranger]
+			defer delete(tt.Keys, stranger)
+			tt.sign(h, 2, stranger)
+		}, Want: errUnauthorizedMinter},
+		{Name: "term regression", Mutate: func(h *types.Header) {
+			h.Coinbase = first
+			tt.sign(h, 1, first)
+		}, Want: errInvalidTerm},
+		{Name: "not leader", Mutate: func(h *types.Header) {
+			h.Coinbase = first
+			tt.sign(h, 2, first)
+		}, Want: errNotLeader},
+		{Name: "unknown term", Mutate: func(h *types.Header) { tt.sign(h, 9, second) }, Want: errUnknownTerm},
+	}...)
+	consensustest.VerifyRejects(t, tt.chain, block, func() consensus.Engine { return tt.engine() }, tests)
+}
+
+// Menguji bahwa Seal hanya mencetak blok oleh leader term yang sedang berjalan,
+// dan membuang blok jika kepemimpinan hilang selama jeda blok.
+func TestSeal(t *testing.T) {
+	tt := newTester(t, 2)
+	leader, follower := tt.Addrs[0], tt.Addrs[1]
+	tt.cluster.elect(leader)
+
+	// prepare menyiapkan blok berikutnya dengan mesin yang diotorisasi signer
+	prepare := func(signer common.Address) (*Raft, *types.Block) {
+		engine := tt.engine()
+		engine.Authorize(signer, tt.SignFn(signer))
+
+		parent := tt.chain.CurrentHeader()
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number, common.Big1),
+			GasLimit:   parent.GasLimit,
+			UncleHash:  uncleHash,
+		}
+		if err := engine.Prepare(tt.chain, header); err != nil {
+			t.Fatalf("failed to prepare header: %v", err)
+		}
+		header.BaseFee = misc.CalcBaseFee(tt.chain.Config(), parent)
+		return engine, types.NewBlockWithHeader(header)
+	}
+	engine, block := prepare(leader)
+	sealed := consensustest.SealBlock(t, tt.chain, engine, block)
+	if author, err := engine.Author(sealed.Header()); err != nil || author != leader {
+		t.Errorf("author mismatch: have %x (%v), want %x", author, err, leader)
+	}
+	// Anggota yang bukan leader tidak boleh mencetak blok
+	results := make(chan *types.Block, 1)
+	engine, block = prepare(follower)
+	if err := engine.Seal(tt.chain, block, results, make(chan struct{})); err != errNotLeader {
+		t.Errorf("follower seal error mismatch: have %v, want %v", err, errNotLeader)
+	}
+	// Blok dibuang jika leader berganti sebelum jeda blok selesai
+	tt.config.BlockTime = 200 * time.Millisecond
+	engine, block = prepare(leader)
+	if err := engine.Seal(tt.chain, block, results, make(chan struct{})); err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	tt.cluster.elect(follower)
+	select {
+	case <-results:
+		t.Errorf("block sealed after losing leadership")
+	case <-time.After(2 * tt.config.BlockTime):
+	}
+}
+
+// Menguji bahwa mesin tidak melaporkan finalitas, karena blok disegel sebelum
+// di-commit ke log Raft.
+func TestNoFinality(t *testing.T) {
+	tt := newTester(t, 1)
+	tt.cluster.elect(tt.Addrs[0])
+	tt.extend(tt.engine(), 2)
+
+	if header := tt.engine().FinalizedHeader(tt.chain); header != nil {
+		t.Errorf("finalized header reported: %d", header.Number)
+	}
+	if header := tt.engine().SafeHeader(tt.chain); header != nil {
+		t.Errorf("safe header reported: %d", header.Number)
+	}
+}
@@ -0,0 +1,68 @@
+package consensus
+
+import "runtime"
+
+// VerifyParallel adalah penjadwal verifikasi bersama untuk implementasi
+// Engine.VerifyHeaders. Fungsi ini membagi n header ke sejumlah pekerja yang
+// menjalankan verify untuk indeks masing-masing, lalu mengirim hasilnya ke saluran
+// error sesuai urutan indeks, berapa pun urutan selesainya pekerja.
+//
+// Jumlah pekerja dibatasi oleh workers; nilai nol atau negatif berarti memakai
+// GOMAXPROCS. Pekerja tidak pernah lebih banyak dari jumlah header. Menutup saluran
+// abort menghentikan pengiriman indeks baru ke pekerja. Fungsi verify harus aman
+// dipanggil secara bersamaan dan hanya boleh bergantung pada header lain di batch
+// yang sudah tersedia sebelum verifikasi dimulai.
+func VerifyParallel(n int, workers int, verify func(index int) error) (chan<- struct{}, <-chan error) {
+	if n == 0 {
+		return make(chan struct{}), make(chan error)
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if n < workers {
+		workers = n
+	}
+	// Buat saluran tugas dan jalankan para verifikator
+	var (
+		inputs = make(chan int)
+		done   = make(chan int, workers)
+		errors = make([]error, n)
+		abort  = make(chan struct{})
+	)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for index := range inputs {
+				errors[index] = verify(index)
+				done <- index
+			}
+		}()
+	}
+	errorsOut := make(chan error, n)
+	go func() {
+		defer close(inputs)
+		var (
+			in, out = 0, 0
+			checked = make([]bool, n)
+			inputs  = inputs
+		)
+		for {
+			select {
+			case inputs <- in:
+				if in++; in == n {
+					// Semua header sudah dikirim, berhenti mengirim ke pekerja
+					inputs = nil
+				}
+			case index := <-done:
+				for checked[index] = true; checked[out]; out++ {
+					errorsOut <- errors[out]
+					if out == n-1 {
+						return
+					}
+				}
+			case <-abort:
+				return
+			}
+		}
+	}()
+	return abort, errorsOut
+}
@@ -0,0 +1,220 @@
+// Paket verifycache menyediakan pembungkus mesin konsensus yang menyimpan hasil
+// verifikasi header berdasarkan hash, agar header yang dilihat berulang kali lewat
+// pengumuman blok, sinkronisasi dan paman tidak diverifikasi ulang.
+package verifycache
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const defaultCacheSize = 4096 // Jumlah default hasil verifikasi yang disimpan di memori
+
+// cacheKey adalah kunci cache verifikasi. Verifikasi dengan segel lebih ketat
+// daripada tanpa segel, jadi keduanya disimpan terpisah.
+type cacheKey struct {
+	hash common.Hash
+	seal bool
+}
+
+// Engine adalah mesin konsensus yang membungkus mesin lain dan menyimpan header
+// yang sudah lolos verifikasi. Hanya hasil yang berhasil disimpan, karena kegagalan
+// seperti induk yang belum dikenal atau blok dari masa depan bisa berubah seiring
+// waktu. Semua metode lain diteruskan apa adanya ke mesin asli.
+//
+// Setiap hasil dicatat bersama epoch cache saat verifikasinya dimulai. Purge dan
+// perubahan konfigurasi rantai menaikkan epoch, sehingga hasil dari verifikasi
+// yang masih berjalan saat itu tidak pernah dipakai.
+type Engine struct {
+	consensus.Engine
+
+	verified *lru.ARCCache // Header yang sudah lolos verifikasi, dipetakan ke epoch-nya
+	config   common.Hash   // Sidik jari konfigurasi rantai pada epoch saat ini
+	epoch    uint64        // Epoch cache, naik setiap kali cache dihapus
+	lock     sync.Mutex    // Menjaga config dan epoch agar reset cache tidak saling balapan
+}
+
+// Cache adalah mesin konsensus dengan cache verifikasi yang dikembalikan New.
+type Cache interface {
+	consensus.Engine
+
+	// InnerEngine mengembalikan mesin konsensus yang dibungkus.
+	InnerEngine() consensus.Engine
+
+	// Purge menghapus semua hasil verifikasi.
+	Purge()
+}
+
+// New membungkus mesin konsensus dengan cache verifikasi berukuran size. Ukuran
+// nol atau negatif berarti memakai ukuran default. Hasilnya hanya memenuhi
+// consensus.PoW jika mesin asli juga PoW, sehingga pemanggil yang memeriksa
+// consensus.PoW untuk memutuskan penambangan tetap mendapat jawaban yang benar.
+func New(engine consensus.Engine, size int) Cache {
+	if size <= 0 {
+		size = defaultCacheSize
+	}
+	verified, _ := lru.NewARC(size)
+	cache := &Engine{Engine: engine, verified: verified}
+
+	if pow, ok := engine.(consensus.PoW); ok {
+		return &powEngine{Engine: cache, pow: pow}
+	}
+	return cache
+}
+
+// InnerEngine mengembalikan mesin konsensus yang dibungkus.
+func (e *Engine) InnerEngine() consensus.Engine {
+	return e.Engine
+}
+
+// Purge menghapus semua hasil verifikasi. Fungsi ini harus dipanggil setiap kali
+// mesin asli dikonfigurasi ulang sehingga aturan verifikasinya bisa berubah.
+func (e *Engine) Purge() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.purge()
+}
+
+// purge menaikkan epoch dan menghapus semua hasil. Pemanggil harus memegang lock.
+func (e *Engine) purge() {
+	e.epoch++
+	e.verified.Purge()
+}
+
+// checkConfig menghapus cache jika konfigurasi rantai telah berganti sejak hasil
+// terakhir disimpan, karena jadwal fork yang berbeda bisa mengubah hasil verifikasi,
+// lalu mengembalikan epoch yang berlaku. Konfigurasi dibandingkan lewat sidik jari
+// isinya sehingga perubahan di tempat pada objek yang sama juga terdeteksi.
+func (e *Engine) checkConfig(chain consensus.ChainHeaderReader) uint64 {
+	config := fingerprint(chain.Config())
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.config != config {
+		e.purge()
+		e.config = config
+	}
+	return e.epoch
+}
+
+// fingerprint mengembalikan hash dari encoding JSON konfigurasi rantai.
+func fingerprint(config *params.ChainConfig) common.Hash {
+	blob, _ := json.Marshal(config)
+	return crypto.Keccak256Hash(blob)
+}
+
+// known mengembalikan apakah header sudah pernah lolos verifikasi pada epoch yang
+// diberikan dengan tingkat keketatan yang sama atau lebih tinggi.
+func (e *Engine) known(hash common.Hash, seal bool, epoch uint64) bool {
+	if e.verifiedIn(cacheKey{hash, true}, epoch) {
+		return true
+	}
+	return !seal && e.verifiedIn(cacheKey{hash, false}, epoch)
+}
+
+// verifiedIn mengembalikan apakah kunci tersimpan dengan epoch yang diberikan.
+func (e *Engine) verifiedIn(key cacheKey, epoch uint64) bool {
+	stored, ok := e.verified.Get(key)
+	return ok && stored.(uint64) == epoch
+}
+
+// remember menyimpan hasil verifikasi yang dimulai pada epoch tertentu, kecuali
+// cache sudah dihapus sejak verifikasi tersebut dimulai.
+func (e *Engine) remember(hash common.Hash, seal bool, epoch uint64) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.epoch == epoch {
+		e.verified.Add(cacheKey{hash, seal}, epoch)
+	}
+}
+
+// VerifyHeader mengimplementasikan consensus.Engine, melewati verifikasi jika header
+// sudah pernah lolos sebelumnya.
+func (e *Engine) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, seal bool) error {
+	epoch := e.checkConfig(chain)
+
+	hash := header.Hash()
+	if e.known(hash, seal, epoch) {
+		return nil
+	}
+	if err := e.Engine.VerifyHeader(chain, header, seal); err != nil {
+		return err
+	}
+	e.remember(hash, seal, epoch)
+	return nil
+}
+
+// VerifyHeaders mengimplementasikan consensus.Engine. Jika semua header sudah pernah
+// lolos verifikasi, hasilnya langsung dikembalikan. Jika tidak, seluruh batch tetap
+// diteruskan ke mesin asli karena mesin bisa membutuhkan header sebelumnya di batch
+// sebagai induk, lalu header yang lolos disimpan ke cache.
+func (e *Engine) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	epoch := e.checkConfig(chain)
+
+	hashes := make([]common.Hash, len(headers))
+	cached := true
+	for i, header := range headers {
+		hashes[i] = header.Hash()
+		if !e.known(hashes[i], seals[i], epoch) {
+			cached = false
+		}
+	}
+	if cached {
+		results := make(chan error, len(headers))
+		for range headers {
+			results <- nil
+		}
+		return make(chan struct{}), results
+	}
+	innerAbort, inner := e.Engine.VerifyHeaders(chain, headers, seals)
+
+	abort := make(chan struct{})
+	results := make(chan error, len(headers))
+	go func() {
+		for i := range headers {
+			select {
+			case err := <-inner:
+				if err == nil {
+					e.remember(hashes[i], seals[i], epoch)
+				}
+				results <- err
+			case <-abort:
+				close(innerAbort)
+				return
+			}
+		}
+	}()
+	return abort, results
+}
+
+// powEngine adalah Engine untuk mesin asli berbasis PoW, yang juga meneruskan
+// metode consensus.PoW ke mesin asli.
+type powEngine struct {
+	*Engine
+	pow consensus.PoW
+}
+
+// SetThreads mengimplementasikan consensus.PoW, meneruskan ke mesin asli.
+func (e *powEngine) SetThreads(threads int) {
+	e.pow.SetThreads(threads)
+}
+
+// Hashrate mengimplementasikan consensus.PoW, meneruskan ke mesin asli.
+func (e *powEngine) Hashrate() float64 {
+	return e.pow.Hashrate()
+}
+
+// SubmitHashrate mengimplementasikan consensus.PoW, meneruskan ke mesin asli.
+func (e *powEngine) SubmitHashrate(id common.Hash, rate uint64) bool {
+	return e.pow.SubmitHashrate(id, rate)
+}
@@ -0,0 +1,136 @@
+package verifycache
+
+import (
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/consensus/consensustest"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// countingEngine adalah mesin palsu yang menghitung verifikasi header yang benar
+// benar diteruskan kepadanya, dan bisa menahan verifikasi sampai gate dibuka.
+type countingEngine struct {
+	*consensustest.FakeEngine
+	calls   int32
+	started chan struct{} // Diberi sinyal saat verifikasi yang ditahan dimulai
+	gate    chan struct{} // Ditutup untuk melepas verifikasi yang ditahan
+}
+
+func (c *countingEngine) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, seal bool) error {
+	atomic.AddInt32(&c.calls, 1)
+	if c.gate != nil {
+		select {
+		case c.started <- struct{}{}:
+		default:
+		}
+		<-c.gate
+	}
+	return nil
+}
+
+// newTestChain membuat rantai di memori dengan salinan konfigurasi tes sendiri
+// sehingga tes bisa mengubahnya di tempat.
+func newTestChain() (*consensustest.HeaderChain, *params.ChainConfig) {
+	config := *params.TestChainConfig
+	genesis := &types.Header{Number: big.NewInt(0), Difficulty: big.NewInt(1)}
+	return consensustest.NewHeaderChain(&config, genesis), &config
+}
+
+// Menguji bahwa hasil disimpan per tingkat keketatan: verifikasi dengan segel
+// mencakup verifikasi tanpa segel, tapi tidak sebaliknya.
+func TestCache(t *testing.T) {
+	inner := &countingEngine{FakeEngine: consensustest.NewFakeEngine()}
+	engine := New(inner, 0)
+	chain, _ := newTestChain()
+	header := &types.Header{Number: big.NewInt(1)}
+
+	steps := []struct {
+		seal  bool
+		calls int32
+	}{
+		{false, 1}, {false, 1}, {true, 2}, {true, 2}, {false, 2},
+	}
+	for i, step := range steps {
+		if err := engine.VerifyHeader(chain, header, step.seal); err != nil {
+			t.Fatalf("step %d: verification failed: %v", i, err)
+		}
+		if have := atomic.LoadInt32(&inner.calls); have != step.calls {
+			t.Errorf("step %d: inner calls mismatch: have %d, want %d", i, have, step.calls)
+		}
+	}
+}
+
+// Menguji bahwa perubahan konfigurasi rantai di tempat, pada objek yang sama,
+// menghapus hasil lama.
+func TestConfigMutation(t *testing.T) {
+	inner := &countingEngine{FakeEngine: consensustest.NewFakeEngine()}
+	engine := New(inner, 0)
+	chain, config := newTestChain()
+	header := &types.Header{Number: big.NewInt(1)}
+
+	engine.VerifyHeader(chain, header, true)
+	config.LondonBlock = big.NewInt(100)
+	engine.VerifyHeader(chain, header, true)
+
+	if have := atomic.LoadInt32(&inner.calls); have != 2 {
+		t.Errorf("inner calls mismatch: have %d, want %d", have, 2)
+	}
+}
+
+// Menguji bahwa hasil verifikasi yang selesai setelah Purge tidak disimpan, karena
+// verifikasi tersebut dimulai dengan aturan lama.
+func TestPurgeInFlight(t *testing.T) {
+	inner := &countingEngine{FakeEngine: consensustest.NewFakeEngine(), started: make(chan struct{}, 1), gate: make(chan struct{})}
+	engine := New(inner, 0)
+	chain, _ := newTestChain()
+	header := &types.Header{Number: big.NewInt(1)}
+
+	done := make(chan error)
+	go func() { done <- engine.VerifyHeader(chain, header, true) }()
+	<-inner.started
+	engine.Purge()
+	close(inner.gate)
+	if err := <-done; err != nil {
+		t.Fatalf("verification failed: %v", err)
+	}
+	engine.VerifyHeader(chain, header, true)
+
+	if have := atomic.LoadInt32(&inner.calls); have != 2 {
+		t.Errorf("inner calls mismatch: have %d, want %d", have, 2)
+	}
+}
+
+// Menguji bahwa metode PoW hanya tersedia dan diteruskan jika mesin asli adalah
+// PoW, sehingga pembungkus clique tidak terlihat seperti mesin PoW.
+func TestPoWForwarding(t *testing.T) {
+	inner := consensustest.NewFakePoW()
+	engine, ok := New(inner, 0).(consensus.PoW)
+	if !ok {
+		t.Fatalf("wrapped PoW engine does not implement consensus.PoW")
+	}
+	engine.SetThreads(3)
+	if !engine.SubmitHashrate(common.Hash{1}, 100) {
+		t.Fatalf("hashrate submission rejected")
+	}
+	if have := inner.Threads(); have != 3 {
+		t.Errorf("threads mismatch: have %d, want %d", have, 3)
+	}
+	if have := engine.Hashrate(); have != 100 {
+		t.Errorf("hashrate mismatch: have %v, want %v", have, 100)
+	}
+	for name, inner := range map[string]consensus.Engine{
+		"fake":   consensustest.NewFakeEngine(),
+		"clique": clique.New(params.AllCliqueProtocolChanges.Clique, rawdb.NewMemoryDatabase()),
+	} {
+		if _, ok := New(inner, 0).(consensus.PoW); ok {
+			t.Errorf("%s: wrapped engine implements consensus.PoW", name)
+		}
+	}
+}